@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cpgillem/garden-planner/geometry"
+	"github.com/cpgillem/garden-planner/models"
+)
+
+// A feature overlapping another, sticking out of the plan, or closer to
+// another than the minimum path width. B is NoFeature for features outside
+// the plan.
+type Collision struct {
+	Kind     geometry.CollisionKind
+	A, B     models.FeatureID
+	Distance float32
+}
+
+// Describes the collision in words.
+func (c Collision) Describe(plan *models.Plan) string {
+	name := func(id models.FeatureID) string {
+		if f := plan.Features[id]; f != nil {
+			return f.Name
+		}
+		return string(id)
+	}
+
+	switch c.Kind {
+	case geometry.OVERLAP:
+		return fmt.Sprintf("%s overlaps %s", name(c.A), name(c.B))
+	case geometry.OUT_OF_BOUNDS:
+		return fmt.Sprintf("%s is outside the plan", name(c.A))
+	case geometry.CLEARANCE:
+		return fmt.Sprintf("%s is too close to %s", name(c.A), name(c.B))
+	}
+	return ""
+}
+
+// Features that overlap, stick out of the plan, or are closer than
+// clearance, in base units. Only features side by side under the same
+// parent are compared, since nested features are inside their parents.
+func FindCollisions(plan *PlanController, clearance float32) []Collision {
+	// Features in a stable order.
+	ids := []models.FeatureID{}
+	for id, f := range plan.Plan.Features {
+		if f != nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	shapes := []geometry.Shape{}
+	indices := map[models.FeatureID]int{}
+	for i, id := range ids {
+		shapes = append(shapes, plan.Plan.Shape(id))
+		indices[id] = i
+	}
+
+	// Neighbours come from the plan's index rather than a tree of their own.
+	near := func(area geometry.Box, distance float32) []int {
+		found := []int{}
+		for _, id := range plan.FeaturesNear(area, distance) {
+			if i, ok := indices[id]; ok {
+				found = append(found, i)
+			}
+		}
+		return found
+	}
+
+	collisions := []Collision{}
+	found := geometry.FindCollisions(shapes, plan.Plan.Box, clearance, near, func(a, b int) bool {
+		return plan.siblings(ids[a], ids[b])
+	})
+	for _, c := range found {
+		collision := Collision{Kind: c.Kind, A: ids[c.A], B: models.NoFeature, Distance: c.Distance}
+		if c.B >= 0 {
+			collision.B = ids[c.B]
+		}
+		collisions = append(collisions, collision)
+	}
+	return collisions
+}
+
+// Collisions involving a feature, with the feature as A.
+func CollisionsOf(id models.FeatureID, collisions []Collision) []Collision {
+	of := []Collision{}
+	for _, c := range collisions {
+		switch id {
+		case c.A:
+			of = append(of, c)
+		case c.B:
+			c.A, c.B = c.B, c.A
+			of = append(of, c)
+		}
+	}
+	return of
+}
+
+// Whether a feature overlaps a sibling or sticks out of the plan. Plans
+// with no size have no edges to stick out of.
+func (c *PlanController) collides(id models.FeatureID) bool {
+	shape := c.Plan.Shape(id)
+	if !c.Plan.Box.IsEmpty() && !geometry.Inside(shape, c.Plan.Box) {
+		return true
+	}
+	for _, other := range c.FeaturesIn(shape.Bounds()) {
+		if other != id && c.siblings(id, other) && geometry.Overlaps(shape, c.Plan.Shape(other)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether two features have the same parent, or are both root features.
+func (c *PlanController) siblings(a models.FeatureID, b models.FeatureID) bool {
+	if c.Plan.IsRoot(a) || c.Plan.IsRoot(b) {
+		return c.Plan.IsRoot(a) && c.Plan.IsRoot(b)
+	}
+	return c.Plan.Features[a].Parent == c.Plan.Features[b].Parent
+}
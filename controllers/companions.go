@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"sort"
+
+	"github.com/cpgillem/garden-planner/geometry"
+	"github.com/cpgillem/garden-planner/models"
+)
+
+// Two features near enough to each other for their plants to interact.
+type Companion struct {
+	A, B            models.FeatureID
+	PlantA, PlantB  int
+	InteractionType uint16
+	Distance        float32
+}
+
+// Pairs of features whose plants interact and whose edges are at most
+// maxDistance apart, in base units. Neutral pairs are left out.
+func FindCompanions(plan *PlanController, plants *PlantController, maxDistance float32) []Companion {
+	// Features with a known plant, in a stable order.
+	ids := []models.FeatureID{}
+	for id, f := range plan.Plan.Features {
+		if f != nil && plants.HasPlant(f.PlantID()) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	companions := []Companion{}
+	for _, a := range ids {
+		shapeA := plan.Plan.Shape(a)
+		plantA, _ := plants.GetPlant(plan.Plan.Features[a].PlantID())
+
+		// Outlines are never closer than their bounds. Each pair is checked
+		// once, from the feature with the lower ID.
+		for _, b := range plan.FeaturesNear(shapeA.Bounds(), maxDistance) {
+			if b <= a || !plants.HasPlant(plan.Plan.Features[b].PlantID()) {
+				continue
+			}
+			shapeB := plan.Plan.Shape(b)
+			distance := geometry.Gap(shapeA, shapeB)
+			if distance > maxDistance {
+				continue
+			}
+
+			plantB, _ := plants.GetPlant(plan.Plan.Features[b].PlantID())
+			interaction := models.CombinedInteraction(plantA, plantB)
+			if interaction == models.NEUTRAL {
+				continue
+			}
+
+			companions = append(companions, Companion{
+				A:               a,
+				B:               b,
+				PlantA:          plantA.ID,
+				PlantB:          plantB.ID,
+				InteractionType: interaction,
+				Distance:        distance,
+			})
+		}
+	}
+
+	return companions
+}
+
+// Companions involving one feature, with that feature always as A.
+func CompanionsOf(id models.FeatureID, companions []Companion) []Companion {
+	found := []Companion{}
+	for _, c := range companions {
+		switch id {
+		case c.A:
+			found = append(found, c)
+		case c.B:
+			found = append(found, Companion{
+				A:               c.B,
+				B:               c.A,
+				PlantA:          c.PlantB,
+				PlantB:          c.PlantA,
+				InteractionType: c.InteractionType,
+				Distance:        c.Distance,
+			})
+		}
+	}
+	return found
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/cpgillem/garden-planner/geometry"
+	"github.com/cpgillem/garden-planner/models"
+)
+
+func TestFindCompanions(t *testing.T) {
+	plants := newTestPlantController()
+	c := NewPlanController(models.NewPlan())
+
+	row := func(x float32, plantID int) models.FeatureID {
+		return c.AddFeature(models.Feature{
+			Box:        geometry.NewBox(x, 0, 10, 100),
+			Properties: map[string]models.PropertyValue{"plant_id": models.NewPlantValue(plantID)},
+		})
+	}
+	tomato := row(0, 1)
+	basil := row(15, 2)
+	farBasil := row(100, 2)
+
+	companions := FindCompanions(&c, &plants, 12)
+	if len(companions) != 1 {
+		t.Fatalf("len(FindCompanions()) == %d; want 1", len(companions))
+	}
+	if got := CompanionsOf(basil, companions); len(got) != 1 || got[0].B != tomato || got[0].InteractionType != models.BENEFICIAL {
+		t.Errorf("CompanionsOf(basil) == %v; want tomato, beneficial", got)
+	}
+	if got := CompanionsOf(farBasil, companions); len(got) != 0 {
+		t.Errorf("CompanionsOf(farBasil) == %v; want none", got)
+	}
+}
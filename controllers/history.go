@@ -0,0 +1,152 @@
+package controllers
+
+// A reversible change to application data.
+type Command interface {
+	Do()
+	Undo()
+}
+
+// Implemented by commands that can absorb a following command, so that a
+// stream of small edits (e.g. drag events) is stored as one command.
+type mergeable interface {
+	Merge(next Command) bool
+}
+
+// Several commands that are done and undone as a single step.
+type commandGroup []Command
+
+func (g commandGroup) Do() {
+	for _, cmd := range g {
+		cmd.Do()
+	}
+}
+
+func (g commandGroup) Undo() {
+	// Undo in reverse order so each command sees the state it produced.
+	for i := len(g) - 1; i >= 0; i-- {
+		g[i].Undo()
+	}
+}
+
+// Undo and redo stacks of commands.
+type History struct {
+	undoStack []Command
+	redoStack []Command
+
+	// Commands collected while a group is open.
+	group      commandGroup
+	groupDepth int
+}
+
+func NewHistory() *History {
+	return &History{
+		undoStack: []Command{},
+		redoStack: []Command{},
+	}
+}
+
+// Does a command and records it so it can be undone.
+func (h *History) Execute(cmd Command) {
+	cmd.Do()
+	h.record(cmd)
+}
+
+// Records a command that has already been done.
+func (h *History) record(cmd Command) {
+	if h.groupDepth > 0 {
+		if len(h.group) > 0 {
+			if last, ok := h.group[len(h.group)-1].(mergeable); ok && last.Merge(cmd) {
+				return
+			}
+		}
+		h.group = append(h.group, cmd)
+		return
+	}
+
+	h.undoStack = append(h.undoStack, cmd)
+	h.redoStack = []Command{}
+}
+
+// Starts collecting commands into one undo step. Groups may be nested; only
+// the outermost EndGroup records the step.
+func (h *History) BeginGroup() {
+	h.groupDepth++
+}
+
+// Finishes the current group. Empty groups are discarded.
+func (h *History) EndGroup() {
+	if h.groupDepth == 0 {
+		return
+	}
+
+	h.groupDepth--
+	if h.groupDepth > 0 {
+		return
+	}
+
+	group := h.group
+	h.group = nil
+	if len(group) > 0 {
+		h.record(group)
+	}
+}
+
+func (h *History) InGroup() bool {
+	return h.groupDepth > 0
+}
+
+func (h *History) CanUndo() bool {
+	return len(h.undoStack) > 0 && !h.InGroup()
+}
+
+func (h *History) CanRedo() bool {
+	return len(h.redoStack) > 0 && !h.InGroup()
+}
+
+// The step Undo would revert, or nil.
+func (h *History) nextUndo() Command {
+	if !h.CanUndo() {
+		return nil
+	}
+	return h.undoStack[len(h.undoStack)-1]
+}
+
+// The step Redo would reapply, or nil.
+func (h *History) nextRedo() Command {
+	if !h.CanRedo() {
+		return nil
+	}
+	return h.redoStack[len(h.redoStack)-1]
+}
+
+// Reverts the most recent step.
+func (h *History) Undo() {
+	if !h.CanUndo() {
+		return
+	}
+
+	cmd := h.undoStack[len(h.undoStack)-1]
+	h.undoStack = h.undoStack[:len(h.undoStack)-1]
+	cmd.Undo()
+	h.redoStack = append(h.redoStack, cmd)
+}
+
+// Reapplies the most recently undone step.
+func (h *History) Redo() {
+	if !h.CanRedo() {
+		return
+	}
+
+	cmd := h.redoStack[len(h.redoStack)-1]
+	h.redoStack = h.redoStack[:len(h.redoStack)-1]
+	cmd.Do()
+	h.undoStack = append(h.undoStack, cmd)
+}
+
+// Forgets all recorded steps.
+func (h *History) Clear() {
+	h.undoStack = []Command{}
+	h.redoStack = []Command{}
+	h.group = nil
+	h.groupDepth = 0
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/cpgillem/garden-planner/geometry"
+	"github.com/cpgillem/garden-planner/models"
+)
+
+func TestUndoRedo(t *testing.T) {
+	c := NewPlanController(models.NewPlan())
+	id := c.AddFeature(models.Feature{Name: "Bed", Properties: map[string]models.PropertyValue{}})
+
+	c.SetFeatureName(id, "Raised Bed")
+	c.Undo()
+	if got := c.Plan.Features[id].Name; got != "Bed" {
+		t.Errorf("after undo, name == %q; want %q", got, "Bed")
+	}
+
+	c.Redo()
+	if got := c.Plan.Features[id].Name; got != "Raised Bed" {
+		t.Errorf("after redo, name == %q; want %q", got, "Raised Bed")
+	}
+
+	// Undo the rename and the add.
+	c.Undo()
+	c.Undo()
+	if c.HasFeature(id) {
+		t.Errorf("feature still exists after undoing its addition")
+	}
+	if c.CanUndo() {
+		t.Errorf("CanUndo() == true with an empty history")
+	}
+}
+
+func TestGestureUndoesAsOneStep(t *testing.T) {
+	c := NewPlanController(models.NewPlan())
+	id := c.AddFeature(models.Feature{Box: geometry.NewBox(0, 0, 10, 10), Properties: map[string]models.PropertyValue{}})
+
+	c.BeginGesture()
+	for i := 0; i < 5; i++ {
+		delta := geometry.NewBox(1, 2, 0, 0)
+		c.MoveResizeFeature(id, &delta)
+	}
+	c.EndGesture()
+
+	if got := c.Plan.Features[id].Box; got != geometry.NewBox(5, 10, 10, 10) {
+		t.Errorf("after drag, box == %v; want %v", got, geometry.NewBox(5, 10, 10, 10))
+	}
+
+	c.Undo()
+	if got := c.Plan.Features[id].Box; got != geometry.NewBox(0, 0, 10, 10) {
+		t.Errorf("after undo, box == %v; want %v", got, geometry.NewBox(0, 0, 10, 10))
+	}
+
+	// The feature itself is still there; only the drag was undone.
+	if !c.HasFeature(id) {
+		t.Errorf("undoing a drag removed the feature")
+	}
+}
+
+func TestGestureDisablesUndo(t *testing.T) {
+	c := NewPlanController(models.NewPlan())
+	id := c.AddFeature(models.Feature{Box: geometry.NewBox(0, 0, 10, 10), Properties: map[string]models.PropertyValue{}})
+
+	canUndo := []bool{}
+	c.OnHistoryChanged = func() { canUndo = append(canUndo, c.CanUndo()) }
+
+	c.BeginGesture()
+	delta := geometry.NewBox(1, 2, 0, 0)
+	c.MoveResizeFeature(id, &delta)
+	c.EndGesture()
+
+	if len(canUndo) != 2 || canUndo[0] || !canUndo[1] {
+		t.Errorf("CanUndo() at each history change == %v; want [false true]", canUndo)
+	}
+}
@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"github.com/cpgillem/garden-planner/geometry"
+	"github.com/cpgillem/garden-planner/models"
+)
+
+// Adds a feature to the plan.
+type addFeatureCommand struct {
+	c       *PlanController
+	id      models.FeatureID
+	feature *models.Feature
+}
+
+func (cmd *addFeatureCommand) Do() {
+	cmd.c.insertFeature(cmd.id, cmd.feature)
+}
+
+func (cmd *addFeatureCommand) Undo() {
+	cmd.c.deleteFeature(cmd.id)
+}
+
+// Removes a feature from the plan.
+type removeFeatureCommand struct {
+	c       *PlanController
+	id      models.FeatureID
+	feature *models.Feature
+}
+
+func (cmd *removeFeatureCommand) Do() {
+	cmd.c.deleteFeature(cmd.id)
+}
+
+func (cmd *removeFeatureCommand) Undo() {
+	cmd.c.insertFeature(cmd.id, cmd.feature)
+}
+
+// Replaces the box of a feature.
+type setFeatureBoxCommand struct {
+	c      *PlanController
+	id     models.FeatureID
+	before geometry.Box
+	after  geometry.Box
+}
+
+func (cmd *setFeatureBoxCommand) Do() {
+	cmd.c.setFeatureBox(cmd.id, cmd.after)
+}
+
+func (cmd *setFeatureBoxCommand) Undo() {
+	cmd.c.setFeatureBox(cmd.id, cmd.before)
+}
+
+// Consecutive box changes to the same feature collapse into one.
+func (cmd *setFeatureBoxCommand) Merge(next Command) bool {
+	n, ok := next.(*setFeatureBoxCommand)
+	if !ok || n.id != cmd.id {
+		return false
+	}
+
+	cmd.after = n.after
+	return true
+}
+
+// Moves a feature under another parent. The box and rotation change along
+// with it so the feature stays in place.
+type setFeatureParentCommand struct {
+	c              *PlanController
+	id             models.FeatureID
+	beforeParent   models.FeatureID
+	afterParent    models.FeatureID
+	beforeBox      geometry.Box
+	afterBox       geometry.Box
+	beforeRotation float32
+	afterRotation  float32
+}
+
+func (cmd *setFeatureParentCommand) Do() {
+	cmd.c.setFeatureParent(cmd.id, cmd.afterParent, cmd.afterBox, cmd.afterRotation)
+}
+
+func (cmd *setFeatureParentCommand) Undo() {
+	cmd.c.setFeatureParent(cmd.id, cmd.beforeParent, cmd.beforeBox, cmd.beforeRotation)
+}
+
+// Turns a feature.
+type setFeatureRotationCommand struct {
+	c      *PlanController
+	id     models.FeatureID
+	before float32
+	after  float32
+}
+
+func (cmd *setFeatureRotationCommand) Do() {
+	cmd.c.setFeatureRotation(cmd.id, cmd.after)
+}
+
+func (cmd *setFeatureRotationCommand) Undo() {
+	cmd.c.setFeatureRotation(cmd.id, cmd.before)
+}
+
+// Consecutive turns of the same feature collapse into one.
+func (cmd *setFeatureRotationCommand) Merge(next Command) bool {
+	n, ok := next.(*setFeatureRotationCommand)
+	if !ok || n.id != cmd.id {
+		return false
+	}
+
+	cmd.after = n.after
+	return true
+}
+
+// Replaces the outline of a feature. Moving polygon corners changes the box
+// too.
+type setFeatureShapeCommand struct {
+	c           *PlanController
+	id          models.FeatureID
+	beforeShape *models.FeatureShape
+	afterShape  *models.FeatureShape
+	beforeBox   geometry.Box
+	afterBox    geometry.Box
+}
+
+func (cmd *setFeatureShapeCommand) Do() {
+	cmd.c.setFeatureShape(cmd.id, cmd.afterShape, cmd.afterBox)
+}
+
+func (cmd *setFeatureShapeCommand) Undo() {
+	cmd.c.setFeatureShape(cmd.id, cmd.beforeShape, cmd.beforeBox)
+}
+
+// Consecutive shape changes to the same feature collapse into one.
+func (cmd *setFeatureShapeCommand) Merge(next Command) bool {
+	n, ok := next.(*setFeatureShapeCommand)
+	if !ok || n.id != cmd.id {
+		return false
+	}
+
+	cmd.afterShape = n.afterShape
+	cmd.afterBox = n.afterBox
+	return true
+}
+
+// Renames a feature.
+type setFeatureNameCommand struct {
+	c      *PlanController
+	id     models.FeatureID
+	before string
+	after  string
+}
+
+func (cmd *setFeatureNameCommand) Do() {
+	cmd.c.setFeatureName(cmd.id, cmd.after)
+}
+
+func (cmd *setFeatureNameCommand) Undo() {
+	cmd.c.setFeatureName(cmd.id, cmd.before)
+}
+
+// Sets a custom property on a feature.
+type setFeaturePropertyCommand struct {
+	c         *PlanController
+	id        models.FeatureID
+	name      string
+	before    models.PropertyValue
+	hadBefore bool
+	after     models.PropertyValue
+}
+
+func (cmd *setFeaturePropertyCommand) Do() {
+	cmd.c.setFeatureProperty(cmd.id, cmd.name, cmd.after)
+}
+
+func (cmd *setFeaturePropertyCommand) Undo() {
+	if cmd.hadBefore {
+		cmd.c.setFeatureProperty(cmd.id, cmd.name, cmd.before)
+	} else {
+		cmd.c.deleteFeatureProperty(cmd.id, cmd.name)
+	}
+}
+
+// Replaces the irrigation network as a whole.
+type setIrrigationCommand struct {
+	c      *PlanController
+	before models.Irrigation
+	after  models.Irrigation
+}
+
+func (cmd *setIrrigationCommand) Do() {
+	cmd.c.setIrrigation(cmd.after)
+}
+
+func (cmd *setIrrigationCommand) Undo() {
+	cmd.c.setIrrigation(cmd.before)
+}
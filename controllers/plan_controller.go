@@ -1,34 +1,222 @@
 package controllers
 
 import (
+	"fmt"
+	"math"
+	"sort"
+
 	"github.com/cpgillem/garden-planner/geometry"
 	"github.com/cpgillem/garden-planner/models"
 )
 
 // Takes care of plan data in one place. Fires data-related events.
+// Every change to the plan goes through a command so it can be undone.
 type PlanController struct {
 	Plan *models.Plan
 
 	selectedFeature models.FeatureID
+	history         *History
+
+	// Bounds of every feature's outline on the plan, for finding features
+	// by location.
+	index *geometry.QuadTree[models.FeatureID]
+
+	// Refuses changes that would make a feature overlap another or leave
+	// the plan.
+	PreventCollisions bool
 
 	// Defines how to refresh UI code.
-	OnFeatureSelected func(id models.FeatureID)
-	OnFeatureAdded    func(id models.FeatureID)
-	OnFeatureRemoved  func(id models.FeatureID)
+	OnFeatureSelected   func(id models.FeatureID)
+	OnFeatureAdded      func(id models.FeatureID)
+	OnFeatureRemoved    func(id models.FeatureID)
+	OnFeatureChanged    func(id models.FeatureID)
+	OnIrrigationChanged func()
+	OnHistoryChanged    func()
 }
 
 func NewPlanController(plan *models.Plan) PlanController {
-	return PlanController{
-		Plan:              plan,
-		OnFeatureSelected: func(id models.FeatureID) {},
-		OnFeatureAdded:    func(id models.FeatureID) {},
-		OnFeatureRemoved:  func(id models.FeatureID) {},
-		selectedFeature:   -1,
+	if plan.Features == nil {
+		plan.Features = map[models.FeatureID]*models.Feature{}
+	}
+	plan.Irrigation.Normalize()
+
+	c := PlanController{
+		Plan:                plan,
+		OnFeatureSelected:   func(id models.FeatureID) {},
+		OnFeatureAdded:      func(id models.FeatureID) {},
+		OnFeatureRemoved:    func(id models.FeatureID) {},
+		OnFeatureChanged:    func(id models.FeatureID) {},
+		OnIrrigationChanged: func() {},
+		OnHistoryChanged:    func() {},
+		selectedFeature:     models.NoFeature,
+		history:             NewHistory(),
+		index:               geometry.NewQuadTree[models.FeatureID](plan.Box),
 	}
+	for id, f := range plan.Features {
+		if f != nil {
+			c.index.Insert(id, plan.Shape(id).Bounds())
+		}
+	}
+	return c
 }
 
+// Moves and/or resizes a feature by a delta. While a gesture is open, all
+// deltas are recorded as a single undo step.
 func (c *PlanController) MoveResizeFeature(id models.FeatureID, boxDelta *geometry.Box) {
-	c.Plan.Features[id].Box.AddTo(boxDelta)
+	if !c.HasFeature(id) {
+		return
+	}
+
+	box := c.Plan.Features[id].Box.Copy()
+	box.AddTo(boxDelta)
+	c.SetFeatureBox(id, box)
+}
+
+func (c *PlanController) SetFeatureBox(id models.FeatureID, box geometry.Box) {
+	if !c.HasFeature(id) || c.blocked(id, func(f *models.Feature) { f.Box = box }) {
+		return
+	}
+
+	c.execute(&setFeatureBoxCommand{
+		c:      c,
+		id:     id,
+		before: c.Plan.Features[id].Box.Copy(),
+		after:  box,
+	})
+}
+
+// Resizes a feature by a delta given in its own turned frame, e.g. from
+// dragging an edge handle. Edges that don't change stay in place.
+func (c *PlanController) ResizeFeature(id models.FeatureID, boxDelta *geometry.Box) {
+	if !c.HasFeature(id) {
+		return
+	}
+
+	f := c.Plan.Features[id]
+	box := f.Box.Copy()
+	box.AddTo(boxDelta)
+	c.SetFeatureBox(id, geometry.KeepInPlace(f.Box, box, f.Rotation))
+}
+
+// Turns a feature to an angle in degrees, clockwise around the center of
+// its box.
+func (c *PlanController) SetFeatureRotation(id models.FeatureID, degrees float32) {
+	if !c.HasFeature(id) {
+		return
+	}
+
+	degrees = float32(math.Mod(float64(degrees), 360))
+	if degrees < 0 {
+		degrees += 360
+	}
+	if c.Plan.Features[id].Rotation == degrees || c.blocked(id, func(f *models.Feature) { f.Rotation = degrees }) {
+		return
+	}
+
+	c.execute(&setFeatureRotationCommand{
+		c:      c,
+		id:     id,
+		before: c.Plan.Features[id].Rotation,
+		after:  degrees,
+	})
+}
+
+// Replaces the outline of a feature. Nil makes it a rectangle.
+func (c *PlanController) SetFeatureShape(id models.FeatureID, shape *models.FeatureShape) error {
+	if !c.HasFeature(id) {
+		return fmt.Errorf("feature %s does not exist", id)
+	}
+	if err := shape.Check(); err != nil {
+		return err
+	}
+	if c.blocked(id, func(f *models.Feature) { f.Shape = shape }) {
+		return fmt.Errorf("%s would overlap another feature or leave the plan", c.Plan.Features[id].Name)
+	}
+
+	f := c.Plan.Features[id]
+	c.execute(&setFeatureShapeCommand{
+		c:           c,
+		id:          id,
+		beforeShape: f.Shape.Copy(),
+		afterShape:  shape.Copy(),
+		beforeBox:   f.Box.Copy(),
+		afterBox:    f.Box.Copy(),
+	})
+	return nil
+}
+
+// Moves one corner of a polygon feature by a delta on the plan. The box
+// grows or shrinks to fit the corners, so the others stay in place.
+func (c *PlanController) MoveVertex(id models.FeatureID, index int, delta geometry.Vector) error {
+	points, err := c.polygonPoints(id, index)
+	if err != nil {
+		return err
+	}
+
+	// Corners are kept in the feature's unturned frame.
+	_, degrees := c.Plan.Placement(id)
+	delta = delta.Rotate(geometry.NewVector(0, 0, 0), -degrees)
+	points[index].AddTo(&delta)
+	c.setPolygonPoints(id, points)
+	return nil
+}
+
+// Adds a corner to a polygon feature halfway along the side after a corner.
+func (c *PlanController) InsertVertex(id models.FeatureID, after int) error {
+	points, err := c.polygonPoints(id, after)
+	if err != nil {
+		return err
+	}
+
+	a, b := points[after], points[(after+1)%len(points)]
+	middle := geometry.NewVector((a.X+b.X)/2, (a.Y+b.Y)/2, 0)
+	points = append(points[:after+1], append([]geometry.Vector{middle}, points[after+1:]...)...)
+	c.setPolygonPoints(id, points)
+	return nil
+}
+
+// Removes a corner from a polygon feature. Polygons keep at least three.
+func (c *PlanController) RemoveVertex(id models.FeatureID, index int) error {
+	points, err := c.polygonPoints(id, index)
+	if err != nil {
+		return err
+	}
+	if len(points) <= 3 {
+		return fmt.Errorf("a polygon needs at least 3 corners")
+	}
+
+	points = append(points[:index], points[index+1:]...)
+	c.setPolygonPoints(id, points)
+	return nil
+}
+
+func (c *PlanController) SetFeatureName(id models.FeatureID, name string) {
+	if !c.HasFeature(id) || c.Plan.Features[id].Name == name {
+		return
+	}
+
+	c.execute(&setFeatureNameCommand{
+		c:      c,
+		id:     id,
+		before: c.Plan.Features[id].Name,
+		after:  name,
+	})
+}
+
+func (c *PlanController) SetFeatureProperty(id models.FeatureID, name string, value models.PropertyValue) {
+	if !c.HasFeature(id) {
+		return
+	}
+
+	before, hadBefore := c.Plan.Features[id].Properties[name]
+	c.execute(&setFeaturePropertyCommand{
+		c:         c,
+		id:        id,
+		name:      name,
+		before:    before,
+		hadBefore: hadBefore,
+		after:     value,
+	})
 }
 
 func (c *PlanController) SelectFeature(id models.FeatureID) {
@@ -44,15 +232,205 @@ func (c *PlanController) HasSelection() bool {
 	return c.Plan.Features[c.GetSelectedFeature()] != nil
 }
 
-func (c *PlanController) AddFeature(f models.Feature) {
+// Adds a feature to the plan and returns its new ID.
+func (c *PlanController) AddFeature(f models.Feature) models.FeatureID {
 	id := c.NewFeatureID()
-	c.Plan.Features[id] = &f
-	c.OnFeatureAdded(id)
+	c.execute(&addFeatureCommand{
+		c:       c,
+		id:      id,
+		feature: &f,
+	})
+	return id
+}
+
+// Adds several features as one undo step and returns their IDs in order.
+func (c *PlanController) AddFeatures(features []models.Feature) []models.FeatureID {
+	ids := []models.FeatureID{}
+	c.history.BeginGroup()
+	for _, f := range features {
+		ids = append(ids, c.AddFeature(f))
+	}
+	c.history.EndGroup()
+	if !c.history.InGroup() {
+		c.OnHistoryChanged()
+	}
+	return ids
 }
 
+// Removes a feature along with every feature nested inside it, as one undo
+// step.
 func (c *PlanController) RemoveFeature(id models.FeatureID) {
-	// c.Plan.Features[id] = nil
-	c.OnFeatureRemoved(id)
+	if !c.HasFeature(id) {
+		return
+	}
+
+	// Remove children before their parents, so undo restores parents first.
+	ids := append([]models.FeatureID{id}, c.Plan.Descendants(id)...)
+	c.history.BeginGroup()
+	for i := len(ids) - 1; i >= 0; i-- {
+		c.history.Execute(&removeFeatureCommand{
+			c:       c,
+			id:      ids[i],
+			feature: c.Plan.Features[ids[i]],
+		})
+	}
+	c.history.EndGroup()
+	if !c.history.InGroup() {
+		c.OnHistoryChanged()
+	}
+}
+
+// Nests a feature inside another, or makes it a root feature if parent is
+// NoFeature. The feature keeps its position on the plan.
+func (c *PlanController) SetFeatureParent(id models.FeatureID, parent models.FeatureID) error {
+	if !c.HasFeature(id) {
+		return fmt.Errorf("feature %s does not exist", id)
+	}
+	f := c.Plan.Features[id]
+	if parent != models.NoFeature {
+		if !c.HasFeature(parent) {
+			return fmt.Errorf("feature %s does not exist", parent)
+		}
+		if parent == id || c.Plan.IsDescendant(parent, id) {
+			return fmt.Errorf("cannot put %s inside itself", f.Name)
+		}
+	}
+	if f.Parent == parent {
+		return nil
+	}
+
+	// Keep the feature where it is on the plan. The box becomes relative to
+	// the new parent, and the turn what is left after the parent's.
+	placed, degrees := c.Plan.Placement(id)
+	center := c.Plan.Unturn(parent, placed.Center())
+	box := f.Box.Copy()
+	box.Location = geometry.NewVector(center.X-box.Size.X/2, center.Y-box.Size.Y/2, 0)
+	if parent != models.NoFeature {
+		parentBox := c.Plan.AbsoluteBox(parent)
+		box.Location.AddTo(parentBox.Location.Negate())
+	}
+	_, parentDegrees := c.Plan.Placement(parent)
+
+	c.execute(&setFeatureParentCommand{
+		c:              c,
+		id:             id,
+		beforeParent:   f.Parent,
+		afterParent:    parent,
+		beforeBox:      f.Box.Copy(),
+		afterBox:       box,
+		beforeRotation: f.Rotation,
+		afterRotation:  degrees - parentDegrees,
+	})
+	return nil
+}
+
+// Replaces the irrigation network. Callers change a copy, e.g. from
+// Plan.Irrigation.Copy(), and pass it in so the change can be undone.
+func (c *PlanController) SetIrrigation(irrigation models.Irrigation) {
+	c.execute(&setIrrigationCommand{
+		c:      c,
+		before: c.Plan.Irrigation.Copy(),
+		after:  irrigation.Copy(),
+	})
+}
+
+// Corners of a polygon feature relative to its parent, before it is turned,
+// checking that a corner index is in range.
+func (c *PlanController) polygonPoints(id models.FeatureID, index int) ([]geometry.Vector, error) {
+	if !c.HasFeature(id) {
+		return nil, fmt.Errorf("feature %s does not exist", id)
+	}
+	f := c.Plan.Features[id]
+	if f.Shape.GetKind() != models.SHAPE_POLYGON {
+		return nil, fmt.Errorf("%s is not a polygon", f.Name)
+	}
+	if index < 0 || index >= len(f.Shape.Points) {
+		return nil, fmt.Errorf("%s has no corner %d", f.Name, index)
+	}
+
+	return f.Shape.In(f.Box).Outline(), nil
+}
+
+// Replaces the corners of a polygon feature, refitting its box.
+func (c *PlanController) setPolygonPoints(id models.FeatureID, points []geometry.Vector) {
+	f := c.Plan.Features[id]
+	box, fractions := models.FitPoints(points)
+	box = geometry.KeepInPlace(f.Box, box, f.Rotation)
+	shape := f.Shape.Copy()
+	shape.Points = fractions
+	if c.blocked(id, func(f *models.Feature) { f.Shape, f.Box = shape, box }) {
+		return
+	}
+
+	c.execute(&setFeatureShapeCommand{
+		c:           c,
+		id:          id,
+		beforeShape: f.Shape.Copy(),
+		afterShape:  shape,
+		beforeBox:   f.Box.Copy(),
+		afterBox:    box,
+	})
+}
+
+// Whether a change to a feature must be refused, while collisions are
+// prevented, because the feature would collide. Features that already
+// collide may still change, so they can be moved clear.
+func (c *PlanController) blocked(id models.FeatureID, change func(f *models.Feature)) bool {
+	if !c.PreventCollisions || c.collides(id) {
+		return false
+	}
+
+	f := c.Plan.Features[id]
+	saved := *f
+	change(f)
+	blocked := c.collides(id)
+	*f = saved
+	return blocked
+}
+
+// Features whose outlines may reach into an area, going by the box around
+// each outline, sorted by ID.
+func (c *PlanController) FeaturesIn(area geometry.Box) []models.FeatureID {
+	return c.FeaturesNear(area, 0)
+}
+
+// Features whose outlines may come within a distance of an area, going by
+// the box around each outline, sorted by ID.
+func (c *PlanController) FeaturesNear(area geometry.Box, distance float32) []models.FeatureID {
+	ids := c.index.Within(area, distance)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Feature whose outline is nearest a point, going by the box around each
+// outline, or NoFeature if the plan is empty.
+func (c *PlanController) NearestFeature(p geometry.Vector) models.FeatureID {
+	if id, ok := c.index.Nearest(p); ok {
+		return id
+	}
+	return models.NoFeature
+}
+
+// Topmost feature whose outline contains a point, or NoFeature.
+func (c *PlanController) FeatureAt(p geometry.Vector) models.FeatureID {
+	return c.Plan.TopmostAt(p, c.FeaturesIn(geometry.NewBox(p.X, p.Y, 0, 0)))
+}
+
+// Edge of a feature's outline nearest a point, if one is within a
+// distance.
+func (c *PlanController) EdgeNear(p geometry.Vector, distance float32) (geometry.Segment, bool) {
+	edge, best, found := geometry.Segment{}, distance, false
+	for _, id := range c.FeaturesNear(geometry.NewBox(p.X, p.Y, 0, 0), distance) {
+		if e, d, ok := geometry.NearestEdge(c.Plan.Shape(id).Outline(), p); ok && d <= best {
+			edge, best, found = e, d, true
+		}
+	}
+	return edge, found
+}
+
+// Box of a feature in plan coordinates, including the offsets of its parents.
+func (c *PlanController) AbsoluteBox(id models.FeatureID) geometry.Box {
+	return c.Plan.AbsoluteBox(id)
 }
 
 func (c *PlanController) RemoveSelected() {
@@ -61,15 +439,11 @@ func (c *PlanController) RemoveSelected() {
 	}
 }
 
+// Creates a new feature ID. IDs are UUIDs, so they are never reused, even
+// after the feature holding one is removed, and history and references to
+// removed features stay unambiguous.
 func (c *PlanController) NewFeatureID() models.FeatureID {
-	// Find max ID.
-	max := models.FeatureID(-1)
-	for i := range c.Plan.Features {
-		if i > max {
-			max = i
-		}
-	}
-	return max + 1
+	return models.NewFeatureID()
 }
 
 func (c *PlanController) GetMaxName() string {
@@ -91,3 +465,136 @@ func (c *PlanController) LenFeatures() int {
 func (c *PlanController) HasFeature(id models.FeatureID) bool {
 	return c.Plan.Features[id] != nil
 }
+
+// Lists the features with a property that references the given plant.
+func (c *PlanController) FeaturesWithPlant(plantID int) []models.FeatureID {
+	ids := []models.FeatureID{}
+	for id, f := range c.Plan.Features {
+		for _, value := range f.Properties {
+			if value.Type != models.PLANT {
+				continue
+			}
+			if v, _ := value.PlantID(); v == plantID {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// History
+
+// Starts a gesture, such as a drag. Changes until EndGesture undo as one step.
+func (c *PlanController) BeginGesture() {
+	c.history.BeginGroup()
+	c.OnHistoryChanged()
+}
+
+func (c *PlanController) EndGesture() {
+	c.history.EndGroup()
+	c.OnHistoryChanged()
+}
+
+func (c *PlanController) InGesture() bool {
+	return c.history.InGroup()
+}
+
+func (c *PlanController) Undo() {
+	c.history.Undo()
+	c.OnHistoryChanged()
+}
+
+func (c *PlanController) Redo() {
+	c.history.Redo()
+	c.OnHistoryChanged()
+}
+
+func (c *PlanController) CanUndo() bool {
+	return c.history.CanUndo()
+}
+
+func (c *PlanController) CanRedo() bool {
+	return c.history.CanRedo()
+}
+
+func (c *PlanController) execute(cmd Command) {
+	c.history.Execute(cmd)
+	if !c.history.InGroup() {
+		c.OnHistoryChanged()
+	}
+}
+
+// Mutations used by commands. These fire events but are not recorded.
+
+func (c *PlanController) insertFeature(id models.FeatureID, f *models.Feature) {
+	c.Plan.Features[id] = f
+	c.reindex(id)
+	c.OnFeatureAdded(id)
+}
+
+func (c *PlanController) deleteFeature(id models.FeatureID) {
+	delete(c.Plan.Features, id)
+	c.index.Remove(id)
+	c.OnFeatureRemoved(id)
+
+	// Clear references to the removed feature.
+	if c.selectedFeature == id {
+		c.selectedFeature = models.NoFeature
+	}
+}
+
+func (c *PlanController) setFeatureBox(id models.FeatureID, box geometry.Box) {
+	c.Plan.Features[id].Box = box.Copy()
+	c.reindex(id)
+	c.OnFeatureChanged(id)
+}
+
+func (c *PlanController) setFeatureParent(id models.FeatureID, parent models.FeatureID, box geometry.Box, degrees float32) {
+	c.Plan.Features[id].Parent = parent
+	c.Plan.Features[id].Box = box.Copy()
+	c.Plan.Features[id].Rotation = degrees
+	c.reindex(id)
+	c.OnFeatureChanged(id)
+}
+
+func (c *PlanController) setFeatureShape(id models.FeatureID, shape *models.FeatureShape, box geometry.Box) {
+	c.Plan.Features[id].Shape = shape.Copy()
+	c.Plan.Features[id].Box = box.Copy()
+	c.reindex(id)
+	c.OnFeatureChanged(id)
+}
+
+func (c *PlanController) setFeatureRotation(id models.FeatureID, degrees float32) {
+	c.Plan.Features[id].Rotation = degrees
+	c.reindex(id)
+	c.OnFeatureChanged(id)
+}
+
+// Updates the index for a feature and the features nested inside it, which
+// move with it.
+func (c *PlanController) reindex(id models.FeatureID) {
+	for _, i := range append([]models.FeatureID{id}, c.Plan.Descendants(id)...) {
+		c.index.Insert(i, c.Plan.Shape(i).Bounds())
+	}
+}
+
+func (c *PlanController) setIrrigation(irrigation models.Irrigation) {
+	c.Plan.Irrigation = irrigation.Copy()
+	c.OnIrrigationChanged()
+}
+
+func (c *PlanController) setFeatureName(id models.FeatureID, name string) {
+	c.Plan.Features[id].Name = name
+	c.OnFeatureChanged(id)
+}
+
+func (c *PlanController) setFeatureProperty(id models.FeatureID, name string, value models.PropertyValue) {
+	c.Plan.Features[id].Properties[name] = value
+	c.OnFeatureChanged(id)
+}
+
+func (c *PlanController) deleteFeatureProperty(id models.FeatureID, name string) {
+	delete(c.Plan.Features[id].Properties, name)
+	c.OnFeatureChanged(id)
+}
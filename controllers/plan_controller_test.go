@@ -0,0 +1,314 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/cpgillem/garden-planner/geometry"
+	"github.com/cpgillem/garden-planner/models"
+)
+
+func TestRemoveFeature(t *testing.T) {
+	c := NewPlanController(models.NewPlan())
+	id := c.AddFeature(models.Feature{Name: "Bed", Properties: map[string]models.PropertyValue{}})
+	c.SelectFeature(id)
+
+	c.RemoveFeature(id)
+	if c.HasFeature(id) {
+		t.Fatalf("feature %s still in plan after removal", id)
+	}
+	if c.HasSelection() {
+		t.Errorf("removed feature is still selected")
+	}
+
+	// IDs of removed features are not handed out again.
+	if next := c.AddFeature(models.Feature{Properties: map[string]models.PropertyValue{}}); next == id {
+		t.Errorf("AddFeature reused removed ID %s", id)
+	}
+
+	// Undoing the new addition and the removal brings back the original feature.
+	c.Undo()
+	c.Undo()
+	if !c.HasFeature(id) || c.Plan.Features[id].Name != "Bed" {
+		t.Errorf("undo did not restore feature %s", id)
+	}
+}
+
+func TestRemoveParentRemovesChildren(t *testing.T) {
+	c := NewPlanController(models.NewPlan())
+	bed := c.AddFeature(models.Feature{Name: "Bed", Properties: map[string]models.PropertyValue{}})
+	row := c.AddFeature(models.Feature{Name: "Row", Parent: bed, Properties: map[string]models.PropertyValue{}})
+
+	c.RemoveFeature(bed)
+	if c.HasFeature(row) {
+		t.Errorf("child still in plan after its parent was removed")
+	}
+
+	// One undo brings back the whole subtree.
+	c.Undo()
+	if !c.HasFeature(bed) || !c.HasFeature(row) {
+		t.Fatalf("undo did not restore the parent and child")
+	}
+	if got := c.Plan.Features[row].Parent; got != bed {
+		t.Errorf("restored child parent == %s; want %s", got, bed)
+	}
+}
+
+func TestSetFeatureParent(t *testing.T) {
+	c := NewPlanController(models.NewPlan())
+	bed := c.AddFeature(models.Feature{Name: "Bed", Box: geometry.NewBox(10, 20, 50, 50), Properties: map[string]models.PropertyValue{}})
+	row := c.AddFeature(models.Feature{Name: "Row", Box: geometry.NewBox(15, 30, 5, 40), Properties: map[string]models.PropertyValue{}})
+
+	if err := c.SetFeatureParent(row, bed); err != nil {
+		t.Fatalf("SetFeatureParent() error == %v", err)
+	}
+
+	// The row stays where it was on the plan.
+	if got := c.Plan.Features[row].Box; got != geometry.NewBox(5, 10, 5, 40) {
+		t.Errorf("relative box == %v; want %v", got, geometry.NewBox(5, 10, 5, 40))
+	}
+	if got := c.AbsoluteBox(row); got != geometry.NewBox(15, 30, 5, 40) {
+		t.Errorf("absolute box == %v; want %v", got, geometry.NewBox(15, 30, 5, 40))
+	}
+
+	// Moving the parent moves the child.
+	delta := geometry.NewBox(1, 1, 0, 0)
+	c.MoveResizeFeature(bed, &delta)
+	if got := c.AbsoluteBox(row); got != geometry.NewBox(16, 31, 5, 40) {
+		t.Errorf("absolute box after moving parent == %v; want %v", got, geometry.NewBox(16, 31, 5, 40))
+	}
+
+	// Features can't be nested inside their own children.
+	if err := c.SetFeatureParent(bed, row); err == nil {
+		t.Errorf("SetFeatureParent(parent, child) succeeded; want error")
+	}
+}
+
+func TestSetFeatureParentTurned(t *testing.T) {
+	c := NewPlanController(models.NewPlan())
+	bed := c.AddFeature(models.Feature{Name: "Bed", Box: geometry.NewBox(0, 0, 20, 10), Rotation: 90, Properties: map[string]models.PropertyValue{}})
+	row := c.AddFeature(models.Feature{Name: "Row", Box: geometry.NewBox(12, 0, 4, 2), Rotation: 30, Properties: map[string]models.PropertyValue{}})
+	before := c.Plan.Shape(row).Outline()
+
+	// Moving into a turned bed leaves the row where it was on the plan.
+	if err := c.SetFeatureParent(row, bed); err != nil {
+		t.Fatalf("SetFeatureParent() error == %v", err)
+	}
+	if got := c.Plan.Features[row].Rotation; got != -60 {
+		t.Errorf("Rotation == %v inside the bed; want -60", got)
+	}
+	for i, p := range c.Plan.Shape(row).Outline() {
+		if p.Distance(&before[i]) > 1e-3 {
+			t.Errorf("corner %d at %v after nesting; want %v", i, p, before[i])
+		}
+	}
+
+	c.Undo()
+	if got := c.Plan.Features[row]; got.Parent != models.NoFeature || got.Rotation != 30 || got.Box != geometry.NewBox(12, 0, 4, 2) {
+		t.Errorf("row == %+v after undo; want it back at the root", got)
+	}
+}
+
+func TestSetIrrigationUndo(t *testing.T) {
+	c := NewPlanController(models.NewPlan())
+
+	irrigation := c.Plan.Irrigation.Copy()
+	irrigation.Nodes["spigot"] = &models.IrrigationNode{Name: "Spigot", Source: true}
+	c.SetIrrigation(irrigation)
+
+	// The controller keeps its own copy.
+	irrigation.Nodes["spigot"].Name = "Changed"
+	if c.Plan.Irrigation.Nodes["spigot"].Name != "Spigot" {
+		t.Errorf("SetIrrigation did not copy the network")
+	}
+
+	c.Undo()
+	if len(c.Plan.Irrigation.Nodes) != 0 {
+		t.Errorf("undo left %d nodes; want 0", len(c.Plan.Irrigation.Nodes))
+	}
+	c.Redo()
+	if c.Plan.Irrigation.Nodes["spigot"] == nil {
+		t.Errorf("redo did not restore the spigot")
+	}
+}
+
+func TestMoveVertex(t *testing.T) {
+	c := NewPlanController(models.NewPlan())
+	id := c.AddFeature(models.Feature{
+		Name:       "Bed",
+		Box:        geometry.NewBox(0, 0, 10, 10),
+		Shape:      models.NewFeatureShape(models.SHAPE_POLYGON),
+		Properties: map[string]models.PropertyValue{},
+	})
+
+	// Dragging the top corner up grows the box without moving the others.
+	c.BeginGesture()
+	c.MoveVertex(id, 0, geometry.NewVector(0, -5, 0))
+	c.MoveVertex(id, 0, geometry.NewVector(0, -5, 0))
+	c.EndGesture()
+
+	box := c.Plan.Features[id].Box
+	if box != geometry.NewBox(0, -10, 10, 20) {
+		t.Errorf("box == %v; want it to grow to the new corner", box)
+	}
+	bottom := c.Plan.Shape(id).Outline()[2]
+	if bottom.X != 5 || bottom.Y != 10 {
+		t.Errorf("bottom corner == %v; want it to stay at (5, 10)", bottom)
+	}
+
+	if err := c.InsertVertex(id, 0); err != nil || len(c.Plan.Features[id].Shape.Points) != 5 {
+		t.Errorf("InsertVertex did not add a corner: %v", err)
+	}
+	c.RemoveVertex(id, 1)
+	c.RemoveVertex(id, 1)
+	if err := c.RemoveVertex(id, 1); err == nil {
+		t.Errorf("RemoveVertex left a polygon with 2 corners")
+	}
+
+	// Undo the removals, the insertion and then the whole drag.
+	for i := 0; i < 4; i++ {
+		c.Undo()
+	}
+	if c.Plan.Features[id].Box != geometry.NewBox(0, 0, 10, 10) {
+		t.Errorf("undo left box at %v", c.Plan.Features[id].Box)
+	}
+}
+
+func TestSetFeatureRotation(t *testing.T) {
+	c := NewPlanController(models.NewPlan())
+	id := c.AddFeature(models.Feature{Name: "Bed", Box: geometry.NewBox(0, 0, 10, 4), Properties: map[string]models.PropertyValue{}})
+
+	c.BeginGesture()
+	c.SetFeatureRotation(id, 45)
+	c.SetFeatureRotation(id, -90)
+	c.EndGesture()
+	if got := c.Plan.Features[id].Rotation; got != 270 {
+		t.Errorf("rotation == %v; want 270", got)
+	}
+
+	// Dragging the right edge of a turned bed keeps its left edge in place.
+	before := c.Plan.Shape(id).Outline()[0]
+	delta := geometry.NewBox(0, 0, 2, 0)
+	c.ResizeFeature(id, &delta)
+	after := c.Plan.Shape(id).Outline()[0]
+	if before.Distance(&after) > 0.001 {
+		t.Errorf("corner moved from %v to %v", before, after)
+	}
+
+	c.Undo()
+	c.Undo()
+	if got := c.Plan.Features[id].Rotation; got != 0 {
+		t.Errorf("rotation == %v after undo; want 0", got)
+	}
+}
+
+func TestFindCollisions(t *testing.T) {
+	plan := models.NewPlan()
+	plan.Box = geometry.NewBox(0, 0, 100, 100)
+	c := NewPlanController(plan)
+	bed := func(x float32, parent models.FeatureID) models.FeatureID {
+		return c.AddFeature(models.Feature{Box: geometry.NewBox(x, 0, 20, 20), Parent: parent, Properties: map[string]models.PropertyValue{}})
+	}
+	a := bed(0, models.NoFeature)
+	b := bed(10, models.NoFeature)
+	row := bed(0, a)
+	far := bed(50, models.NoFeature)
+
+	// The row is inside its bed, so only the two beds overlap.
+	collisions := FindCollisions(&c, 0)
+	if len(collisions) != 1 || collisions[0].Kind != geometry.OVERLAP {
+		t.Fatalf("FindCollisions() == %+v; want one overlap", collisions)
+	}
+	if got := CollisionsOf(b, collisions); len(got) != 1 || got[0].B != a {
+		t.Errorf("CollisionsOf(b) == %+v; want the overlap with a", got)
+	}
+	if got := CollisionsOf(row, collisions); len(got) != 0 {
+		t.Errorf("CollisionsOf(row) == %+v; want none", got)
+	}
+
+	// While collisions are prevented, moves into another bed or off the
+	// plan are refused.
+	c.PreventCollisions = true
+	c.SetFeatureBox(far, geometry.NewBox(35, 0, 20, 20))
+	c.SetFeatureBox(far, geometry.NewBox(20, 0, 20, 20))
+	if got := c.Plan.Features[far].Box.GetX(); got != 35 {
+		t.Errorf("x == %v; want the move into b refused", got)
+	}
+	c.SetFeatureBox(far, geometry.NewBox(90, 0, 20, 20))
+	if got := c.Plan.Features[far].Box.GetX(); got != 35 {
+		t.Errorf("x == %v; want the move off the plan refused", got)
+	}
+
+	// Beds that already overlap can still be moved apart.
+	c.SetFeatureBox(b, geometry.NewBox(12, 40, 20, 20))
+	if got := c.Plan.Features[b].Box.GetY(); got != 40 {
+		t.Errorf("y == %v; want the overlapping bed moved", got)
+	}
+}
+
+func TestFeatureIndex(t *testing.T) {
+	c := NewPlanController(models.NewPlan())
+	bed := c.AddFeature(models.Feature{Box: geometry.NewBox(0, 0, 20, 20), Properties: map[string]models.PropertyValue{}})
+	row := c.AddFeature(models.Feature{Box: geometry.NewBox(5, 5, 5, 5), Parent: bed, Properties: map[string]models.PropertyValue{}})
+	tree := c.AddFeature(models.Feature{Box: geometry.NewBox(100, 100, 10, 10), Properties: map[string]models.PropertyValue{}})
+
+	if got := c.FeaturesIn(geometry.NewBox(6, 6, 1, 1)); len(got) != 2 {
+		t.Errorf("FeaturesIn() == %v; want the bed and row", got)
+	}
+	if got := c.NearestFeature(geometry.NewVector(90, 90, 0)); got != tree {
+		t.Errorf("NearestFeature() == %v; want the tree", got)
+	}
+
+	// Moving the bed moves the row with it.
+	c.SetFeatureBox(bed, geometry.NewBox(200, 0, 20, 20))
+	if got := c.FeaturesIn(geometry.NewBox(6, 6, 1, 1)); len(got) != 0 {
+		t.Errorf("FeaturesIn() == %v after the move; want none", got)
+	}
+	if got := c.FeatureAt(geometry.NewVector(206, 6, 0)); got != row {
+		t.Errorf("FeatureAt() == %v; want the row", got)
+	}
+	if got := c.FeaturesNear(geometry.NewBox(115, 100, 0, 0), 5); len(got) != 1 || got[0] != tree {
+		t.Errorf("FeaturesNear() == %v; want the tree", got)
+	}
+
+	// Removed features leave the index, and come back on undo.
+	c.RemoveFeature(bed)
+	if got := c.FeaturesIn(geometry.NewBox(200, 0, 20, 20)); len(got) != 0 {
+		t.Errorf("FeaturesIn() == %v after removing; want none", got)
+	}
+	c.Undo()
+	if got := c.FeaturesIn(geometry.NewBox(200, 0, 20, 20)); len(got) != 2 {
+		t.Errorf("FeaturesIn() == %v after undo; want the bed and row", got)
+	}
+}
+
+func TestEdgeNear(t *testing.T) {
+	c := NewPlanController(models.NewPlan())
+	c.AddFeature(models.Feature{Box: geometry.NewBox(0, 0, 20, 20), Properties: map[string]models.PropertyValue{}})
+	c.AddFeature(models.Feature{Box: geometry.NewBox(30, 0, 20, 20), Properties: map[string]models.PropertyValue{}})
+
+	// Between the beds, the nearer one's edge wins.
+	edge, ok := c.EdgeNear(geometry.NewVector(22, 10, 0), 5)
+	if !ok || edge.A.X != 20 || edge.B.X != 20 {
+		t.Errorf("EdgeNear() == %v, %v; want the first bed's right edge", edge, ok)
+	}
+	if _, ok := c.EdgeNear(geometry.NewVector(10, 40, 0), 5); ok {
+		t.Errorf("EdgeNear() found an edge far from both beds")
+	}
+}
+
+func TestFeatureSnapper(t *testing.T) {
+	c := NewPlanController(models.NewPlan())
+	bed := c.AddFeature(models.Feature{Box: geometry.NewBox(0, 0, 20, 20), Properties: map[string]models.PropertyValue{}})
+	c.AddFeature(models.Feature{Box: geometry.NewBox(5, 5, 5, 5), Parent: bed, Properties: map[string]models.PropertyValue{}})
+	c.AddFeature(models.Feature{Box: geometry.NewBox(50, 0, 10, 10), Properties: map[string]models.PropertyValue{}})
+
+	// The bed lines up with the other bed, not with its own row.
+	s := NewFeatureSnapper(&c, bed, 0, 1)
+	if len(s.XLines) != 3 {
+		t.Fatalf("XLines == %v; want the other bed's edges and centre", s.XLines)
+	}
+	offset, _ := s.SnapMove(geometry.NewBox(30.5, 0, 20, 20))
+	if offset.X != -0.5 {
+		t.Errorf("SnapMove() offset == %v; want the bed's right edge on the other's left", offset.X)
+	}
+}
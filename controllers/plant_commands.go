@@ -0,0 +1,19 @@
+package controllers
+
+import "github.com/cpgillem/garden-planner/models"
+
+// Replaces the plant database. Plants are few and small, so every change
+// keeps a full copy from before and after.
+type setPlantsCommand struct {
+	c      *PlantController
+	before map[int]models.Plant
+	after  map[int]models.Plant
+}
+
+func (cmd *setPlantsCommand) Do() {
+	cmd.c.replace(cmd.after)
+}
+
+func (cmd *setPlantsCommand) Undo() {
+	cmd.c.replace(cmd.before)
+}
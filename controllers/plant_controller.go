@@ -1,36 +1,360 @@
 package controllers
 
-import "github.com/cpgillem/garden-planner/models"
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/cpgillem/garden-planner/models"
+)
 
 // Used to edit the collection of plant data used by the app for all plans.
+// Every change is saved through Store; if saving fails, the change is
+// dropped so memory and the plant database stay the same. Changes can be
+// undone.
 type PlantController struct {
-	plants map[int]models.Plant
+	plants  map[int]models.Plant
+	history *History
+
+	// Saves the whole plant database, sorted by ID.
+	Store func(plants []models.Plant) error
 
-	OnPlantAdded   func(models.Plant)
-	OnPlantRemoved func(models.Plant)
+	OnPlantAdded     func(models.Plant)
+	OnPlantUpdated   func(models.Plant)
+	OnPlantRemoved   func(models.Plant)
+	OnHistoryChanged func()
 }
 
 func NewPlantController(plants *[]models.Plant) PlantController {
 	c := PlantController{
-		plants:         map[int]models.Plant{},
-		OnPlantAdded:   func(p models.Plant) {},
-		OnPlantRemoved: func(p models.Plant) {},
+		plants:           map[int]models.Plant{},
+		history:          NewHistory(),
+		Store:            func(plants []models.Plant) error { return nil },
+		OnPlantAdded:     func(p models.Plant) {},
+		OnPlantUpdated:   func(p models.Plant) {},
+		OnPlantRemoved:   func(p models.Plant) {},
+		OnHistoryChanged: func() {},
 	}
 
 	// Add initial plants.
 	for _, p := range *plants {
-		c.plants[p.ID] = p
+		c.plants[p.ID] = copyPlant(p)
 	}
 
 	return c
 }
 
-func (c *PlantController) AddPlant(plant models.Plant) {
-	c.plants[plant.ID] = plant
-	c.OnPlantAdded(plant)
+// Adds a plant and returns it. A plant with ID NO_PLANT is given the next
+// free ID; any other ID must not be taken.
+func (c *PlantController) AddPlant(plant models.Plant) (models.Plant, error) {
+	if plant.ID == models.NO_PLANT {
+		plant.ID = c.NextID()
+	}
+	if plant.ID < 0 {
+		return plant, fmt.Errorf("plant ID %d is negative", plant.ID)
+	}
+	if existing, ok := c.plants[plant.ID]; ok {
+		return plant, fmt.Errorf("plant ID %d is already used by %s", plant.ID, existing.Name)
+	}
+
+	next := c.copyPlants()
+	next[plant.ID] = copyPlant(plant)
+	return plant, c.change(next)
+}
+
+// Replaces the plant with the same ID, e.g. to rename it.
+func (c *PlantController) UpdatePlant(plant models.Plant) error {
+	if !c.HasPlant(plant.ID) {
+		return fmt.Errorf("plant %d does not exist", plant.ID)
+	}
+
+	next := c.copyPlants()
+	next[plant.ID] = copyPlant(plant)
+	return c.change(next)
+}
+
+// Removes a plant, along with interactions other plants have with it.
+// Features that reference the plant keep the reference; see
+// PlanController.FeaturesWithPlant.
+func (c *PlantController) RemovePlant(id int) error {
+	if !c.HasPlant(id) {
+		return fmt.Errorf("plant %d does not exist", id)
+	}
+
+	next := c.copyPlants()
+	delete(next, id)
+	for otherID, other := range next {
+		next[otherID] = withInteraction(other, id, models.NEUTRAL)
+	}
+	return c.change(next)
+}
+
+// Sets how one plant is affected by another. If symmetric, the other plant
+// gets the same interaction back, in the same undo step.
+func (c *PlantController) SetInteraction(subjectID int, targetID int, interactionType uint16, symmetric bool) error {
+	subject, ok := c.plants[subjectID]
+	if !ok {
+		return fmt.Errorf("plant %d does not exist", subjectID)
+	}
+	target, ok := c.plants[targetID]
+	if !ok {
+		return fmt.Errorf("plant %d does not exist", targetID)
+	}
+	if subjectID == targetID {
+		return fmt.Errorf("%s can't interact with itself", subject.Name)
+	}
+	if interactionType > models.ANTAGONISTIC {
+		return fmt.Errorf("unknown interaction type %d", interactionType)
+	}
+
+	next := c.copyPlants()
+	next[subjectID] = withInteraction(subject, targetID, interactionType)
+	if symmetric {
+		next[targetID] = withInteraction(target, subjectID, interactionType)
+	}
+	return c.change(next)
+}
+
+func (c *PlantController) GetPlant(id int) (models.Plant, bool) {
+	plant, ok := c.plants[id]
+	return copyPlant(plant), ok
+}
+
+// Looks up a plant by name, ignoring case and surrounding spaces.
+func (c *PlantController) FindPlantByName(name string) (models.Plant, bool) {
+	name = strings.TrimSpace(name)
+	for _, p := range c.Plants() {
+		if strings.EqualFold(strings.TrimSpace(p.Name), name) {
+			return p, true
+		}
+	}
+	return models.Plant{}, false
+}
+
+func (c *PlantController) HasPlant(id int) bool {
+	_, ok := c.plants[id]
+	return ok
+}
+
+// Plants with an interaction targeting the given plant, sorted by name.
+// These interactions are removed along with the plant.
+func (c *PlantController) PlantsInteractingWith(id int) []models.Plant {
+	plants := []models.Plant{}
+	for _, p := range c.Plants() {
+		for _, interaction := range p.Interactions {
+			if interaction.TargetPlantID == id && p.ID != id {
+				plants = append(plants, p)
+				break
+			}
+		}
+	}
+	return plants
 }
 
-func (c *PlantController) RemovePlant(id int) {
-	// TODO
-	c.OnPlantRemoved(c.plants[id])
+// Whether two plants disagree about their interaction, e.g. A helps B but B
+// is neutral to A. Often a sign of a data entry mistake.
+func (c *PlantController) IsAsymmetric(a int, b int) bool {
+	plantA, okA := c.plants[a]
+	plantB, okB := c.plants[b]
+	if !okA || !okB || a == b {
+		return false
+	}
+	return plantA.InteractionWith(b) != plantB.InteractionWith(a)
+}
+
+// Distinct plant families in use, sorted. Plants without a family are left
+// out.
+func (c *PlantController) Families() []string {
+	seen := map[string]bool{}
+	families := []string{}
+	for _, p := range c.plants {
+		if p.Family != "" && !seen[p.Family] {
+			seen[p.Family] = true
+			families = append(families, p.Family)
+		}
+	}
+	sort.Strings(families)
+	return families
+}
+
+// The lowest ID above every ID in use.
+func (c *PlantController) NextID() int {
+	max := models.NO_PLANT
+	for id := range c.plants {
+		if id > max {
+			max = id
+		}
+	}
+	return max + 1
+}
+
+// All plants, sorted by name.
+func (c *PlantController) Plants() []models.Plant {
+	plants := []models.Plant{}
+	for _, p := range c.plants {
+		plants = append(plants, copyPlant(p))
+	}
+
+	sort.Slice(plants, func(i, j int) bool {
+		if plants[i].Name == plants[j].Name {
+			return plants[i].ID < plants[j].ID
+		}
+		return plants[i].Name < plants[j].Name
+	})
+
+	return plants
+}
+
+// History
+
+// Reverts the last change, saving the plant database as it was before.
+func (c *PlantController) Undo() error {
+	cmd, ok := c.history.nextUndo().(*setPlantsCommand)
+	if !ok {
+		return nil
+	}
+	if err := c.store(cmd.before); err != nil {
+		return err
+	}
+
+	c.history.Undo()
+	c.OnHistoryChanged()
+	return nil
+}
+
+func (c *PlantController) Redo() error {
+	cmd, ok := c.history.nextRedo().(*setPlantsCommand)
+	if !ok {
+		return nil
+	}
+	if err := c.store(cmd.after); err != nil {
+		return err
+	}
+
+	c.history.Redo()
+	c.OnHistoryChanged()
+	return nil
+}
+
+func (c *PlantController) CanUndo() bool {
+	return c.history.CanUndo()
+}
+
+func (c *PlantController) CanRedo() bool {
+	return c.history.CanRedo()
+}
+
+// Saves a new set of plants and, if that worked, applies it as one undo step.
+func (c *PlantController) change(next map[int]models.Plant) error {
+	if reflect.DeepEqual(next, c.plants) {
+		return nil
+	}
+	if err := c.store(next); err != nil {
+		return err
+	}
+
+	c.history.Execute(&setPlantsCommand{
+		c:      c,
+		before: c.plants,
+		after:  next,
+	})
+	c.OnHistoryChanged()
+	return nil
+}
+
+// Saves a set of plants through Store.
+func (c *PlantController) store(plants map[int]models.Plant) error {
+	sorted := []models.Plant{}
+	for _, p := range plants {
+		sorted = append(sorted, copyPlant(p))
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	if err := c.Store(sorted); err != nil {
+		return fmt.Errorf("could not save plants: %w", err)
+	}
+	return nil
+}
+
+// Swaps in a set of plants and fires events for whatever changed. Used by
+// commands; the set must already be saved.
+func (c *PlantController) replace(next map[int]models.Plant) {
+	previous := c.plants
+	c.plants = next
+
+	ids := []int{}
+	for id := range previous {
+		ids = append(ids, id)
+	}
+	for id := range next {
+		if _, ok := previous[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	// Updates first, so e.g. interactions with a removed plant are gone
+	// by the time it is reported as removed.
+	for _, id := range ids {
+		before, hadBefore := previous[id]
+		after, hasAfter := next[id]
+		if hadBefore && hasAfter && !reflect.DeepEqual(before, after) {
+			c.OnPlantUpdated(copyPlant(after))
+		}
+	}
+	for _, id := range ids {
+		if _, ok := previous[id]; !ok {
+			c.OnPlantAdded(copyPlant(next[id]))
+		}
+	}
+	for _, id := range ids {
+		if _, ok := next[id]; !ok {
+			c.OnPlantRemoved(copyPlant(previous[id]))
+		}
+	}
+}
+
+func (c *PlantController) copyPlants() map[int]models.Plant {
+	plants := map[int]models.Plant{}
+	for id, p := range c.plants {
+		plants[id] = copyPlant(p)
+	}
+	return plants
+}
+
+// Copies a plant so callers can't change stored interactions in place.
+func copyPlant(p models.Plant) models.Plant {
+	if p.Interactions != nil {
+		p.Interactions = append([]models.PlantInteraction{}, p.Interactions...)
+	}
+	return p
+}
+
+// Copy of a plant with its interaction with another plant changed. NEUTRAL
+// removes the interaction, since that is what no entry means.
+func withInteraction(p models.Plant, targetID int, interactionType uint16) models.Plant {
+	interactions := []models.PlantInteraction{}
+	found := false
+	for _, interaction := range p.Interactions {
+		if interaction.TargetPlantID != targetID {
+			interactions = append(interactions, interaction)
+			continue
+		}
+		if !found && interactionType != models.NEUTRAL {
+			interactions = append(interactions, models.PlantInteraction{TargetPlantID: targetID, InteractionType: interactionType})
+		}
+		found = true
+	}
+	if !found && interactionType != models.NEUTRAL {
+		interactions = append(interactions, models.PlantInteraction{TargetPlantID: targetID, InteractionType: interactionType})
+	}
+
+	if !found && interactionType == models.NEUTRAL {
+		return p
+	}
+	p.Interactions = interactions
+	return p
 }
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cpgillem/garden-planner/models"
+)
+
+func newTestPlantController() PlantController {
+	return NewPlantController(&[]models.Plant{
+		{ID: 1, Name: "Tomato", Interactions: []models.PlantInteraction{{TargetPlantID: 2, InteractionType: models.BENEFICIAL}}},
+		{ID: 2, Name: "Basil"},
+	})
+}
+
+func TestAddPlant(t *testing.T) {
+	c := newTestPlantController()
+
+	plant, err := c.AddPlant(models.Plant{Name: "Bean"})
+	if err != nil {
+		t.Fatalf("AddPlant() error == %v", err)
+	}
+	if plant.ID != 3 {
+		t.Errorf("new plant ID == %d; want 3", plant.ID)
+	}
+
+	if _, err := c.AddPlant(models.Plant{ID: 2, Name: "Other Basil"}); err == nil {
+		t.Errorf("AddPlant() with a used ID succeeded; want error")
+	}
+
+	if p, ok := c.FindPlantByName(" bean "); !ok || p.ID != 3 {
+		t.Errorf("FindPlantByName(\" bean \") == %v, %t; want plant 3", p, ok)
+	}
+}
+
+func TestRemovePlantRemovesInteractions(t *testing.T) {
+	c := newTestPlantController()
+
+	if err := c.RemovePlant(2); err != nil {
+		t.Fatalf("RemovePlant() error == %v", err)
+	}
+	if c.HasPlant(2) {
+		t.Errorf("plant 2 still exists after removal")
+	}
+	if tomato, _ := c.GetPlant(1); len(tomato.Interactions) != 0 {
+		t.Errorf("tomato still has %d interactions with a removed plant", len(tomato.Interactions))
+	}
+}
+
+func TestFailedStoreKeepsPlants(t *testing.T) {
+	c := newTestPlantController()
+	c.Store = func(plants []models.Plant) error {
+		return errors.New("disk full")
+	}
+
+	if err := c.RemovePlant(2); err == nil {
+		t.Fatalf("RemovePlant() succeeded although saving failed")
+	}
+	if !c.HasPlant(2) {
+		t.Errorf("plant was removed although saving failed")
+	}
+	if tomato, _ := c.GetPlant(1); len(tomato.Interactions) != 1 {
+		t.Errorf("interactions changed although saving failed")
+	}
+}
+
+func TestSetInteractionSymmetricUndo(t *testing.T) {
+	c := newTestPlantController()
+
+	if err := c.SetInteraction(2, 1, models.ANTAGONISTIC, true); err != nil {
+		t.Fatalf("SetInteraction() error == %v", err)
+	}
+	basil, _ := c.GetPlant(2)
+	tomato, _ := c.GetPlant(1)
+	if basil.InteractionWith(1) != models.ANTAGONISTIC || tomato.InteractionWith(2) != models.ANTAGONISTIC {
+		t.Errorf("symmetric interaction not set on both plants")
+	}
+
+	// Both directions are undone together.
+	if err := c.Undo(); err != nil {
+		t.Fatalf("Undo() error == %v", err)
+	}
+	basil, _ = c.GetPlant(2)
+	tomato, _ = c.GetPlant(1)
+	if basil.InteractionWith(1) != models.NEUTRAL || tomato.InteractionWith(2) != models.BENEFICIAL {
+		t.Errorf("undo did not restore both interactions")
+	}
+}
+
+func TestIsAsymmetric(t *testing.T) {
+	c := newTestPlantController()
+
+	// Tomato helps basil, but basil is neutral to tomato.
+	if !c.IsAsymmetric(1, 2) || !c.IsAsymmetric(2, 1) {
+		t.Errorf("IsAsymmetric(tomato, basil) == false; want true")
+	}
+
+	c.SetInteraction(2, 1, models.BENEFICIAL, false)
+	if c.IsAsymmetric(1, 2) {
+		t.Errorf("IsAsymmetric(tomato, basil) == true after matching interactions; want false")
+	}
+}
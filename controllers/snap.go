@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"github.com/cpgillem/garden-planner/geometry"
+	"github.com/cpgillem/garden-planner/models"
+)
+
+// Snaps a feature being moved or resized to the grid, the edges of the plan,
+// and the edges and centres of other features. Features nested in it move
+// with it, so they are left out. Grid and tolerance are in base units.
+func NewFeatureSnapper(plan *PlanController, id models.FeatureID, grid float32, tolerance float32) geometry.Snapper {
+	s := geometry.NewSnapper(grid, tolerance)
+	if !plan.Plan.Box.IsEmpty() {
+		s.AddBox(plan.Plan.Box)
+	}
+
+	moving := map[models.FeatureID]bool{id: true}
+	for _, d := range plan.Plan.Descendants(id) {
+		moving[d] = true
+	}
+	for other, f := range plan.Plan.Features {
+		if f != nil && !moving[other] {
+			s.AddBox(plan.Plan.Shape(other).Bounds())
+		}
+	}
+	return s
+}
@@ -5,47 +5,75 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/cpgillem/garden-planner/validation"
 )
 
-// Reads a plan from a reader.
-func ReadObject[T any](r io.ReadCloser) (*T, error) {
+// Reads an object from a reader. Problems with the content are added to the
+// report; an error is returned only if the object couldn't be decoded.
+func ReadObject[T any](r io.ReadCloser, report *validation.Report) (*T, error) {
 	defer r.Close()
 
 	// Read bytes.
 	content, err := io.ReadAll(r)
 	if err != nil {
-		fmt.Println("Could not read data.\n" + err.Error())
+		report.Errorf(validation.Root(), "could not read data: %s", err.Error())
 		return nil, err
 	}
 
 	// Decode from JSON.
-	return DecodeObject[T](content)
+	return DecodeObject[T](content, report)
+}
+
+// Implemented by types whose file format is versioned. Migrate upgrades raw
+// content to the current format before it is decoded.
+type Migratable interface {
+	Migrate(content []byte) ([]byte, error)
 }
 
-// Load an object from a byte slice.
-func DecodeObject[T any](content []byte) (*T, error) {
+// Load an object from a byte slice, checking it against the object's schema.
+func DecodeObject[T any](content []byte, report *validation.Report) (*T, error) {
 	var o T
+
+	// Upgrade older file formats first.
+	if m, ok := any(&o).(Migratable); ok {
+		migrated, err := m.Migrate(content)
+		if err != nil {
+			report.Errorf(validation.Root(), "could not upgrade file: %s", err.Error())
+			return nil, err
+		}
+		content = migrated
+	}
+
+	// Report every problem with the structure, not just the first.
+	reported := report.Count(validation.ERROR)
+	validation.CheckSchema(content, reflect.TypeOf(o), report)
+
 	err := json.Unmarshal(content, &o)
 	if err != nil {
-		fmt.Println("Could not parse JSON.\n" + err.Error())
-		return nil, err
+		// The schema check has usually reported why already.
+		if report.Count(validation.ERROR) == reported {
+			report.Errorf(validation.Root(), "could not parse JSON: %s", err.Error())
+		}
+		return nil, fmt.Errorf("could not parse JSON: %w", err)
 	}
 
 	return &o, nil
 }
 
-// Encodes a plan to JSON.
+// Encodes an object to JSON, indented like the hand-written data files.
 func EncodeObject[T any](o *T) ([]byte, error) {
-	content, err := json.Marshal(o)
+	content, err := json.MarshalIndent(o, "", "    ")
 	if err != nil {
-		fmt.Println("Could not encode to JSON.\n" + err.Error())
-		return nil, err
+		return nil, fmt.Errorf("could not encode to JSON: %w", err)
 	}
 
 	return content, nil
 }
 
-// Writes a plan to a writer.
+// Writes an object to a writer.
 func WriteObject[T any](w io.WriteCloser, o T) error {
 	defer w.Close()
 
@@ -55,38 +83,67 @@ func WriteObject[T any](w io.WriteCloser, o T) error {
 		return err
 	}
 
-	// Write plan.
+	// Write object.
 	_, err = w.Write(content)
 	if err != nil {
-		fmt.Println("Could not write object.\n" + err.Error())
+		return fmt.Errorf("could not write object: %w", err)
 	}
 
 	return nil
 }
 
-// Save a garden plan or create a new one.
+// Save an object to a file, creating it if needed. The object is written to
+// a temporary file first and moved into place, so the file is never left
+// half written.
 func WriteObjectToFile[T any](o *T, path string) error {
-	// Create/truncate file.
-	f, err := os.Create(path)
+	content, err := EncodeObject(o)
 	if err != nil {
-		fmt.Println("Could not open file to save to.\nPath: " + path + "\n" + err.Error())
 		return err
 	}
-	defer f.Close()
 
-	// Write to file.
-	return WriteObject(f, o)
+	// Create the temporary file next to the target so renaming is atomic.
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("could not open %s to save to: %w", path, err)
+	}
+	defer os.Remove(f.Name())
+
+	// Write to file. Temporary files are private by default.
+	if err := f.Chmod(0644); err != nil {
+		f.Close()
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("could not write %s: %w", path, err)
+	}
+
+	// Replace the old file.
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("could not save %s: %w", path, err)
+	}
+
+	return nil
 }
 
-// Open a file as an object.
-func ReadObjectFromFile[T any](path string) (*T, error) {
+// Open a file as an object. Problems are added to the report under the path.
+func ReadObjectFromFile[T any](path string, report *validation.Report) (*T, error) {
+	report = report.In(path)
+
 	// Open file for reading.
 	f, err := os.Open(path)
 	if err != nil {
-		fmt.Println("Could not load from " + path + "\n" + err.Error())
+		report.Errorf(validation.Root(), "could not open file: %s", err.Error())
 		return nil, err
 	}
 	defer f.Close()
 
-	return ReadObject[T](f)
+	return ReadObject[T](f, report)
 }
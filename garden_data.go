@@ -1,14 +1,16 @@
 package main
 
 import (
-	"fmt"
-
 	"github.com/cpgillem/garden-planner/models"
+	"github.com/cpgillem/garden-planner/validation"
 )
 
 type GardenData struct {
 	Properties       map[string]models.Property
 	FeatureTemplates map[string]models.FeatureTemplate
+
+	// Problems found while loading the data files.
+	Report *validation.Report
 }
 
 // Loads garden data from the files in the /data directory.
@@ -17,28 +19,31 @@ func NewGardenData() *GardenData {
 	gardenData := GardenData{
 		Properties:       map[string]models.Property{},
 		FeatureTemplates: map[string]models.FeatureTemplate{},
+		Report:           validation.NewReport(),
 	}
 
 	// Load properties of any landscape feature.
-	properties, err := ReadObjectFromFile[[]models.Property]("data/properties.json")
-	if err != nil {
-		fmt.Println("Could not load properties.")
-	}
-
-	// Map properties for easy retrieval.
-	for _, p := range *properties {
-		gardenData.Properties[p.Name] = p
+	propertiesPath := "data/properties.json"
+	properties, err := ReadObjectFromFile[[]models.Property](propertiesPath, gardenData.Report)
+	if err == nil {
+		models.ValidateProperties(*properties, gardenData.Report.In(propertiesPath))
+
+		// Map properties for easy retrieval.
+		for _, p := range *properties {
+			gardenData.Properties[p.Name] = p
+		}
 	}
 
 	// Load templates for landscaping features.
-	featureTemplates, err := ReadObjectFromFile[[]models.FeatureTemplate]("data/feature_templates.json")
-	if err != nil {
-		fmt.Println("Could not load feature templates.")
-	}
-
-	// Map feature templates to names.
-	for _, ft := range *featureTemplates {
-		gardenData.FeatureTemplates[ft.Name] = ft
+	templatesPath := "data/feature_templates.json"
+	featureTemplates, err := ReadObjectFromFile[[]models.FeatureTemplate](templatesPath, gardenData.Report)
+	if err == nil {
+		models.ValidateFeatureTemplates(*featureTemplates, gardenData.Properties, gardenData.Report.In(templatesPath))
+
+		// Map feature templates to names.
+		for _, ft := range *featureTemplates {
+			gardenData.FeatureTemplates[ft.Name] = ft
+		}
 	}
 
 	return &gardenData
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
@@ -16,23 +22,38 @@ import (
 	"github.com/cpgillem/garden-planner/geometry"
 	"github.com/cpgillem/garden-planner/models"
 	"github.com/cpgillem/garden-planner/ui"
+	"github.com/cpgillem/garden-planner/validation"
 )
 
 const IMPERIAL string = "imperial"
 const METRIC string = "metric"
 
+// How close features must be for companion hints, unless set in settings.
+const DEFAULT_COMPANION_DISTANCE string = "24 in"
+
+// Narrowest path allowed between features, unless set in settings. 0 lets
+// features touch.
+const DEFAULT_PATH_WIDTH string = "0 in"
+
 // Represents the state of the application.
 type GardenPlanner struct {
 	App fyne.App
 
 	// Windows
-	Window         fyne.Window
-	SettingsWindow SettingsWindow
+	Window            fyne.Window
+	SettingsWindow    SettingsWindow
+	PlantEditor       PlantEditor
+	SolverWindow      SolverWindow
+	IrrigationWindow  IrrigationWindow
+	WaterBudgetWindow WaterBudgetWindow
 
 	// Containers
 	MainContainer *fyne.Container
 	Sidebar       *fyne.Container
 	GardenWidget  *ui.GardenWidget
+	FeatureTree   *ui.FeatureTree
+	TopRuler      *ui.Ruler
+	SideRuler     *ui.Ruler
 
 	// Controllers
 	PlanController  controllers.PlanController
@@ -42,12 +63,21 @@ type GardenPlanner struct {
 	Toolbar       *widget.Toolbar
 	StatusBar     *widget.Label
 	PropertyTable *fyne.Container
+	NeighbourList *fyne.Container
+	ProblemList   *fyne.Container
 	FeatureTools  *fyne.Container
 	BoxEditor     *ui.BoxEditor
 
 	// Button References
 	DeleteFeature    *widget.Button
 	TemplateSelector *widget.Select
+	UndoButton       *ui.ToolbarAction
+	RedoButton       *ui.ToolbarAction
+
+	// Parts of the status bar: where the pointer is, and the measuring
+	// tool's result.
+	pointerStatus string
+	measureStatus string
 
 	// Data
 	GardenData    *GardenData
@@ -67,12 +97,22 @@ func NewGardenPlanner() *GardenPlanner {
 	gardenData := NewGardenData()
 
 	// Load plant data.
-	plants, err := ReadObjectFromFile[[]models.Plant]("data/plants.json")
+	plantsPath := "data/plants.json"
+	plants, err := ReadObjectFromFile[[]models.Plant](plantsPath, gardenData.Report)
 	if err != nil {
-		log.Fatal("Could not load plant data.")
 		plants = &[]models.Plant{}
 	}
+	models.ValidatePlants(*plants, gardenData.Report.In(plantsPath))
 	plantController := controllers.NewPlantController(plants)
+	plantController.Store = func(plants []models.Plant) error {
+		return WriteObjectToFile(&plants, plantsPath)
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		// Don't replace a database that exists but couldn't be read.
+		plantController.Store = func(plants []models.Plant) error {
+			return fmt.Errorf("%s could not be read, so changes won't be saved", plantsPath)
+		}
+	}
 
 	displayConfig := models.NewDisplayConfig()
 	formatter := ui.NewFormatter()
@@ -93,8 +133,32 @@ func NewGardenPlanner() *GardenPlanner {
 	)
 	toolbar := widget.NewToolbar()
 	statusBar := widget.NewLabel("")
-	mainContainer := container.NewBorder(toolbar, nil, sidebar, nil, gardenWidget)
+	// The garden draws wherever the view puts the plan, so a scroll
+	// container clips it to its own area.
+	gardenScroll := container.NewScroll(gardenWidget)
+	gardenScroll.SetMinSize(gardenWidget.MinSize())
+
+	// Rulers run along the top and left of the garden, following its view.
+	topRuler := ui.NewRuler(false, formatter)
+	sideRuler := ui.NewRuler(true, formatter)
+	gardenWidget.OnViewChanged = func(view ui.Viewport) {
+		topRuler.SetView(view)
+		sideRuler.SetView(view)
+	}
+	rulerCorner := canvas.NewRectangle(theme.BackgroundColor())
+	rulerCorner.SetMinSize(fyne.NewSquareSize(ui.RULER_SIZE))
+	gardenArea := container.NewBorder(
+		container.NewBorder(nil, nil, rulerCorner, nil, topRuler),
+		nil,
+		sideRuler,
+		nil,
+		gardenScroll,
+	)
+	mainContainer := container.NewBorder(toolbar, statusBar, sidebar, nil, gardenArea)
 	propertyTable := container.New(layout.NewFormLayout())
+	neighbourList := container.NewVBox()
+	problemList := container.NewVBox()
+	featureTree := ui.NewFeatureTree(&planController)
 	featureTools := container.NewHBox()
 	boxEditor := ui.NewBoxEditor(geometry.NewBoxZero(), ui.AnyUnit, formatter)
 
@@ -110,8 +174,13 @@ func NewGardenPlanner() *GardenPlanner {
 		Toolbar:         toolbar,
 		StatusBar:       statusBar,
 		GardenWidget:    gardenWidget,
+		TopRuler:        topRuler,
+		SideRuler:       sideRuler,
+		FeatureTree:     featureTree,
 		FeatureTools:    featureTools,
 		PropertyTable:   propertyTable,
+		NeighbourList:   neighbourList,
+		ProblemList:     problemList,
 		GardenData:      gardenData,
 		Formatter:       formatter,
 		PlanController:  planController,
@@ -119,25 +188,79 @@ func NewGardenPlanner() *GardenPlanner {
 		DisplayConfig:   &displayConfig,
 	}
 
+	// Plant changes can affect features in the plan.
+	gardenPlanner.PlantController.OnPlantAdded = gardenPlanner.PlantAdded
+	gardenPlanner.PlantController.OnPlantUpdated = gardenPlanner.PlantUpdated
+	gardenPlanner.PlantController.OnPlantRemoved = gardenPlanner.PlantRemoved
+
 	// Setup Toolbar
 	gardenPlanner.SetupToolbar()
 	gardenPlanner.SetupFeatureTools()
+	gardenPlanner.SetupShortcuts()
 
 	// Other windows
 	gardenPlanner.SettingsWindow = NewSettingsWindow(&gardenPlanner)
+	gardenPlanner.PlantEditor = NewPlantEditor(&gardenPlanner)
+	gardenPlanner.PlantEditor.Setup()
+	gardenPlanner.SolverWindow = NewSolverWindow(&gardenPlanner)
+	gardenPlanner.SolverWindow.Setup()
+	gardenPlanner.IrrigationWindow = NewIrrigationWindow(&gardenPlanner)
+	gardenPlanner.IrrigationWindow.Setup()
+	gardenPlanner.WaterBudgetWindow = NewWaterBudgetWindow(&gardenPlanner)
+	gardenPlanner.WaterBudgetWindow.Setup()
+
+	// Companion hints need plant data.
+	gardenPlanner.GardenWidget.Plants = &gardenPlanner.PlantController
+
+	// The status bar follows the pointer and the measuring tool.
+	gardenPlanner.GardenWidget.OnPointerMoved = gardenPlanner.PointerMoved
+	gardenPlanner.GardenWidget.OnPointerLeft = gardenPlanner.PointerLeft
+	gardenPlanner.GardenWidget.OnMeasured = gardenPlanner.Measured
 
 	mainApp.Preferences().AddChangeListener(gardenPlanner.RereadSettings)
+	gardenPlanner.RereadSettings()
 
 	return &gardenPlanner
 }
 
 // After settings are changed, make the appropriate updates.
 func (p *GardenPlanner) RereadSettings() {
+	// Lengths read off the plan follow the measurement system.
+	if p.App.Preferences().StringWithFallback("measurement_system", IMPERIAL) == METRIC {
+		p.Formatter.SetDisplayUnit(units.Meter)
+	} else {
+		p.Formatter.SetDisplayUnit(units.Foot)
+	}
+	p.TopRuler.Refresh()
+	p.SideRuler.Refresh()
+
 	spacing := p.App.Preferences().StringWithFallback("grid_spacing", "12 in")
 	spacingUnit, err := p.Formatter.ToDimensionBaseUnit(spacing, p.DisplayConfig.BaseUnit)
 	if err == nil {
 		p.GardenWidget.SetGridSpacing(float32(spacingUnit.Float()))
 	}
+	p.GardenWidget.SetSnapping(
+		p.App.Preferences().BoolWithFallback("snap_to_grid", true),
+		p.App.Preferences().BoolWithFallback("snap_to_features", true),
+	)
+
+	distance := p.App.Preferences().StringWithFallback("companion_distance", DEFAULT_COMPANION_DISTANCE)
+	distanceUnit, err := p.Formatter.ToDimensionBaseUnit(distance, p.DisplayConfig.BaseUnit)
+	if err == nil {
+		p.GardenWidget.SetCompanionDistance(float32(distanceUnit.Float()))
+		p.RefreshNeighbours()
+	}
+
+	width := p.App.Preferences().StringWithFallback("path_width", DEFAULT_PATH_WIDTH)
+	widthUnit, err := p.Formatter.ToDimensionBaseUnit(width, p.DisplayConfig.BaseUnit)
+	if err == nil {
+		p.GardenWidget.SetPathWidth(float32(widthUnit.Float()))
+	}
+	p.PlanController.PreventCollisions = p.App.Preferences().Bool("prevent_overlap")
+	p.RefreshProblems()
+
+	// Volumes follow the measurement system.
+	p.WaterBudgetWindow.Refresh()
 }
 
 func (p *GardenPlanner) Start() {
@@ -151,6 +274,10 @@ func (instance *GardenPlanner) FeatureSelected(id models.FeatureID) {
 
 func (instance *GardenPlanner) FeatureAdded(id models.FeatureID) {
 	instance.GardenWidget.AddFeature(id)
+	instance.GardenWidget.Recalculate()
+	instance.FeatureTree.Refresh()
+	instance.RefreshIrrigation()
+	instance.refreshPlantWarning(id)
 	instance.SelectFeature(id)
 }
 
@@ -158,16 +285,63 @@ func (instance *GardenPlanner) FeatureRemoved(id models.FeatureID) {
 	if instance.PlanController.GetSelectedFeature() == id {
 		instance.PropertyTable.RemoveAll()
 		instance.DeleteFeature.Disable()
+		instance.FeatureTree.UnselectAll()
 	}
 	instance.GardenWidget.RemoveFeature(id)
+	instance.RefreshNeighbours()
+	instance.RefreshProblems()
+	instance.FeatureTree.Refresh()
+	instance.RefreshIrrigation()
 }
 
-func (instance *GardenPlanner) FeatureDragEnd(id models.FeatureID) {
-	instance.BoxEditor.SetBox(instance.PlanController.Plan.Features[id].Box)
-	instance.Sidebar.Refresh()
+func (instance *GardenPlanner) FeatureChanged(id models.FeatureID) {
+	instance.refreshPlantWarning(id)
+
+	// The feature widget redraws the garden as it is dragged, and the
+	// garden is reanalyzed once the gesture is over.
+	if instance.PlanController.InGesture() {
+		return
+	}
+	instance.GardenWidget.Recalculate()
+
+	// Names and nesting show in the tree.
+	instance.FeatureTree.Refresh()
+	instance.RefreshNeighbours()
+	instance.RefreshProblems()
+	instance.RefreshIrrigation()
+
+	// Rebuild the property panel once a gesture is over, e.g. after an undo.
+	if id == instance.PlanController.GetSelectedFeature() {
+		instance.SelectFeature(id)
+	}
+}
+
+func (instance *GardenPlanner) IrrigationChanged() {
+	instance.GardenWidget.CalculateIrrigation()
+	instance.GardenWidget.Refresh()
+	instance.RefreshIrrigation()
 }
 
-func (instance *GardenPlanner) FeatureHandleDragEnd(id models.FeatureID, edge geometry.BoxEdge) {
+// Updates the irrigation designer and water budget after the plan changed.
+func (instance *GardenPlanner) RefreshIrrigation() {
+	instance.IrrigationWindow.Refresh()
+	instance.WaterBudgetWindow.Refresh()
+}
+
+// Enables undo and redo only when there is a step to take. Both are disabled
+// during a gesture.
+func (instance *GardenPlanner) RefreshHistory() {
+	instance.UndoButton.SetEnabled(instance.PlanController.CanUndo())
+	instance.RedoButton.SetEnabled(instance.PlanController.CanRedo())
+}
+
+// Updates the panels once a feature has been moved, resized or turned by
+// dragging it or one of its handles.
+func (instance *GardenPlanner) FeatureDragEnd(id models.FeatureID) {
+	instance.BoxEditor.SetBox(instance.PlanController.Plan.Features[id].Box)
+	instance.BoxEditor.SetRotation(instance.PlanController.Plan.Features[id].Rotation)
+	instance.RefreshNeighbours()
+	instance.RefreshProblems()
 	instance.Sidebar.Refresh()
 }
 
@@ -178,7 +352,10 @@ func (instance *GardenPlanner) OpenPlan(plan *models.Plan) {
 
 	instance.Sidebar.Add(instance.FeatureTools)
 	// instance.Sidebar.Add(instance.FeatureList)
+	instance.Sidebar.Add(container.New(layout.NewGridWrapLayout(fyne.NewSize(250, 200)), instance.FeatureTree))
 	instance.Sidebar.Add(instance.PropertyTable)
+	instance.Sidebar.Add(instance.NeighbourList)
+	instance.Sidebar.Add(instance.ProblemList)
 
 	// TODO: Make displayconfig loadable from a file.
 
@@ -187,11 +364,31 @@ func (instance *GardenPlanner) OpenPlan(plan *models.Plan) {
 	instance.PlanController.OnFeatureSelected = instance.FeatureSelected
 	instance.PlanController.OnFeatureAdded = instance.FeatureAdded
 	instance.PlanController.OnFeatureRemoved = instance.FeatureRemoved
+	instance.PlanController.OnFeatureChanged = instance.FeatureChanged
+	instance.PlanController.OnIrrigationChanged = instance.IrrigationChanged
+	instance.PlanController.OnHistoryChanged = instance.RefreshHistory
+	instance.RefreshHistory()
+	instance.PlanController.PreventCollisions = instance.App.Preferences().Bool("prevent_overlap")
 
 	// Setup garden viewer widget.
 	instance.GardenWidget.OpenPlan(&instance.PlanController)
 	instance.GardenWidget.OnFeatureDragEnd = instance.FeatureDragEnd
-	instance.GardenWidget.OnFeatureHandleDragEnd = instance.FeatureHandleDragEnd
+	instance.GardenWidget.OnFeatureHandleDragEnd = func(id models.FeatureID, edge geometry.BoxEdge) {
+		instance.FeatureDragEnd(id)
+	}
+	instance.GardenWidget.OnFeatureCornerDragEnd = func(id models.FeatureID, corner geometry.BoxCorner) {
+		instance.FeatureDragEnd(id)
+	}
+
+	// Setup feature tree.
+	instance.FeatureTree.Controller = &instance.PlanController
+	instance.FeatureTree.OnReparentError = func(err error) {
+		dialog.ShowError(err, instance.Window)
+	}
+	instance.FeatureTree.Refresh()
+	instance.RefreshPlantWarnings()
+	instance.RefreshProblems()
+	instance.RefreshIrrigation()
 
 	// Enable necessary feature buttons.
 	if instance.PlanController.HasSelection() {
@@ -200,18 +397,91 @@ func (instance *GardenPlanner) OpenPlan(plan *models.Plan) {
 	instance.TemplateSelector.Enable()
 }
 
+// Validates a plan that was read from a file, opens it, and shows any problems.
+func (instance *GardenPlanner) LoadPlan(plan *models.Plan, report *validation.Report) {
+	models.ValidatePlan(plan, instance.GardenData.Properties, instance.GardenData.FeatureTemplates, report)
+	models.ValidatePlantReferences(plan, instance.PlantController.Plants(), report)
+	models.NormalizePropertyValues(plan, instance.GardenData.Properties)
+	models.NormalizeParents(plan)
+	instance.OpenPlan(plan)
+	ShowLoadReport("Plan Problems", report, instance.Window)
+}
+
 // Updates the GUI when a feature is selected.
 func (instance *GardenPlanner) SelectFeature(id models.FeatureID) {
 	instance.PropertyTable.RemoveAll()
 	instance.AddFeatureProperties(id)
 
 	instance.GardenWidget.SelectFeature(id)
+	instance.FeatureTree.SelectFeature(id)
 
 	// Enable feature editing buttons
 	instance.DeleteFeature.Enable()
 
 	instance.PropertyTable.Refresh()
 	instance.GardenWidget.Refresh()
+	instance.RefreshNeighbours()
+}
+
+// Lists the beneficial and antagonistic neighbours of the selected feature.
+func (instance *GardenPlanner) RefreshNeighbours() {
+	instance.NeighbourList.RemoveAll()
+	id := instance.PlanController.GetSelectedFeature()
+	if !instance.PlanController.HasSelection() {
+		return
+	}
+
+	companions := controllers.CompanionsOf(id, instance.GardenWidget.Companions())
+	if len(companions) == 0 {
+		return
+	}
+
+	// Beneficial neighbours first, then by name.
+	features := instance.PlanController.Plan.Features
+	sort.Slice(companions, func(i, j int) bool {
+		if companions[i].InteractionType != companions[j].InteractionType {
+			return companions[i].InteractionType < companions[j].InteractionType
+		}
+		return features[companions[i].B].Name < features[companions[j].B].Name
+	})
+
+	instance.NeighbourList.Add(widget.NewLabelWithStyle("Neighbours", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	for _, c := range companions {
+		plant, _ := instance.PlantController.GetPlant(c.PlantB)
+		label := widget.NewLabel(fmt.Sprintf(
+			"%s: %s (%s)",
+			models.InteractionTypeName(c.InteractionType),
+			features[c.B].Name,
+			plant.Name,
+		))
+		label.Importance = widget.SuccessImportance
+		if c.InteractionType == models.ANTAGONISTIC {
+			label.Importance = widget.DangerImportance
+		}
+		instance.NeighbourList.Add(label)
+	}
+}
+
+// Lists overlapping features, features outside the plan, and paths that
+// are too narrow. Tapping a problem selects the feature.
+func (instance *GardenPlanner) RefreshProblems() {
+	instance.ProblemList.RemoveAll()
+	plan := instance.PlanController.Plan
+	collisions := instance.GardenWidget.Collisions()
+	if len(collisions) == 0 {
+		return
+	}
+
+	instance.ProblemList.Add(widget.NewLabelWithStyle("Problems", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	for _, c := range collisions {
+		id := c.A
+		button := widget.NewButton(c.Describe(plan), func() {
+			instance.PlanController.SelectFeature(id)
+		})
+		button.Importance = widget.DangerImportance
+		button.Alignment = widget.ButtonAlignLeading
+		instance.ProblemList.Add(button)
+	}
 }
 
 func (instance *GardenPlanner) ClearFeatureProperties() {
@@ -225,8 +495,11 @@ func (instance *GardenPlanner) AddFeatureProperties(id models.FeatureID) {
 	boxLabel := widget.NewLabel("Box")
 	boxEditor := ui.NewBoxEditor(feature.Box, units.Inch, instance.Formatter)
 	boxEditor.OnSubmitted = func(newBox geometry.Box) {
-		feature.Box = newBox
-		instance.GardenWidget.Refresh()
+		instance.PlanController.SetFeatureBox(id, newBox)
+	}
+	boxEditor.SetRotation(feature.Rotation)
+	boxEditor.OnRotationSubmitted = func(degrees float32) {
+		instance.PlanController.SetFeatureRotation(id, degrees)
 	}
 	instance.BoxEditor = boxEditor
 
@@ -235,19 +508,19 @@ func (instance *GardenPlanner) AddFeatureProperties(id models.FeatureID) {
 	nameEntry.MultiLine = false
 	nameEntry.SetText(feature.Name)
 	nameEntry.OnSubmitted = func(s string) {
-		feature.Name = s
-		instance.GardenWidget.Refresh()
+		instance.PlanController.SetFeatureName(id, s)
 	}
 
 	instance.PropertyTable.Add(nameLabel)
 	instance.PropertyTable.Add(nameEntry)
 	instance.PropertyTable.Add(boxLabel)
 	instance.PropertyTable.Add(boxEditor)
+	instance.addShapeProperties(id)
 
 	// Custom properties on feature.
 	for propertyName := range feature.Properties {
 		label := widget.NewLabel(instance.GardenData.Properties[propertyName].DisplayName)
-		entry, err := instance.CreatePropertyWidget(instance.GardenData.Properties[propertyName], feature)
+		entry, err := instance.CreatePropertyWidget(instance.GardenData.Properties[propertyName], id)
 		if err != nil {
 			// Don't add anything if the property can't be read.
 			fmt.Printf("Warning: %s\n", err.Error())
@@ -258,64 +531,139 @@ func (instance *GardenPlanner) AddFeatureProperties(id models.FeatureID) {
 	}
 }
 
+// Adds widgets for choosing the outline of a feature and, for arcs, its
+// angles and thickness.
+func (instance *GardenPlanner) addShapeProperties(id models.FeatureID) {
+	shape := instance.PlanController.Plan.Features[id].Shape
+
+	shapeSelect := widget.NewSelect(models.ShapeKinds, nil)
+	shapeSelect.SetSelected(shape.GetKind())
+	shapeSelect.OnChanged = func(kind string) {
+		if kind != shape.GetKind() {
+			instance.PlanController.SetFeatureShape(id, models.NewFeatureShape(kind))
+		}
+	}
+	instance.PropertyTable.Add(widget.NewLabel("Shape"))
+	instance.PropertyTable.Add(shapeSelect)
+
+	if shape.GetKind() != models.SHAPE_ARC {
+		return
+	}
+
+	// Each entry changes one parameter of a copy of the arc.
+	arcEntry := func(label string, value float32, apply func(s *models.FeatureShape, v float32)) {
+		entry := widget.NewEntry()
+		entry.SetText(strconv.FormatFloat(float64(value), 'f', -1, 32))
+		entry.OnSubmitted = func(text string) {
+			v, err := strconv.ParseFloat(text, 32)
+			if err == nil {
+				changed := shape.Copy()
+				apply(changed, float32(v))
+				err = instance.PlanController.SetFeatureShape(id, changed)
+			}
+			if err != nil {
+				dialog.ShowError(err, instance.Window)
+			}
+		}
+		instance.PropertyTable.Add(widget.NewLabel(label))
+		instance.PropertyTable.Add(entry)
+	}
+	arcEntry("Arc Start (°)", shape.Start, func(s *models.FeatureShape, v float32) { s.Start = v })
+	arcEntry("Arc Sweep (°)", shape.Sweep, func(s *models.FeatureShape, v float32) { s.Sweep = v })
+	arcEntry("Arc Thickness", shape.Thickness, func(s *models.FeatureShape, v float32) { s.Thickness = v })
+}
+
 // Creates a widget for modifying a property on a feature.
-func (instance *GardenPlanner) CreatePropertyWidget(property models.Property, feature *models.Feature) (fyne.Widget, error) {
-	// TODO: Custom widgets for property types.
+func (instance *GardenPlanner) CreatePropertyWidget(property models.Property, id models.FeatureID) (fyne.Widget, error) {
 	// TODO: formatting parameters.
-	value := feature.Properties[property.Name]
+	feature := instance.PlanController.Plan.Features[id]
 
-	switch property.PropertyType {
-	case "dimension":
-		// Dimensions are stored as strings in files.
-		val, err := instance.Formatter.ToDimension(value.(string))
+	// Values that don't fit the property were flagged on load; edit the
+	// default instead of failing.
+	value, err := property.Convert(feature.Properties[property.Name])
+	if err != nil {
+		value = property.DefaultValue()
+	}
+
+	// Applies a value if it fits the property's constraints. Returns whether
+	// it was applied, so widgets can revert otherwise.
+	setValue := func(v models.PropertyValue) bool {
+		v, err := property.Check(v)
 		if err != nil {
-			fmt.Printf("Warning on property %s.%s: %s\n", feature.Name, property.Name, err.Error())
+			dialog.ShowError(err, instance.Window)
+			return false
 		}
+		instance.PlanController.SetFeatureProperty(id, property.Name, v)
+		return true
+	}
+
+	switch property.PropertyType {
+	case models.DIMENSION:
+		val, _ := value.Dimension()
 		entry := ui.NewDimensionEntry(val, instance.Formatter)
 		entry.OnDimensionError = func(err error) {
 			dialog.ShowError(err, instance.Window)
 		}
-		entry.OnValueChanged = func(val units.Value) {
-			feature.Properties[property.Name] = instance.Formatter.FormatDimension(val)
-			instance.MainContainer.Refresh()
+		entry.OnValueChanged = func(newVal units.Value) {
+			if !setValue(models.NewDimensionValue(newVal)) {
+				entry.SetValue(val)
+			}
 		}
 		return entry, nil
-	case "decimal":
-		// TODO: Numerical entry widget.
-		entry := widget.NewEntry()
-		entry.SetText(instance.Formatter.FormatDecimal(float32(value.(float64))))
+	case models.STRING:
+		if len(property.Options) == 0 {
+			return instance.createTextPropertyEntry(property, value, setValue), nil
+		}
+
+		// Strings limited to a list of options can still be typed.
+		entry := widget.NewSelectEntry(property.Options)
+		entry.SetText(value.String())
 		entry.OnSubmitted = func(s string) {
-			setValue, err := instance.Formatter.ToDecimal(s)
-			if err != nil {
-				dialog.ShowError(err, instance.Window)
-				return
+			if !setValue(models.NewStringValue(s)) {
+				entry.SetText(value.String())
 			}
-			feature.Properties[property.Name] = setValue
-			instance.MainContainer.Refresh()
 		}
-		return entry, nil
-	case "integer":
-		entry := widget.NewEntry()
-		entry.SetText(instance.Formatter.FormatInteger(value.(int)))
-		entry.OnSubmitted = func(s string) {
-			setValue, err := instance.Formatter.ToInteger(s)
-			if err != nil {
-				dialog.ShowError(err, instance.Window)
-				return
+		entry.OnChanged = func(s string) {
+			// Picking an option from the drop-down applies it right away.
+			for _, option := range property.Options {
+				if s == option && s != value.String() {
+					setValue(models.NewStringValue(s))
+				}
 			}
-			feature.Properties[property.Name] = setValue
-			instance.MainContainer.Refresh()
 		}
 		return entry, nil
-	case "string":
-		// Should be a string.
-		entry := widget.NewEntry()
-		entry.SetText(value.(string))
-		entry.OnSubmitted = func(s string) {
-			feature.Properties[property.Name] = s
-			instance.MainContainer.Refresh()
+	case models.DECIMAL, models.INTEGER:
+		// TODO: Numerical entry widget.
+		return instance.createTextPropertyEntry(property, value, setValue), nil
+	case models.PLANT:
+		plantID, _ := value.PlantID()
+		entry := ui.NewIdSelectEntry(instance.plantOptions())
+		entry.SetSelectedID(plantID)
+		entry.OnSelected = func(newID int) {
+			if !setValue(models.NewPlantValue(newID)) {
+				entry.SetSelectedID(plantID)
+			}
 		}
 		return entry, nil
+	case models.BOOLEAN:
+		checked, _ := value.Boolean()
+		check := widget.NewCheck("", nil)
+		check.SetChecked(checked)
+		check.OnChanged = func(b bool) {
+			if !setValue(models.NewBooleanValue(b)) {
+				check.SetChecked(checked)
+			}
+		}
+		return check, nil
+	case models.ENUM:
+		selector := widget.NewSelect(property.Options, nil)
+		selector.SetSelected(value.String())
+		selector.OnChanged = func(option string) {
+			if !setValue(models.NewEnumValue(option)) {
+				selector.SetSelected(value.String())
+			}
+		}
+		return selector, nil
 	default:
 		// Return a disabled entry and throw an error.
 		entry := widget.NewEntry()
@@ -324,10 +672,148 @@ func (instance *GardenPlanner) CreatePropertyWidget(property models.Property, fe
 	}
 }
 
+// Creates an entry whose text is parsed as the property's type on submit.
+func (instance *GardenPlanner) createTextPropertyEntry(property models.Property, value models.PropertyValue, setValue func(models.PropertyValue) bool) *widget.Entry {
+	entry := widget.NewEntry()
+	entry.SetText(value.String())
+	entry.OnSubmitted = func(s string) {
+		v, err := property.Convert(models.NewStringValue(s))
+		if err != nil {
+			dialog.ShowError(err, instance.Window)
+			entry.SetText(value.String())
+			return
+		}
+		if !setValue(v) {
+			entry.SetText(value.String())
+		}
+	}
+	return entry
+}
+
+// Plants that can be referenced by a property, including none at all.
+func (instance *GardenPlanner) plantOptions() []ui.IdOption {
+	options := []ui.IdOption{{ID: models.NO_PLANT, Name: "(none)"}}
+	for _, p := range instance.PlantController.Plants() {
+		options = append(options, ui.IdOption{ID: p.ID, Name: p.Name})
+	}
+	return options
+}
+
+func (instance *GardenPlanner) PlantAdded(plant models.Plant) {
+	instance.PlantEditor.Refresh()
+	instance.WaterBudgetWindow.Refresh()
+	instance.RefreshCompanions()
+
+	// An undone removal brings back references to the plant.
+	instance.RefreshPlantWarnings()
+}
+
+func (instance *GardenPlanner) PlantUpdated(plant models.Plant) {
+	instance.PlantEditor.Refresh()
+	instance.WaterBudgetWindow.Refresh()
+	instance.RefreshCompanions()
+
+	// Show the new name in the property panel.
+	if instance.PlanController.HasSelection() {
+		instance.SelectFeature(instance.PlanController.GetSelectedFeature())
+	}
+}
+
+// Finds interacting neighbours again once plants or their interactions
+// change.
+func (instance *GardenPlanner) RefreshCompanions() {
+	instance.GardenWidget.CalculateCompanionLinks()
+	instance.GardenWidget.Refresh()
+	instance.RefreshNeighbours()
+}
+
+// Asks before removing a plant, listing the interactions that will go with
+// it. Features still using the plant are listed once it is removed.
+func (instance *GardenPlanner) ConfirmRemovePlant(id int, parent fyne.Window) {
+	plant, ok := instance.PlantController.GetPlant(id)
+	if !ok {
+		return
+	}
+
+	message := fmt.Sprintf("Remove %s from the plant database?", plant.Name)
+	interacting := []string{}
+	for _, p := range instance.PlantController.PlantsInteractingWith(id) {
+		interacting = append(interacting, p.Name)
+	}
+	if len(interacting) > 0 {
+		message += fmt.Sprintf("\n\nInteractions with it will be removed from:\n%s", strings.Join(interacting, "\n"))
+	}
+
+	dialog.ShowConfirm("Remove Plant", message, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		if err := instance.PlantController.RemovePlant(id); err != nil {
+			dialog.ShowError(err, parent)
+		}
+	}, parent)
+}
+
+func (instance *GardenPlanner) PlantRemoved(plant models.Plant) {
+	instance.PlantEditor.Refresh()
+	instance.WaterBudgetWindow.Refresh()
+	instance.RefreshCompanions()
+
+	if instance.PlanController.HasSelection() {
+		instance.SelectFeature(instance.PlanController.GetSelectedFeature())
+	}
+	instance.RefreshPlantWarnings()
+
+	// Let the user know which features lost their plant.
+	names := []string{}
+	for _, id := range instance.PlanController.FeaturesWithPlant(plant.ID) {
+		names = append(names, instance.PlanController.Plan.Features[id].Name)
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		dialog.ShowInformation(
+			"Plant Removed",
+			fmt.Sprintf("%s is still used by:\n%s", plant.Name, strings.Join(names, "\n")),
+			instance.Window,
+		)
+	}
+}
+
+// Flags features on the canvas that reference plants which don't exist.
+func (instance *GardenPlanner) RefreshPlantWarnings() {
+	for id := range instance.PlanController.Plan.Features {
+		instance.refreshPlantWarning(id)
+	}
+}
+
+func (instance *GardenPlanner) refreshPlantWarning(id models.FeatureID) {
+	feature := instance.PlanController.Plan.Features[id]
+	if feature == nil {
+		return
+	}
+
+	missing := []string{}
+	for _, value := range feature.Properties {
+		if value.Type != models.PLANT {
+			continue
+		}
+		plantID, _ := value.PlantID()
+		if plantID != models.NO_PLANT && !instance.PlantController.HasPlant(plantID) {
+			missing = append(missing, fmt.Sprintf("Missing plant #%d", plantID))
+		}
+	}
+	sort.Strings(missing)
+	instance.GardenWidget.SetFeatureWarning(id, strings.Join(missing, ", "))
+}
+
 // Cleans up the UI elements depending on a current plan.
 func (instance *GardenPlanner) ClosePlan() {
 	// instance.Content.RemoveAll()
+	instance.Sidebar.RemoveAll()
 	instance.PropertyTable.RemoveAll()
+	instance.NeighbourList.RemoveAll()
+	instance.ProblemList.RemoveAll()
+	instance.SetMeasuring(false)
 	instance.DeleteFeature.Disable()
 	instance.TemplateSelector.Disable()
 }
@@ -336,7 +822,7 @@ func (instance *GardenPlanner) SetupToolbar() {
 	// Create file
 	createButton := widget.NewToolbarAction(theme.DocumentCreateIcon(), func() {
 		// Open an empty plan.
-		instance.OpenPlan(&models.Plan{})
+		instance.OpenPlan(models.NewPlan())
 	})
 	instance.Toolbar.Append(createButton)
 
@@ -346,8 +832,13 @@ func (instance *GardenPlanner) SetupToolbar() {
 		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
 			if reader != nil {
 				// Read the plan and open it.
-				plan, _ := ReadObject[models.Plan](reader)
-				instance.OpenPlan(plan)
+				report := validation.NewReport()
+				plan, err := ReadObject[models.Plan](reader, report.In(reader.URI().Name()))
+				if err != nil {
+					ShowLoadReport("Could Not Open Plan", report, instance.Window)
+					return
+				}
+				instance.LoadPlan(plan, report.In(reader.URI().Name()))
 			}
 		}, instance.Window)
 	}))
@@ -358,11 +849,54 @@ func (instance *GardenPlanner) SetupToolbar() {
 		dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
 			if writer != nil {
 				// Save the plan.
-				WriteObject(writer, instance.PlanController.Plan)
+				if err := WriteObject(writer, instance.PlanController.Plan); err != nil {
+					dialog.ShowError(err, instance.Window)
+				}
 			}
 		}, instance.Window)
 	}))
 
+	// Undo/redo
+	instance.Toolbar.Append(widget.NewToolbarSeparator())
+	instance.UndoButton = ui.NewToolbarAction(theme.ContentUndoIcon(), instance.PlanController.Undo)
+	instance.RedoButton = ui.NewToolbarAction(theme.ContentRedoIcon(), instance.PlanController.Redo)
+	instance.Toolbar.Append(instance.UndoButton)
+	instance.Toolbar.Append(instance.RedoButton)
+	instance.RefreshHistory()
+	instance.Toolbar.Append(widget.NewToolbarSeparator())
+
+	// Measuring tool
+	instance.Toolbar.Append(widget.NewToolbarAction(theme.MoreHorizontalIcon(), func() {
+		instance.SetMeasuring(!instance.GardenWidget.IsMeasuring())
+	}))
+
+	// Zoom
+	instance.Toolbar.Append(widget.NewToolbarAction(theme.ZoomInIcon(), instance.GardenWidget.ZoomIn))
+	instance.Toolbar.Append(widget.NewToolbarAction(theme.ZoomOutIcon(), instance.GardenWidget.ZoomOut))
+	instance.Toolbar.Append(widget.NewToolbarAction(theme.ZoomFitIcon(), instance.GardenWidget.ZoomToFit))
+	instance.Toolbar.Append(widget.NewToolbarAction(theme.VisibilityIcon(), instance.ZoomToSelection))
+	instance.Toolbar.Append(widget.NewToolbarSeparator())
+
+	// Plant database
+	instance.Toolbar.Append(widget.NewToolbarAction(theme.StorageIcon(), func() {
+		instance.PlantEditor.Show()
+	}))
+
+	// Layout solver
+	instance.Toolbar.Append(widget.NewToolbarAction(theme.GridIcon(), func() {
+		instance.SolverWindow.Show()
+	}))
+
+	// Irrigation designer
+	instance.Toolbar.Append(widget.NewToolbarAction(theme.MediaRecordIcon(), func() {
+		instance.IrrigationWindow.Show()
+	}))
+
+	// Water budget
+	instance.Toolbar.Append(widget.NewToolbarAction(theme.ListIcon(), func() {
+		instance.WaterBudgetWindow.Show()
+	}))
+
 	// Settings
 	instance.Toolbar.Append(widget.NewToolbarAction(theme.SettingsIcon(), func() {
 		// Display the settings window.
@@ -391,3 +925,114 @@ func (instance *GardenPlanner) SetupFeatureTools() {
 	instance.DeleteFeature.Disable()
 	instance.FeatureTools.Add(instance.DeleteFeature)
 }
+
+// Registers keyboard shortcuts on the main window.
+func (instance *GardenPlanner) SetupShortcuts() {
+	canvas := instance.Window.Canvas()
+
+	// Undo: Ctrl+Z
+	canvas.AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyZ,
+		Modifier: fyne.KeyModifierShortcutDefault,
+	}, func(shortcut fyne.Shortcut) {
+		instance.PlanController.Undo()
+	})
+
+	// Redo: Ctrl+Shift+Z
+	canvas.AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyZ,
+		Modifier: fyne.KeyModifierShortcutDefault | fyne.KeyModifierShift,
+	}, func(shortcut fyne.Shortcut) {
+		instance.PlanController.Redo()
+	})
+
+	// Zoom in and out: Ctrl+= and Ctrl+-
+	canvas.AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyEqual,
+		Modifier: fyne.KeyModifierShortcutDefault,
+	}, func(shortcut fyne.Shortcut) {
+		instance.GardenWidget.ZoomIn()
+	})
+	canvas.AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyMinus,
+		Modifier: fyne.KeyModifierShortcutDefault,
+	}, func(shortcut fyne.Shortcut) {
+		instance.GardenWidget.ZoomOut()
+	})
+
+	// Zoom to fit: Ctrl+0
+	canvas.AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.Key0,
+		Modifier: fyne.KeyModifierShortcutDefault,
+	}, func(shortcut fyne.Shortcut) {
+		instance.GardenWidget.ZoomToFit()
+	})
+
+	// Zoom to selection: Ctrl+Shift+0
+	canvas.AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.Key0,
+		Modifier: fyne.KeyModifierShortcutDefault | fyne.KeyModifierShift,
+	}, func(shortcut fyne.Shortcut) {
+		instance.ZoomToSelection()
+	})
+
+	// Pan: hold space and drag. Stop measuring: Escape. Keys only arrive
+	// here while no entry has focus.
+	if keys, ok := canvas.(desktop.Canvas); ok {
+		keys.SetOnKeyDown(func(e *fyne.KeyEvent) {
+			switch e.Name {
+			case fyne.KeySpace:
+				instance.GardenWidget.SetPanKey(true)
+			case fyne.KeyEscape:
+				instance.SetMeasuring(false)
+			}
+		})
+		keys.SetOnKeyUp(func(e *fyne.KeyEvent) {
+			if e.Name == fyne.KeySpace {
+				instance.GardenWidget.SetPanKey(false)
+			}
+		})
+	}
+}
+
+// Turns the measuring tool on or off.
+func (instance *GardenPlanner) SetMeasuring(on bool) {
+	instance.GardenWidget.SetMeasuring(on)
+	instance.measureStatus = ""
+	if on {
+		instance.measureStatus = "Measuring: tap two points or feature edges"
+	}
+	instance.RefreshStatus()
+}
+
+// Shows the distance found by the measuring tool.
+func (instance *GardenPlanner) Measured(from geometry.Vector, to geometry.Vector) {
+	instance.measureStatus = "Distance: " + instance.Formatter.FormatLength(from.Distance(&to))
+	instance.RefreshStatus()
+}
+
+// Shows where the pointer is on the plan.
+func (instance *GardenPlanner) PointerMoved(p geometry.Vector) {
+	instance.pointerStatus = fmt.Sprintf("X: %s  Y: %s", instance.Formatter.FormatLength(p.X), instance.Formatter.FormatLength(p.Y))
+	instance.RefreshStatus()
+}
+
+func (instance *GardenPlanner) PointerLeft() {
+	instance.pointerStatus = ""
+	instance.RefreshStatus()
+}
+
+func (instance *GardenPlanner) RefreshStatus() {
+	parts := []string{}
+	for _, part := range []string{instance.pointerStatus, instance.measureStatus} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	instance.StatusBar.SetText(strings.Join(parts, "    "))
+}
+
+// Zooms the garden to the selected feature, if there is one.
+func (instance *GardenPlanner) ZoomToSelection() {
+	instance.GardenWidget.ZoomToFeature(instance.PlanController.GetSelectedFeature())
+}
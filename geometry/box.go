@@ -1,5 +1,7 @@
 package geometry
 
+import "math"
+
 type BoxEdge int
 
 const TOP = BoxEdge(1)
@@ -84,3 +86,44 @@ func (box *Box) SetWidth(v float32) {
 func (box *Box) SetHeight(v float32) {
 	box.Size.Y = v
 }
+
+// Whether the box covers no area.
+func (box *Box) IsEmpty() bool {
+	return box.Size.X <= 0 || box.Size.Y <= 0
+}
+
+func (box *Box) Center() Vector {
+	return NewVector(
+		box.Location.X+box.Size.X/2,
+		box.Location.Y+box.Size.Y/2,
+		0,
+	)
+}
+
+// Smallest box holding both boxes.
+func (box *Box) Union(other *Box) Box {
+	left := min(box.Location.X, other.Location.X)
+	top := min(box.Location.Y, other.Location.Y)
+	right := max(box.Location.X+box.Size.X, other.Location.X+other.Size.X)
+	bottom := max(box.Location.Y+box.Size.Y, other.Location.Y+other.Size.Y)
+	return NewBox(left, top, right-left, bottom-top)
+}
+
+// Shortest distance between the edges of two boxes. Touching or overlapping
+// boxes are 0 apart.
+func (box *Box) Distance(other *Box) float32 {
+	dx := gap(box.Location.X, box.Location.X+box.Size.X, other.Location.X, other.Location.X+other.Size.X)
+	dy := gap(box.Location.Y, box.Location.Y+box.Size.Y, other.Location.Y, other.Location.Y+other.Size.Y)
+	return float32(math.Hypot(float64(dx), float64(dy)))
+}
+
+// Gap between two ranges on one axis, or 0 if they overlap.
+func gap(min1, max1, min2, max2 float32) float32 {
+	if max1 < min2 {
+		return min2 - max1
+	}
+	if max2 < min1 {
+		return min1 - max2
+	}
+	return 0
+}
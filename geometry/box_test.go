@@ -0,0 +1,30 @@
+package geometry
+
+import "testing"
+
+func TestBoxDistance(t *testing.T) {
+	a := NewBox(0, 0, 10, 10)
+	tests := []struct {
+		b    Box
+		want float32
+	}{
+		{NewBox(5, 5, 10, 10), 0},
+		{NewBox(10, 0, 10, 10), 0},
+		{NewBox(13, 0, 10, 10), 3},
+		{NewBox(13, 14, 10, 10), 5},
+	}
+
+	for _, test := range tests {
+		if got := a.Distance(&test.b); got != test.want {
+			t.Errorf("Distance(%v) == %v; want %v", test.b, got, test.want)
+		}
+	}
+}
+
+func TestBoxUnion(t *testing.T) {
+	a := NewBox(0, 0, 10, 10)
+	b := NewBox(-5, 20, 10, 5)
+	if got, want := a.Union(&b), NewBox(-5, 0, 15, 25); got != want {
+		t.Errorf("Union(%v) == %v; want %v", b, got, want)
+	}
+}
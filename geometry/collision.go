@@ -0,0 +1,125 @@
+package geometry
+
+import "sort"
+
+type CollisionKind int
+
+// Kinds of collision.
+const (
+	// Two shapes cover some of the same area. Touching edges don't count.
+	OVERLAP = CollisionKind(1)
+
+	// A shape sticks out of the bounds.
+	OUT_OF_BOUNDS = CollisionKind(2)
+
+	// Two shapes are closer than the clearance.
+	CLEARANCE = CollisionKind(3)
+)
+
+// Problem with a shape, or between two shapes. Shapes are referred to by
+// their index; B is -1 for problems with the bounds.
+type Collision struct {
+	Kind CollisionKind
+	A, B int
+
+	// Gap between the shapes, for clearance problems.
+	Distance float32
+}
+
+// Finds shapes that overlap, stick out of the bounds, or are closer than
+// the clearance. near finds the shapes whose bounds are within a distance
+// of an area, usually from an index the caller keeps, such as a QuadTree.
+// Only pairs for which compare is true are checked, e.g. to leave out a bed
+// and the rows inside it. Empty bounds, such as those of a plan with no size
+// yet, skip bounds checks, and a clearance of 0 skips clearance checks.
+func FindCollisions(shapes []Shape, bounds Box, clearance float32, near func(area Box, distance float32) []int, compare func(a int, b int) bool) []Collision {
+	collisions := []Collision{}
+	for i, s := range shapes {
+		if !bounds.IsEmpty() && !Inside(s, bounds) {
+			collisions = append(collisions, Collision{Kind: OUT_OF_BOUNDS, A: i, B: -1})
+		}
+	}
+
+	// Shapes are never closer than their bounds.
+	for i := range shapes {
+		others := near(shapes[i].Bounds(), clearance)
+		sort.Ints(others)
+		for _, j := range others {
+			if j <= i || !compare(i, j) {
+				continue
+			}
+
+			if Overlaps(shapes[i], shapes[j]) {
+				collisions = append(collisions, Collision{Kind: OVERLAP, A: i, B: j})
+			} else if clearance > 0 {
+				if gap := Gap(shapes[i], shapes[j]); gap < clearance {
+					collisions = append(collisions, Collision{Kind: CLEARANCE, A: i, B: j, Distance: gap})
+				}
+			}
+		}
+	}
+	return collisions
+}
+
+// Whether a shape lies entirely within a box.
+func Inside(s Shape, bounds Box) bool {
+	b := s.Bounds()
+	const epsilon = 1e-3
+	return b.Location.X >= bounds.Location.X-epsilon &&
+		b.Location.Y >= bounds.Location.Y-epsilon &&
+		b.Location.X+b.Size.X <= bounds.Location.X+bounds.Size.X+epsilon &&
+		b.Location.Y+b.Size.Y <= bounds.Location.Y+bounds.Size.Y+epsilon
+}
+
+// Whether two shapes cover some of the same area. Unlike Intersects,
+// shapes that only touch don't overlap.
+func Overlaps(a Shape, b Shape) bool {
+	boundsA, boundsB := a.Bounds(), b.Bounds()
+	if gap(boundsA.Location.X, boundsA.Location.X+boundsA.Size.X, boundsB.Location.X, boundsB.Location.X+boundsB.Size.X) > 0 ||
+		gap(boundsA.Location.Y, boundsA.Location.Y+boundsA.Size.Y, boundsB.Location.Y, boundsB.Location.Y+boundsB.Size.Y) > 0 {
+		return false
+	}
+
+	// Sides that cross, rather than touch.
+	outlineA, outlineB := a.Outline(), b.Outline()
+	for i := range outlineA {
+		a1, a2 := outlineA[i], outlineA[(i+1)%len(outlineA)]
+		for j := range outlineB {
+			b1, b2 := outlineB[j], outlineB[(j+1)%len(outlineB)]
+			if orientation(b1, b2, a1)*orientation(b1, b2, a2) < 0 && orientation(a1, a2, b1)*orientation(a1, a2, b2) < 0 {
+				return true
+			}
+		}
+	}
+
+	// One inside the other, side by side along a shared line, or both the
+	// same. Points on an outline only touch it.
+	common := NewBox(
+		max(boundsA.Location.X, boundsB.Location.X),
+		max(boundsA.Location.Y, boundsB.Location.Y),
+		0, 0,
+	)
+	common.Size.X = min(boundsA.Location.X+boundsA.Size.X, boundsB.Location.X+boundsB.Size.X) - common.Location.X
+	common.Size.Y = min(boundsA.Location.Y+boundsA.Size.Y, boundsB.Location.Y+boundsB.Size.Y) - common.Location.Y
+	points := append(append([]Vector{}, outlineA...), outlineB...)
+	points = append(points, boundsA.Center(), boundsB.Center(), common.Center())
+	for _, p := range points {
+		if strictlyInside(a, outlineA, p) && strictlyInside(b, outlineB, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether a point is inside a shape and not on its outline.
+func strictlyInside(s Shape, outline []Vector, p Vector) bool {
+	if !s.Contains(p) {
+		return false
+	}
+	for i := range outline {
+		if onSegment(p, outline[i], outline[(i+1)%len(outline)]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+package geometry
+
+import "testing"
+
+func TestOverlaps(t *testing.T) {
+	box := NewBox(0, 0, 10, 10)
+	same := NewBox(0, 0, 10, 10)
+	touching := NewBox(10, 0, 10, 10)
+	crossing := NewBox(5, 5, 10, 10)
+	shifted := NewBox(5, 0, 10, 10)
+	inner := NewBox(2, 2, 2, 2)
+
+	tests := []struct {
+		name  string
+		shape Shape
+		want  bool
+	}{
+		{"same box", &same, true},
+		{"touching box", &touching, false},
+		{"crossing box", &crossing, true},
+		{"shifted box", &shifted, true},
+		{"box inside", &inner, true},
+		{"circle touching", NewCircle(NewVector(15, 5, 0), 5), false},
+		{"circle crossing", NewCircle(NewVector(13, 5, 0), 5), true},
+	}
+
+	for _, test := range tests {
+		if got := Overlaps(&box, test.shape); got != test.want {
+			t.Errorf("%s Overlaps() == %v; want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestFindCollisions(t *testing.T) {
+	a := NewBox(0, 0, 10, 10)
+	b := NewBox(5, 5, 10, 10)
+	c := NewBox(30, 0, 10, 10)
+	d := NewBox(45, 0, 10, 10)
+	shapes := []Shape{&a, &b, &c, &d}
+	index := NewQuadTree[int](NewBox(0, 0, 50, 50))
+	for i, s := range shapes {
+		index.Insert(i, s.Bounds())
+	}
+	all := func(i, j int) bool { return true }
+
+	collisions := FindCollisions(shapes, NewBox(0, 0, 50, 50), 6, index.Within, all)
+	want := map[Collision]bool{
+		{Kind: OUT_OF_BOUNDS, A: 3, B: -1}:         true,
+		{Kind: OVERLAP, A: 0, B: 1}:                true,
+		{Kind: CLEARANCE, A: 2, B: 3, Distance: 5}: true,
+	}
+	if len(collisions) != len(want) {
+		t.Fatalf("FindCollisions() == %+v; want %d collisions", collisions, len(want))
+	}
+	for _, c := range collisions {
+		if !want[c] {
+			t.Errorf("unexpected collision %+v", c)
+		}
+	}
+
+	// Empty bounds aren't checked.
+	none := func(area Box, distance float32) []int { return []int{} }
+	if got := FindCollisions(shapes[3:], NewBoxZero(), 0, none, all); len(got) != 0 {
+		t.Errorf("FindCollisions() == %+v with empty bounds; want none", got)
+	}
+
+	// Pairs left out by compare aren't checked.
+	if got := FindCollisions(shapes[:2], NewBox(0, 0, 50, 50), 0, index.Within, func(i, j int) bool { return false }); len(got) != 0 {
+		t.Errorf("FindCollisions() == %+v; want none", got)
+	}
+}
@@ -0,0 +1,219 @@
+package geometry
+
+import (
+	"math"
+	"sort"
+)
+
+// Most items a quadtree node holds before splitting, and how many times
+// nodes may split.
+const (
+	QUADTREE_CAPACITY  = 8
+	QUADTREE_MAX_DEPTH = 10
+)
+
+// Index of boxes by key, for finding the items in an area or near a point
+// without checking every item. The tree grows to fit items outside its
+// bounds.
+type QuadTree[K comparable] struct {
+	root  *quadNode[K]
+	boxes map[K]Box
+}
+
+// Part of the tree's area. Items are kept in the smallest node that holds
+// their whole box.
+type quadNode[K comparable] struct {
+	bounds Box
+	depth  int
+	items  []K
+
+	// Top left, top right, bottom right and bottom left quarters, or nil
+	// before the node splits.
+	children []*quadNode[K]
+}
+
+// Creates a new, empty quadtree covering an area.
+func NewQuadTree[K comparable](bounds Box) *QuadTree[K] {
+	return &QuadTree[K]{
+		root:  &quadNode[K]{bounds: bounds},
+		boxes: map[K]Box{},
+	}
+}
+
+func (t *QuadTree[K]) Len() int {
+	return len(t.boxes)
+}
+
+// Box of an item, and whether it is in the tree.
+func (t *QuadTree[K]) Box(key K) (Box, bool) {
+	box, ok := t.boxes[key]
+	return box, ok
+}
+
+// Adds an item, or moves it if it is already in the tree.
+func (t *QuadTree[K]) Insert(key K, box Box) {
+	t.Remove(key)
+	t.boxes[key] = box
+	if !encloses(&t.root.bounds, &box) {
+		t.rebuild(grow(t.root.bounds, box))
+		return
+	}
+	t.root.insert(key, box, t.boxes)
+}
+
+// Takes an item out of the tree. Unknown keys are ignored.
+func (t *QuadTree[K]) Remove(key K) {
+	box, ok := t.boxes[key]
+	if !ok {
+		return
+	}
+	delete(t.boxes, key)
+	t.root.remove(key, box)
+}
+
+// Items whose boxes touch or overlap an area.
+func (t *QuadTree[K]) Search(area Box) []K {
+	return t.Within(area, 0)
+}
+
+// Items whose boxes are at most distance from an area.
+func (t *QuadTree[K]) Within(area Box, distance float32) []K {
+	found := []K{}
+	t.root.within(area, distance, t.boxes, &found)
+	return found
+}
+
+// Item whose box is nearest a point, or false if the tree is empty. Items
+// containing the point are 0 away; ties go to any one of them.
+func (t *QuadTree[K]) Nearest(p Vector) (K, bool) {
+	var nearest K
+	best := float32(math.Inf(1))
+	point := NewBox(p.X, p.Y, 0, 0)
+	t.root.nearest(&point, t.boxes, &nearest, &best)
+	return nearest, !math.IsInf(float64(best), 1)
+}
+
+// Puts every item back into a new tree covering an area.
+func (t *QuadTree[K]) rebuild(bounds Box) {
+	t.root = &quadNode[K]{bounds: bounds}
+	for key, box := range t.boxes {
+		t.root.insert(key, box, t.boxes)
+	}
+}
+
+func (n *quadNode[K]) insert(key K, box Box, boxes map[K]Box) {
+	if n.children != nil {
+		if child := n.childFor(&box); child != nil {
+			child.insert(key, box, boxes)
+			return
+		}
+	}
+
+	n.items = append(n.items, key)
+	if n.children == nil && len(n.items) > QUADTREE_CAPACITY && n.depth < QUADTREE_MAX_DEPTH {
+		n.split(boxes)
+	}
+}
+
+// Divides a leaf into quarters and moves down the items that fit in one.
+func (n *quadNode[K]) split(boxes map[K]Box) {
+	x, y := n.bounds.Location.X, n.bounds.Location.Y
+	w, h := n.bounds.Size.X/2, n.bounds.Size.Y/2
+	for _, b := range []Box{NewBox(x, y, w, h), NewBox(x+w, y, w, h), NewBox(x+w, y+h, w, h), NewBox(x, y+h, w, h)} {
+		n.children = append(n.children, &quadNode[K]{bounds: b, depth: n.depth + 1})
+	}
+
+	items := n.items
+	n.items = nil
+	for _, key := range items {
+		box := boxes[key]
+		if child := n.childFor(&box); child != nil {
+			child.insert(key, box, boxes)
+		} else {
+			n.items = append(n.items, key)
+		}
+	}
+}
+
+func (n *quadNode[K]) remove(key K, box Box) {
+	if n.children != nil {
+		if child := n.childFor(&box); child != nil {
+			child.remove(key, box)
+			return
+		}
+	}
+
+	for i, k := range n.items {
+		if k == key {
+			n.items = append(n.items[:i], n.items[i+1:]...)
+			return
+		}
+	}
+}
+
+// Quarter holding the whole of a box, or nil if it spans more than one.
+func (n *quadNode[K]) childFor(box *Box) *quadNode[K] {
+	for _, child := range n.children {
+		if encloses(&child.bounds, box) {
+			return child
+		}
+	}
+	return nil
+}
+
+func (n *quadNode[K]) within(area Box, distance float32, boxes map[K]Box, found *[]K) {
+	for _, key := range n.items {
+		box := boxes[key]
+		if box.Distance(&area) <= distance {
+			*found = append(*found, key)
+		}
+	}
+	for _, child := range n.children {
+		if child.bounds.Distance(&area) <= distance {
+			child.within(area, distance, boxes, found)
+		}
+	}
+}
+
+// Checks the items of a node, then its quarters nearest first, skipping
+// quarters farther than the best item so far.
+func (n *quadNode[K]) nearest(point *Box, boxes map[K]Box, nearest *K, best *float32) {
+	for _, key := range n.items {
+		box := boxes[key]
+		if d := box.Distance(point); d < *best {
+			*nearest, *best = key, d
+		}
+	}
+
+	children := append([]*quadNode[K]{}, n.children...)
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].bounds.Distance(point) < children[j].bounds.Distance(point)
+	})
+	for _, child := range children {
+		if child.bounds.Distance(point) < *best {
+			child.nearest(point, boxes, nearest, best)
+		}
+	}
+}
+
+// Area around bounds and a box outside them, with as much room again to
+// spare so the tree rarely has to grow. Empty bounds are left out.
+func grow(bounds Box, box Box) Box {
+	if bounds.Size.X <= 0 || bounds.Size.Y <= 0 {
+		bounds = box
+	}
+	left := min(bounds.Location.X, box.Location.X)
+	top := min(bounds.Location.Y, box.Location.Y)
+	width := max(bounds.Location.X+bounds.Size.X, box.Location.X+box.Size.X) - left
+	height := max(bounds.Location.Y+bounds.Size.Y, box.Location.Y+box.Size.Y) - top
+	size := max(width, height, 1)
+	return NewBox(left-size/2, top-size/2, size*2, size*2)
+}
+
+// Whether the outer box holds the whole of the inner one.
+func encloses(outer *Box, inner *Box) bool {
+	return inner.Location.X >= outer.Location.X &&
+		inner.Location.Y >= outer.Location.Y &&
+		inner.Location.X+inner.Size.X <= outer.Location.X+outer.Size.X &&
+		inner.Location.Y+inner.Size.Y <= outer.Location.Y+outer.Size.Y
+}
@@ -0,0 +1,166 @@
+package geometry
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// Rows of small beds scattered over a large plan.
+func randomBoxes(count int) []Box {
+	r := rand.New(rand.NewSource(1))
+	boxes := []Box{}
+	for i := 0; i < count; i++ {
+		boxes = append(boxes, NewBox(r.Float32()*1000, r.Float32()*1000, 1+r.Float32()*20, 1+r.Float32()*20))
+	}
+	return boxes
+}
+
+func newTestTree(boxes []Box) *QuadTree[int] {
+	t := NewQuadTree[int](NewBox(0, 0, 1000, 1000))
+	for i, b := range boxes {
+		t.Insert(i, b)
+	}
+	return t
+}
+
+// Items within distance of an area, checking every box.
+func linearWithin(boxes []Box, area Box, distance float32) []int {
+	found := []int{}
+	for i, b := range boxes {
+		if b.Distance(&area) <= distance {
+			found = append(found, i)
+		}
+	}
+	return found
+}
+
+func linearNearest(boxes []Box, p Vector) (int, float32) {
+	point := NewBox(p.X, p.Y, 0, 0)
+	nearest, best := -1, float32(0)
+	for i, b := range boxes {
+		if d := b.Distance(&point); nearest < 0 || d < best {
+			nearest, best = i, d
+		}
+	}
+	return nearest, best
+}
+
+func sameItems(a []int, b []int) bool {
+	sort.Ints(a)
+	sort.Ints(b)
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestQuadTreeSearch(t *testing.T) {
+	boxes := randomBoxes(500)
+	tree := newTestTree(boxes)
+
+	// Move some items, including off the edge of the tree, and remove others.
+	for i := 0; i < 100; i++ {
+		boxes[i].Location.X += 200
+		tree.Insert(i, boxes[i])
+	}
+	for i := 400; i < 500; i++ {
+		tree.Remove(i)
+	}
+	boxes = boxes[:400]
+	if tree.Len() != 400 {
+		t.Fatalf("Len() == %d; want 400", tree.Len())
+	}
+
+	areas := []Box{NewBox(0, 0, 100, 100), NewBox(500, 500, 300, 50), NewBox(1100, 0, 100, 1000), NewBox(-50, -50, 10, 10)}
+	for _, area := range areas {
+		if got, want := tree.Search(area), linearWithin(boxes, area, 0); !sameItems(got, want) {
+			t.Errorf("Search(%v) == %v; want %v", area, got, want)
+		}
+		if got, want := tree.Within(area, 30), linearWithin(boxes, area, 30); !sameItems(got, want) {
+			t.Errorf("Within(%v, 30) == %v; want %v", area, got, want)
+		}
+	}
+}
+
+func TestQuadTreeNearest(t *testing.T) {
+	if _, ok := NewQuadTree[int](NewBoxZero()).Nearest(NewVector(0, 0, 0)); ok {
+		t.Errorf("Nearest() found an item in an empty tree")
+	}
+
+	boxes := randomBoxes(300)
+	tree := newTestTree(boxes)
+	point := NewBox(0, 0, 0, 0)
+	for _, p := range []Vector{NewVector(10, 10, 0), NewVector(500, 500, 0), NewVector(-300, 1200, 0)} {
+		got, ok := tree.Nearest(p)
+		want, best := linearNearest(boxes, p)
+		point.Location = p
+		if !ok || boxes[got].Distance(&point) != best {
+			t.Errorf("Nearest(%v) == %d; want %d", p, got, want)
+		}
+	}
+}
+
+func TestQuadTreeGrows(t *testing.T) {
+	// Blank plans have no size, so the tree starts empty.
+	tree := NewQuadTree[string](NewBoxZero())
+	tree.Insert("bed", NewBox(10, 10, 5, 5))
+	tree.Insert("tree", NewBox(-40, 300, 10, 10))
+	if got := tree.Search(NewBox(-50, 290, 20, 20)); len(got) != 1 || got[0] != "tree" {
+		t.Errorf("Search() == %v; want [tree]", got)
+	}
+}
+
+func BenchmarkQuadTreeSearch(b *testing.B) {
+	boxes := randomBoxes(2000)
+	tree := newTestTree(boxes)
+	area := NewBox(400, 400, 50, 50)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Search(area)
+	}
+}
+
+func BenchmarkLinearSearch(b *testing.B) {
+	boxes := randomBoxes(2000)
+	area := NewBox(400, 400, 50, 50)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		linearWithin(boxes, area, 0)
+	}
+}
+
+func BenchmarkQuadTreeNearest(b *testing.B) {
+	boxes := randomBoxes(2000)
+	tree := newTestTree(boxes)
+	p := NewVector(400, 400, 0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Nearest(p)
+	}
+}
+
+func BenchmarkLinearNearest(b *testing.B) {
+	boxes := randomBoxes(2000)
+	p := NewVector(400, 400, 0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		linearNearest(boxes, p)
+	}
+}
+
+func BenchmarkQuadTreeMove(b *testing.B) {
+	boxes := randomBoxes(2000)
+	tree := newTestTree(boxes)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		box := boxes[i%len(boxes)]
+		box.Location.X += float32(i % 7)
+		tree.Insert(i%len(boxes), box)
+	}
+}
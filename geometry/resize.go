@@ -0,0 +1,100 @@
+package geometry
+
+// Resizing a box by dragging one of its edges or corners.
+type Resize struct {
+	// Edges being dragged: LEFT or RIGHT across, TOP or BOTTOM down, or 0
+	// for an axis that isn't dragged.
+	X BoxEdge
+	Y BoxEdge
+
+	// Keeps the ratio of width to height.
+	KeepAspect bool
+
+	// Moves the opposite edges too, so the center stays in place.
+	FromCenter bool
+
+	// Smallest width or height the box may shrink to.
+	MinSize float32
+}
+
+// Creates a new resize from dragging an edge.
+func NewEdgeResize(edge BoxEdge) Resize {
+	switch edge {
+	case LEFT, RIGHT:
+		return Resize{X: edge}
+	}
+	return Resize{Y: edge}
+}
+
+// Creates a new resize from dragging a corner.
+func NewCornerResize(corner BoxCorner) Resize {
+	x, y := corner.Edges()
+	return Resize{X: x, Y: y}
+}
+
+// The box after its edges are dragged by a delta. The size never drops
+// below the minimum, so the box can't flip over.
+func (r Resize) Apply(box Box, delta Vector) Box {
+	width := box.Size.X + r.grow(r.X, LEFT, delta.X)
+	height := box.Size.Y + r.grow(r.Y, TOP, delta.Y)
+
+	if r.KeepAspect && box.Size.X > 0 && box.Size.Y > 0 {
+		// Follow whichever dragged side changed the most.
+		scaleX, scaleY := width/box.Size.X, height/box.Size.Y
+		scale := scaleX
+		if r.X == 0 || (r.Y != 0 && abs(scaleY-1) > abs(scaleX-1)) {
+			scale = scaleY
+		}
+		scale = max(scale, r.MinSize/box.Size.X, r.MinSize/box.Size.Y)
+		width, height = box.Size.X*scale, box.Size.Y*scale
+	}
+	width, height = max(width, r.MinSize), max(height, r.MinSize)
+
+	return NewBox(
+		r.place(r.X, LEFT, box.Location.X, box.Size.X, width),
+		r.place(r.Y, TOP, box.Location.Y, box.Size.Y, height),
+		width,
+		height,
+	)
+}
+
+// Change in size along one axis from dragging an edge. Dragging the first
+// edge, left or top, outwards makes the box bigger.
+func (r Resize) grow(edge BoxEdge, first BoxEdge, d float32) float32 {
+	if edge == 0 {
+		return 0
+	}
+	if edge == first {
+		d = -d
+	}
+	if r.FromCenter {
+		d *= 2
+	}
+	return d
+}
+
+// New start of the box along one axis, keeping the edge opposite the
+// dragged one in place, or the center for resizes from the center and axes
+// that only change to keep the aspect.
+func (r Resize) place(edge BoxEdge, first BoxEdge, start float32, size float32, newSize float32) float32 {
+	switch {
+	case edge == 0 || r.FromCenter:
+		return start + (size-newSize)/2
+	case edge == first:
+		return start + size - newSize
+	}
+	return start
+}
+
+// Edges that meet at a corner, across then down.
+func (c BoxCorner) Edges() (BoxEdge, BoxEdge) {
+	switch c {
+	case TOP_LEFT:
+		return LEFT, TOP
+	case TOP_RIGHT:
+		return RIGHT, TOP
+	case BOTTOM_RIGHT:
+		return RIGHT, BOTTOM
+	}
+	return LEFT, BOTTOM
+}
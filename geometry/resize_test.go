@@ -0,0 +1,29 @@
+package geometry
+
+import "testing"
+
+func TestResizeApply(t *testing.T) {
+	box := NewBox(10, 10, 20, 10)
+	tests := []struct {
+		name   string
+		resize Resize
+		delta  Vector
+		want   Box
+	}{
+		{"right edge", NewEdgeResize(RIGHT), NewVector(5, 3, 0), NewBox(10, 10, 25, 10)},
+		{"top edge", NewEdgeResize(TOP), NewVector(5, -4, 0), NewBox(10, 6, 20, 14)},
+		{"top left corner", NewCornerResize(TOP_LEFT), NewVector(-2, 3, 0), NewBox(8, 13, 22, 7)},
+		{"bottom right corner", NewCornerResize(BOTTOM_RIGHT), NewVector(2, 3, 0), NewBox(10, 10, 22, 13)},
+		{"past the opposite edge", Resize{X: LEFT, MinSize: 1}, NewVector(30, 0, 0), NewBox(29, 10, 1, 10)},
+		{"keeping the aspect", Resize{X: RIGHT, Y: BOTTOM, KeepAspect: true}, NewVector(20, 1, 0), NewBox(10, 10, 40, 20)},
+		{"edge keeping the aspect", Resize{X: RIGHT, KeepAspect: true}, NewVector(20, 0, 0), NewBox(10, 5, 40, 20)},
+		{"from the center", Resize{X: RIGHT, Y: BOTTOM, FromCenter: true}, NewVector(2, 1, 0), NewBox(8, 9, 24, 12)},
+		{"shrinking past zero keeping the aspect", Resize{X: LEFT, Y: TOP, KeepAspect: true, MinSize: 2}, NewVector(40, 40, 0), NewBox(26, 18, 4, 2)},
+	}
+
+	for _, test := range tests {
+		if got := test.resize.Apply(box, test.delta); got != test.want {
+			t.Errorf("%s Apply() == %v; want %v", test.name, got, test.want)
+		}
+	}
+}
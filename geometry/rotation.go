@@ -0,0 +1,74 @@
+package geometry
+
+import "math"
+
+// Point turned around a center by an angle in degrees. Coordinates are
+// top-left, so positive angles turn clockwise.
+func (v *Vector) Rotate(center Vector, degrees float32) Vector {
+	if degrees == 0 {
+		return v.Copy()
+	}
+
+	sin, cos := math.Sincos(float64(degrees) * math.Pi / 180)
+	dx, dy := float64(v.X-center.X), float64(v.Y-center.Y)
+	return NewVector(
+		center.X+float32(dx*cos-dy*sin),
+		center.Y+float32(dx*sin+dy*cos),
+		v.Z,
+	)
+}
+
+// Shape turned around a center by an angle in degrees, clockwise.
+type Rotated struct {
+	Shape  Shape
+	Center Vector
+	Angle  float32
+}
+
+// Turns a shape around a center. Shapes that aren't turned are returned as
+// they are.
+func NewRotated(shape Shape, center Vector, degrees float32) Shape {
+	if math.Mod(float64(degrees), 360) == 0 {
+		return shape
+	}
+	return &Rotated{Shape: shape, Center: center, Angle: degrees}
+}
+
+func (r *Rotated) Bounds() Box {
+	return boundsOf(r.Outline())
+}
+
+func (r *Rotated) Area() float32 {
+	return r.Shape.Area()
+}
+
+// Turns the point back and checks it against the unturned shape.
+func (r *Rotated) Contains(p Vector) bool {
+	return r.Shape.Contains(p.Rotate(r.Center, -r.Angle))
+}
+
+func (r *Rotated) Intersects(other Shape) bool {
+	return intersects(r, other)
+}
+
+func (r *Rotated) Outline() []Vector {
+	points := []Vector{}
+	for _, p := range r.Shape.Outline() {
+		points = append(points, p.Rotate(r.Center, r.Angle))
+	}
+	return points
+}
+
+// Moves a box that was changed in its own turned frame, so the parts that
+// didn't change stay in place on the plan. Boxes turn around their center,
+// so resizing a turned box would otherwise shift it sideways.
+func KeepInPlace(before Box, after Box, degrees float32) Box {
+	oldCenter, newCenter := before.Center(), after.Center()
+	shift := NewVector(newCenter.X-oldCenter.X, newCenter.Y-oldCenter.Y, 0)
+	turned := shift.Rotate(NewVector(0, 0, 0), degrees)
+
+	box := after.Copy()
+	box.Location.X += turned.X - shift.X
+	box.Location.Y += turned.Y - shift.Y
+	return box
+}
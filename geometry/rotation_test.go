@@ -0,0 +1,35 @@
+package geometry
+
+import "testing"
+
+func TestRotated(t *testing.T) {
+	// A 20 by 2 bed turned to run diagonally.
+	box := NewBox(0, 9, 20, 2)
+	bed := NewRotated(&box, box.Center(), 45)
+
+	if !bed.Contains(NewVector(17, 17, 0)) {
+		t.Errorf("Contains() == false along the diagonal; want true")
+	}
+	if bed.Contains(NewVector(18, 10, 0)) {
+		t.Errorf("Contains() == true at the unturned end; want false")
+	}
+
+	bounds := bed.Bounds()
+	if !near(bounds.Size.X, 15.56) || !near(bounds.Size.Y, 15.56) {
+		t.Errorf("Bounds() == %v; want about 15.56 square", bounds)
+	}
+}
+
+func TestKeepInPlace(t *testing.T) {
+	// Widen a box turned a quarter turn by moving its right edge, which now
+	// faces down. The top, which faces right, should stay put.
+	before := NewBox(0, 0, 10, 4)
+	after := NewBox(0, 0, 12, 4)
+	moved := KeepInPlace(before, after, 90)
+
+	outline := NewRotated(&moved, moved.Center(), 90).Outline()
+	start := NewRotated(&before, before.Center(), 90).Outline()
+	if !near(outline[0].X, start[0].X) || !near(outline[0].Y, start[0].Y) {
+		t.Errorf("fixed corner moved from %v to %v", start[0], outline[0])
+	}
+}
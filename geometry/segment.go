@@ -0,0 +1,79 @@
+package geometry
+
+import "math"
+
+// Straight line from A to B, such as one edge of an outline. A segment
+// whose ends are the same is a single point.
+type Segment struct {
+	A Vector
+	B Vector
+}
+
+// Creates a new segment that is a single point.
+func NewPointSegment(p Vector) Segment {
+	return Segment{A: p, B: p}
+}
+
+func (s Segment) Length() float32 {
+	return s.A.Distance(&s.B)
+}
+
+// Point on the segment nearest to p.
+func (s Segment) Nearest(p Vector) Vector {
+	dx, dy := s.B.X-s.A.X, s.B.Y-s.A.Y
+	t := float32(0)
+	if length := dx*dx + dy*dy; length > 0 {
+		t = min(max(((p.X-s.A.X)*dx+(p.Y-s.A.Y)*dy)/length, 0), 1)
+	}
+	return NewVector(s.A.X+t*dx, s.A.Y+t*dy, 0)
+}
+
+// Nearest pair of points on two segments, one on each. Crossing segments
+// meet where they cross.
+func ClosestPoints(s Segment, t Segment) (Vector, Vector) {
+	if segmentsIntersect(s.A, s.B, t.A, t.B) {
+		if p, ok := crossing(s, t); ok {
+			return p, p
+		}
+	}
+
+	// Otherwise one end of a segment is nearest the other segment.
+	candidates := [][2]Vector{
+		{s.A, t.Nearest(s.A)},
+		{s.B, t.Nearest(s.B)},
+		{s.Nearest(t.A), t.A},
+		{s.Nearest(t.B), t.B},
+	}
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if c[0].Distance(&c[1]) < best[0].Distance(&best[1]) {
+			best = c
+		}
+	}
+	return best[0], best[1]
+}
+
+// Edge of an outline nearest to a point, and how far away it is. Reports
+// false for outlines with no corners.
+func NearestEdge(outline []Vector, p Vector) (Segment, float32, bool) {
+	edge, best := Segment{}, float32(math.Inf(1))
+	for i := range outline {
+		s := Segment{A: outline[i], B: outline[(i+1)%len(outline)]}
+		nearest := s.Nearest(p)
+		if d := p.Distance(&nearest); d < best {
+			edge, best = s, d
+		}
+	}
+	return edge, best, len(outline) > 0
+}
+
+// Where the lines through two segments cross. Reports false for parallel
+// segments.
+func crossing(s Segment, t Segment) (Vector, bool) {
+	d := (s.B.X-s.A.X)*(t.B.Y-t.A.Y) - (s.B.Y-s.A.Y)*(t.B.X-t.A.X)
+	if d == 0 {
+		return Vector{}, false
+	}
+	u := ((t.A.X-s.A.X)*(t.B.Y-t.A.Y) - (t.A.Y-s.A.Y)*(t.B.X-t.A.X)) / d
+	return NewVector(s.A.X+u*(s.B.X-s.A.X), s.A.Y+u*(s.B.Y-s.A.Y), 0), true
+}
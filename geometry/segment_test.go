@@ -0,0 +1,41 @@
+package geometry
+
+import "testing"
+
+func TestClosestPoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        Segment
+		t        Segment
+		distance float32
+	}{
+		{"facing edges", Segment{NewVector(0, 0, 0), NewVector(0, 10, 0)}, Segment{NewVector(4, 2, 0), NewVector(4, 20, 0)}, 4},
+		{"crossing edges", Segment{NewVector(0, 0, 0), NewVector(10, 10, 0)}, Segment{NewVector(0, 10, 0), NewVector(10, 0, 0)}, 0},
+		{"point to edge", NewPointSegment(NewVector(5, 5, 0)), Segment{NewVector(0, 8, 0), NewVector(10, 8, 0)}, 3},
+		{"two points", NewPointSegment(NewVector(0, 0, 0)), NewPointSegment(NewVector(3, 4, 0)), 5},
+	}
+
+	for _, test := range tests {
+		a, b := ClosestPoints(test.s, test.t)
+		if got := a.Distance(&b); !near(got, test.distance) {
+			t.Errorf("%s ClosestPoints() %v apart; want %v", test.name, got, test.distance)
+		}
+	}
+
+	// Crossing segments meet where they cross.
+	a, _ := ClosestPoints(Segment{NewVector(0, 0, 0), NewVector(10, 10, 0)}, Segment{NewVector(0, 10, 0), NewVector(10, 0, 0)})
+	if a != NewVector(5, 5, 0) {
+		t.Errorf("ClosestPoints() crossing at %v; want (5, 5)", a)
+	}
+}
+
+func TestNearestEdge(t *testing.T) {
+	box := NewBox(0, 0, 10, 10)
+	edge, d, ok := NearestEdge(box.Outline(), NewVector(12, 4, 0))
+	if !ok || d != 2 || edge.A.X != 10 || edge.B.X != 10 {
+		t.Errorf("NearestEdge() == %v, %v; want the right edge, 2 away", edge, d)
+	}
+	if _, _, ok := NearestEdge(nil, NewVector(0, 0, 0)); ok {
+		t.Errorf("NearestEdge() found an edge of an empty outline")
+	}
+}
@@ -0,0 +1,326 @@
+package geometry
+
+import "math"
+
+// Number of straight segments used to draw a full turn of a curve.
+const CURVE_SEGMENTS = 48
+
+// A closed outline on the plan, such as a bed, tree canopy or pot. Z is
+// ignored. Coordinates are top-left, so angles run clockwise.
+type Shape interface {
+	// Smallest box around the shape.
+	Bounds() Box
+
+	Area() float32
+
+	// Whether a point is inside the shape or on its outline.
+	Contains(p Vector) bool
+
+	// Whether two shapes share any point.
+	Intersects(other Shape) bool
+
+	// Corners of the outline in order. Curves are approximated.
+	Outline() []Vector
+}
+
+// Boxes are shapes too.
+
+func (box *Box) Bounds() Box {
+	return box.Copy()
+}
+
+func (box *Box) Area() float32 {
+	return box.Size.X * box.Size.Y
+}
+
+func (box *Box) Contains(p Vector) bool {
+	return p.X >= box.Location.X && p.X <= box.Location.X+box.Size.X &&
+		p.Y >= box.Location.Y && p.Y <= box.Location.Y+box.Size.Y
+}
+
+func (box *Box) Intersects(other Shape) bool {
+	return intersects(box, other)
+}
+
+func (box *Box) Outline() []Vector {
+	x, y, w, h := box.Location.X, box.Location.Y, box.Size.X, box.Size.Y
+	return []Vector{
+		NewVector(x, y, 0),
+		NewVector(x+w, y, 0),
+		NewVector(x+w, y+h, 0),
+		NewVector(x, y+h, 0),
+	}
+}
+
+// Shape with straight sides. Points may go either way round, and the
+// polygon may be concave, but its sides shouldn't cross.
+type Polygon struct {
+	Points []Vector
+}
+
+func NewPolygon(points ...Vector) *Polygon {
+	return &Polygon{Points: points}
+}
+
+func (p *Polygon) Bounds() Box {
+	return boundsOf(p.Points)
+}
+
+// Shoelace formula.
+func (p *Polygon) Area() float32 {
+	sum := float32(0)
+	for i := range p.Points {
+		a, b := p.Points[i], p.Points[(i+1)%len(p.Points)]
+		sum += a.X*b.Y - b.X*a.Y
+	}
+	return float32(math.Abs(float64(sum))) / 2
+}
+
+// Casts a ray to the right and counts the sides it crosses.
+func (p *Polygon) Contains(point Vector) bool {
+	inside := false
+	for i := range p.Points {
+		a, b := p.Points[i], p.Points[(i+1)%len(p.Points)]
+		if onSegment(point, a, b) {
+			return true
+		}
+		if (a.Y > point.Y) != (b.Y > point.Y) {
+			x := a.X + (point.Y-a.Y)/(b.Y-a.Y)*(b.X-a.X)
+			if point.X < x {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+func (p *Polygon) Intersects(other Shape) bool {
+	return intersects(p, other)
+}
+
+func (p *Polygon) Outline() []Vector {
+	return p.Points
+}
+
+// Ellipse with axes along X and Y. Circles have equal radii.
+type Ellipse struct {
+	Center  Vector
+	RadiusX float32
+	RadiusY float32
+}
+
+func NewEllipse(center Vector, radiusX float32, radiusY float32) *Ellipse {
+	return &Ellipse{Center: center, RadiusX: radiusX, RadiusY: radiusY}
+}
+
+func NewCircle(center Vector, radius float32) *Ellipse {
+	return NewEllipse(center, radius, radius)
+}
+
+func (e *Ellipse) Bounds() Box {
+	return NewBox(e.Center.X-e.RadiusX, e.Center.Y-e.RadiusY, e.RadiusX*2, e.RadiusY*2)
+}
+
+func (e *Ellipse) Area() float32 {
+	return math.Pi * e.RadiusX * e.RadiusY
+}
+
+func (e *Ellipse) Contains(p Vector) bool {
+	r, ok := normalizedRadius(p, e.Center, e.RadiusX, e.RadiusY)
+	return ok && r <= 1
+}
+
+func (e *Ellipse) Intersects(other Shape) bool {
+	return intersects(e, other)
+}
+
+func (e *Ellipse) Outline() []Vector {
+	return curve(e.Center, e.RadiusX, e.RadiusY, 0, 360)
+}
+
+// Curved band, such as a border around a round bed, cut from an ellipse.
+// Angles are in degrees, clockwise from the right. Thickness is a fraction
+// of the radius; 1 gives a pie slice.
+type Arc struct {
+	Center    Vector
+	RadiusX   float32
+	RadiusY   float32
+	Start     float32
+	Sweep     float32
+	Thickness float32
+}
+
+func (a *Arc) Bounds() Box {
+	return boundsOf(a.Outline())
+}
+
+func (a *Arc) Area() float32 {
+	inner := 1 - a.thickness()
+	return float32(math.Pi) * a.RadiusX * a.RadiusY * (1 - inner*inner) * a.sweep() / 360
+}
+
+func (a *Arc) Contains(p Vector) bool {
+	r, ok := normalizedRadius(p, a.Center, a.RadiusX, a.RadiusY)
+	if !ok || r > 1 || r < 1-a.thickness() {
+		return false
+	}
+	if a.sweep() >= 360 || r == 0 {
+		return true
+	}
+
+	// Angle of the point, measured from the start of the arc.
+	angle := math.Atan2(float64((p.Y-a.Center.Y)/a.RadiusY), float64((p.X-a.Center.X)/a.RadiusX)) * 180 / math.Pi
+	offset := math.Mod(angle-float64(a.Start), 360)
+	if offset < 0 {
+		offset += 360
+	}
+	return offset <= float64(a.sweep())
+}
+
+func (a *Arc) Intersects(other Shape) bool {
+	return intersects(a, other)
+}
+
+// Outer edge from start to end, then the inner edge back.
+func (a *Arc) Outline() []Vector {
+	points := curve(a.Center, a.RadiusX, a.RadiusY, a.Start, a.sweep())
+	inner := 1 - a.thickness()
+	if inner <= 0 {
+		return append(points, a.Center.Copy())
+	}
+
+	innerPoints := curve(a.Center, a.RadiusX*inner, a.RadiusY*inner, a.Start, a.sweep())
+	for i := len(innerPoints) - 1; i >= 0; i-- {
+		points = append(points, innerPoints[i])
+	}
+	return points
+}
+
+func (a *Arc) sweep() float32 {
+	return min(max(a.Sweep, 0), 360)
+}
+
+func (a *Arc) thickness() float32 {
+	return min(max(a.Thickness, 0), 1)
+}
+
+// Whether two shapes share any point: their outlines cross, or one lies
+// inside the other. Curves are checked by their approximate outlines.
+func intersects(a Shape, b Shape) bool {
+	boundsA, boundsB := a.Bounds(), b.Bounds()
+	if boundsA.Distance(&boundsB) > 0 {
+		return false
+	}
+
+	outlineA, outlineB := a.Outline(), b.Outline()
+	for i := range outlineA {
+		a1, a2 := outlineA[i], outlineA[(i+1)%len(outlineA)]
+		for j := range outlineB {
+			if segmentsIntersect(a1, a2, outlineB[j], outlineB[(j+1)%len(outlineB)]) {
+				return true
+			}
+		}
+	}
+
+	return (len(outlineA) > 0 && b.Contains(outlineA[0])) ||
+		(len(outlineB) > 0 && a.Contains(outlineB[0]))
+}
+
+// Shortest distance between the outlines of two shapes, or 0 if they
+// intersect.
+func Gap(a Shape, b Shape) float32 {
+	if a.Intersects(b) {
+		return 0
+	}
+
+	outlineA, outlineB := a.Outline(), b.Outline()
+	gap := float32(math.Inf(1))
+	for i := range outlineA {
+		for j := range outlineB {
+			gap = min(gap,
+				segmentDistance(outlineA[i], outlineB[j], outlineB[(j+1)%len(outlineB)]),
+				segmentDistance(outlineB[j], outlineA[i], outlineA[(i+1)%len(outlineA)]),
+			)
+		}
+	}
+	return gap
+}
+
+// Distance from a point to the nearest point of the segment from a to b.
+func segmentDistance(p Vector, a Vector, b Vector) float32 {
+	nearest := Segment{A: a, B: b}.Nearest(p)
+	return p.Distance(&nearest)
+}
+
+// Box around a set of points.
+func boundsOf(points []Vector) Box {
+	if len(points) == 0 {
+		return NewBoxZero()
+	}
+
+	minX, minY := points[0].X, points[0].Y
+	maxX, maxY := minX, minY
+	for _, p := range points {
+		minX, minY = min(minX, p.X), min(minY, p.Y)
+		maxX, maxY = max(maxX, p.X), max(maxY, p.Y)
+	}
+	return NewBox(minX, minY, maxX-minX, maxY-minY)
+}
+
+// Distance of a point from a center, scaled so the ellipse with the given
+// radii is 1. Reports false for flat ellipses.
+func normalizedRadius(p Vector, center Vector, radiusX float32, radiusY float32) (float32, bool) {
+	if radiusX <= 0 || radiusY <= 0 {
+		return 0, false
+	}
+	nx, ny := (p.X-center.X)/radiusX, (p.Y-center.Y)/radiusY
+	return float32(math.Hypot(float64(nx), float64(ny))), true
+}
+
+// Points along an elliptical curve, from start through sweep degrees.
+func curve(center Vector, radiusX float32, radiusY float32, start float32, sweep float32) []Vector {
+	segments := max(1, int(math.Ceil(float64(CURVE_SEGMENTS*sweep/360))))
+	points := []Vector{}
+	for i := 0; i <= segments; i++ {
+		// A full turn ends where it started.
+		if sweep >= 360 && i == segments {
+			break
+		}
+		angle := float64(start+sweep*float32(i)/float32(segments)) * math.Pi / 180
+		points = append(points, NewVector(
+			center.X+radiusX*float32(math.Cos(angle)),
+			center.Y+radiusY*float32(math.Sin(angle)),
+			0,
+		))
+	}
+	return points
+}
+
+// Which side of the line through a and b a point is on: positive, negative,
+// or 0 on the line.
+func orientation(a Vector, b Vector, p Vector) float32 {
+	return (b.X-a.X)*(p.Y-a.Y) - (b.Y-a.Y)*(p.X-a.X)
+}
+
+// Whether a point lies on the segment from a to b.
+func onSegment(p Vector, a Vector, b Vector) bool {
+	const epsilon = 1e-4
+	if math.Abs(float64(orientation(a, b, p))) > epsilon*float64(max(1, a.Distance(&b))) {
+		return false
+	}
+	return p.X >= min(a.X, b.X)-epsilon && p.X <= max(a.X, b.X)+epsilon &&
+		p.Y >= min(a.Y, b.Y)-epsilon && p.Y <= max(a.Y, b.Y)+epsilon
+}
+
+// Whether segments a1-a2 and b1-b2 cross or touch.
+func segmentsIntersect(a1 Vector, a2 Vector, b1 Vector, b2 Vector) bool {
+	d1 := orientation(b1, b2, a1)
+	d2 := orientation(b1, b2, a2)
+	d3 := orientation(a1, a2, b1)
+	d4 := orientation(a1, a2, b2)
+
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	return onSegment(a1, b1, b2) || onSegment(a2, b1, b2) || onSegment(b1, a1, a2) || onSegment(b2, a1, a2)
+}
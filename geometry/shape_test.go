@@ -0,0 +1,115 @@
+package geometry
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a float32, b float32) bool {
+	return math.Abs(float64(a-b)) < 0.5
+}
+
+func TestShapeArea(t *testing.T) {
+	box := NewBox(0, 0, 10, 20)
+	tests := []struct {
+		name  string
+		shape Shape
+		want  float32
+	}{
+		{"box", &box, 200},
+		{"triangle", NewPolygon(NewVector(0, 0, 0), NewVector(10, 0, 0), NewVector(0, 10, 0)), 50},
+		{"circle", NewCircle(NewVector(0, 0, 0), 10), 314.16},
+		{"ring", &Arc{RadiusX: 10, RadiusY: 10, Sweep: 360, Thickness: 0.5}, 235.62},
+		{"quarter", &Arc{RadiusX: 10, RadiusY: 10, Sweep: 90, Thickness: 1}, 78.54},
+	}
+
+	for _, test := range tests {
+		if got := test.shape.Area(); !near(got, test.want) {
+			t.Errorf("%s Area() == %v; want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestShapeContains(t *testing.T) {
+	// An L shape, missing its top right quarter.
+	l := NewPolygon(
+		NewVector(0, 0, 0), NewVector(5, 0, 0), NewVector(5, 5, 0),
+		NewVector(10, 5, 0), NewVector(10, 10, 0), NewVector(0, 10, 0),
+	)
+	arc := &Arc{RadiusX: 10, RadiusY: 10, Start: 0, Sweep: 90, Thickness: 0.5}
+
+	tests := []struct {
+		name  string
+		shape Shape
+		p     Vector
+		want  bool
+	}{
+		{"polygon inside", l, NewVector(2, 8, 0), true},
+		{"polygon notch", l, NewVector(8, 2, 0), false},
+		{"polygon edge", l, NewVector(5, 2, 0), true},
+		{"ellipse inside", NewEllipse(NewVector(0, 0, 0), 10, 5), NewVector(9, 0, 0), true},
+		{"ellipse outside", NewEllipse(NewVector(0, 0, 0), 10, 5), NewVector(0, 6, 0), false},
+		{"arc band", arc, NewVector(5, 5, 0), true},
+		{"arc hole", arc, NewVector(2, 2, 0), false},
+		{"arc outside sweep", arc, NewVector(-5, 5, 0), false},
+	}
+
+	for _, test := range tests {
+		if got := test.shape.Contains(test.p); got != test.want {
+			t.Errorf("%s Contains(%v) == %v; want %v", test.name, test.p, got, test.want)
+		}
+	}
+}
+
+func TestShapeIntersects(t *testing.T) {
+	box := NewBox(0, 0, 10, 10)
+	inner := NewBox(2, 2, 2, 2)
+	tests := []struct {
+		name  string
+		shape Shape
+		want  bool
+	}{
+		{"overlapping circle", NewCircle(NewVector(12, 5, 0), 3), true},
+		{"distant circle", NewCircle(NewVector(20, 5, 0), 3), false},
+		{"circle near corner", NewCircle(NewVector(13, 13, 0), 3), false},
+		{"box inside", &inner, true},
+		{"enclosing circle", NewCircle(NewVector(5, 5, 0), 20), true},
+		{"ring around", &Arc{Center: NewVector(5, 5, 0), RadiusX: 20, RadiusY: 20, Sweep: 360, Thickness: 0.1}, false},
+	}
+
+	for _, test := range tests {
+		if got := box.Intersects(test.shape); got != test.want {
+			t.Errorf("%s Intersects() == %v; want %v", test.name, got, test.want)
+		}
+		if got := test.shape.Intersects(&box); got != test.want {
+			t.Errorf("%s reversed Intersects() == %v; want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestShapeBounds(t *testing.T) {
+	arc := &Arc{Center: NewVector(0, 0, 0), RadiusX: 10, RadiusY: 10, Start: 0, Sweep: 90, Thickness: 1}
+	b := arc.Bounds()
+	if !near(b.Location.X, 0) || !near(b.Location.Y, 0) || !near(b.Size.X, 10) || !near(b.Size.Y, 10) {
+		t.Errorf("Bounds() == %v; want a 10 by 10 box at the origin", b)
+	}
+}
+
+func TestGap(t *testing.T) {
+	box := NewBox(0, 0, 10, 10)
+	tests := []struct {
+		name  string
+		shape Shape
+		want  float32
+	}{
+		{"circle to the right", NewCircle(NewVector(15, 5, 0), 2), 3},
+		{"circle off the corner", NewCircle(NewVector(13, 14, 0), 1), 4},
+		{"overlapping circle", NewCircle(NewVector(10, 5, 0), 2), 0},
+	}
+
+	for _, test := range tests {
+		if got := Gap(&box, test.shape); !near(got, test.want) {
+			t.Errorf("%s Gap() == %v; want %v", test.name, got, test.want)
+		}
+	}
+}
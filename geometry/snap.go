@@ -0,0 +1,101 @@
+package geometry
+
+import "math"
+
+// Places moving boxes on clean measurements: lined up with the edges or
+// centres of other boxes when close to one, otherwise on the grid.
+type Snapper struct {
+	// Spacing of the grid. 0 turns grid snapping off.
+	Grid float32
+
+	// How close an edge or centre must come to a line to snap to it.
+	Tolerance float32
+
+	// Lines to line up with, across and down.
+	XLines []float32
+	YLines []float32
+}
+
+// Line a box was lined up with, for drawing an alignment guide. Vertical
+// guides run down the plan at X = Position, others across it.
+type Guide struct {
+	Vertical bool
+	Position float32
+}
+
+// Creates a new snapper with a grid and no lines.
+func NewSnapper(grid float32, tolerance float32) Snapper {
+	return Snapper{
+		Grid:      grid,
+		Tolerance: tolerance,
+		XLines:    []float32{},
+		YLines:    []float32{},
+	}
+}
+
+// Adds the edges and centre of a box as lines to line up with.
+func (s *Snapper) AddBox(box Box) {
+	center := box.Center()
+	s.XLines = append(s.XLines, box.Location.X, center.X, box.Location.X+box.Size.X)
+	s.YLines = append(s.YLines, box.Location.Y, center.Y, box.Location.Y+box.Size.Y)
+}
+
+// Offset that snaps a moving box, and the guides it lines up with. Any of
+// its edges or its centre may line up; otherwise its top left corner goes
+// on the grid.
+func (s *Snapper) SnapMove(box Box) (Vector, []Guide) {
+	center := box.Center()
+	guides := []Guide{}
+
+	dx, line, ok := s.snap([]float32{box.Location.X, center.X, box.Location.X + box.Size.X}, s.XLines)
+	if ok {
+		guides = append(guides, Guide{Vertical: true, Position: line})
+	}
+	dy, line, ok := s.snap([]float32{box.Location.Y, center.Y, box.Location.Y + box.Size.Y}, s.YLines)
+	if ok {
+		guides = append(guides, Guide{Vertical: false, Position: line})
+	}
+	return NewVector(dx, dy, 0), guides
+}
+
+// Snaps a vertical edge being dragged across.
+func (s *Snapper) SnapX(x float32) (float32, []Guide) {
+	dx, line, ok := s.snap([]float32{x}, s.XLines)
+	if ok {
+		return x + dx, []Guide{{Vertical: true, Position: line}}
+	}
+	return x + dx, []Guide{}
+}
+
+// Snaps a horizontal edge being dragged up or down.
+func (s *Snapper) SnapY(y float32) (float32, []Guide) {
+	dy, line, ok := s.snap([]float32{y}, s.YLines)
+	if ok {
+		return y + dy, []Guide{{Vertical: false, Position: line}}
+	}
+	return y + dy, []Guide{}
+}
+
+// Smallest offset that puts one of the values on a line within tolerance,
+// and that line. Without one, the offset puts the first value on the grid.
+func (s *Snapper) snap(values []float32, lines []float32) (float32, float32, bool) {
+	offset, found, ok := float32(0), float32(0), false
+	for _, v := range values {
+		for _, l := range lines {
+			d := l - v
+			if abs(d) <= s.Tolerance && (!ok || abs(d) < abs(offset)) {
+				offset, found, ok = d, l, true
+			}
+		}
+	}
+	if ok || s.Grid <= 0 {
+		return offset, found, ok
+	}
+
+	snapped := float32(math.Round(float64(values[0]/s.Grid))) * s.Grid
+	return snapped - values[0], 0, false
+}
+
+func abs(v float32) float32 {
+	return float32(math.Abs(float64(v)))
+}
@@ -0,0 +1,44 @@
+package geometry
+
+import "testing"
+
+func TestSnapMove(t *testing.T) {
+	s := NewSnapper(12, 2)
+	s.AddBox(NewBox(100, 100, 20, 40))
+
+	tests := []struct {
+		name   string
+		box    Box
+		want   Vector
+		guides int
+	}{
+		{"onto the grid", NewBox(37.4, 13, 10, 10), NewVector(-1.4, -1, 0), 0},
+		{"left edges lined up", NewBox(101, 50, 10, 10), NewVector(-1, -2, 0), 1},
+		{"centres lined up", NewBox(104.5, 114, 10, 10), NewVector(0.5, 1, 0), 2},
+		{"right edge to left edge", NewBox(89, 200, 10, 10), NewVector(1, 4, 0), 1},
+	}
+
+	for _, test := range tests {
+		got, guides := s.SnapMove(test.box)
+		if !near(got.X, test.want.X) || !near(got.Y, test.want.Y) {
+			t.Errorf("%s SnapMove() == %v; want %v", test.name, got, test.want)
+		}
+		if len(guides) != test.guides {
+			t.Errorf("%s SnapMove() guides == %v; want %d", test.name, guides, test.guides)
+		}
+	}
+}
+
+func TestSnapEdge(t *testing.T) {
+	s := NewSnapper(0, 2)
+	s.AddBox(NewBox(100, 100, 20, 40))
+
+	if got, guides := s.SnapX(119); got != 120 || len(guides) != 1 || !guides[0].Vertical {
+		t.Errorf("SnapX(119) == %v, %v; want 120 with a vertical guide", got, guides)
+	}
+
+	// Without a grid, edges far from any line stay put.
+	if got, guides := s.SnapY(50.3); got != 50.3 || len(guides) != 0 {
+		t.Errorf("SnapY(50.3) == %v, %v; want 50.3 and no guides", got, guides)
+	}
+}
@@ -1,6 +1,10 @@
 package geometry
 
-import "fyne.io/fyne/v2"
+import (
+	"math"
+
+	"fyne.io/fyne/v2"
+)
 
 // 3D vector for storing garden layout data in the abstract.
 // Coordinates are top-left.
@@ -62,3 +66,8 @@ func NewVectorFromSize(size *fyne.Size) Vector {
 		Z: 0,
 	}
 }
+
+// Straight-line distance to another point.
+func (v *Vector) Distance(other *Vector) float32 {
+	return float32(math.Sqrt(float64((v.X-other.X)*(v.X-other.X) + (v.Y-other.Y)*(v.Y-other.Y) + (v.Z-other.Z)*(v.Z-other.Z))))
+}
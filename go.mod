@@ -4,15 +4,16 @@ go 1.22.3
 
 require (
 	fyne.io/fyne v1.4.3
+	fyne.io/fyne/v2 v2.4.5
+	github.com/bcicen/go-units v1.0.5
 	github.com/google/uuid v1.6.0
+	golang.org/x/image v0.11.0
 )
 
 require (
-	fyne.io/fyne/v2 v2.4.5 // indirect
 	fyne.io/systray v1.10.1-0.20231115130155-104f5ef7839e // indirect
 	github.com/Knetic/govaluate v3.0.0+incompatible // indirect
 	github.com/bcicen/bfstree v1.0.0 // indirect
-	github.com/bcicen/go-units v1.0.5 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/fredbi/uri v1.0.0 // indirect
 	github.com/fsnotify/fsnotify v1.6.0 // indirect
@@ -32,7 +33,6 @@ require (
 	github.com/stretchr/testify v1.8.4 // indirect
 	github.com/tevino/abool v1.2.0 // indirect
 	github.com/yuin/goldmark v1.5.5 // indirect
-	golang.org/x/image v0.11.0 // indirect
 	golang.org/x/mobile v0.0.0-20230531173138-3c911d8e3eda // indirect
 	golang.org/x/net v0.17.0 // indirect
 	golang.org/x/sys v0.13.0 // indirect
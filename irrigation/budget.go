@@ -0,0 +1,197 @@
+package irrigation
+
+import (
+	"sort"
+	"time"
+
+	"github.com/bcicen/go-units"
+	"github.com/cpgillem/garden-planner/models"
+)
+
+// Volume of a standard rain barrel, in gallons.
+const RAIN_BARREL_GALLONS = 55
+
+// Name used for features no zone waters, and for features without a plant.
+const (
+	UNZONED  = "Unzoned"
+	NO_PLANT = "No plant"
+)
+
+// Water demand of one feature. Volumes are in US gallons.
+type FeatureWater struct {
+	ID      models.FeatureID
+	Name    string
+	PlantID int
+	Zone    models.IrrigationID
+
+	Weekly     float64
+	Sessions   int
+	PerSession float64
+}
+
+// Weekly demand of a group of features, such as a plant or a zone.
+type WaterTotal struct {
+	Name   string
+	Weekly float64
+}
+
+// Water needed on one day of the week.
+type WaterDay struct {
+	Weekday  time.Weekday
+	Gallons  float64
+	Features []models.FeatureID
+}
+
+type Budget struct {
+	Features []FeatureWater
+	Plants   []WaterTotal
+	Zones    []WaterTotal
+
+	// Monday first.
+	Schedule []WaterDay
+
+	Weekly float64
+}
+
+// Day needing the most water.
+func (b *Budget) BusiestDay() WaterDay {
+	busiest := WaterDay{Weekday: time.Monday, Features: []models.FeatureID{}}
+	for _, day := range b.Schedule {
+		if day.Gallons > busiest.Gallons {
+			busiest = day
+		}
+	}
+	return busiest
+}
+
+// Rain barrels needed to store a week of water.
+func (b *Budget) RainBarrels() int {
+	barrels := int(b.Weekly / RAIN_BARREL_GALLONS)
+	if float64(barrels)*RAIN_BARREL_GALLONS < b.Weekly {
+		barrels++
+	}
+	return barrels
+}
+
+// Adds up the water features need each week, by feature, plant and zone,
+// and spreads each feature's sessions over the week. Features without a
+// frequency are watered once a week, so every gallon is scheduled.
+func WaterBudget(plan *models.Plan, plants []models.Plant) Budget {
+	b := Budget{
+		Features: []FeatureWater{},
+		Plants:   []WaterTotal{},
+		Zones:    []WaterTotal{},
+		Schedule: []WaterDay{},
+	}
+
+	// Zone of each row, from the first lateral watering it.
+	zones := map[models.FeatureID]models.IrrigationID{}
+	for _, id := range sortedTubes(&plan.Irrigation) {
+		t := plan.Irrigation.Tubes[id]
+		if _, ok := zones[t.Feature]; !ok && t.Feature != models.NoFeature && plan.Irrigation.Zones[t.Zone] != nil {
+			zones[t.Feature] = t.Zone
+		}
+	}
+
+	for id, f := range plan.Features {
+		if f == nil {
+			continue
+		}
+		requirement, frequency := WaterNeeds(f)
+		if requirement <= 0 {
+			continue
+		}
+
+		b.Features = append(b.Features, FeatureWater{
+			ID:         id,
+			Name:       f.Name,
+			PlantID:    f.PlantID(),
+			Zone:       zones[id],
+			Weekly:     requirement,
+			Sessions:   frequency,
+			PerSession: requirement / float64(frequency),
+		})
+		b.Weekly += requirement
+	}
+	sort.Slice(b.Features, func(i, j int) bool {
+		if b.Features[i].Name != b.Features[j].Name {
+			return b.Features[i].Name < b.Features[j].Name
+		}
+		return b.Features[i].ID < b.Features[j].ID
+	})
+
+	// Totals by plant and by zone.
+	plantNames := map[int]string{}
+	for _, p := range plants {
+		plantNames[p.ID] = p.Name
+	}
+	byPlant := map[string]float64{}
+	byZone := map[string]float64{}
+	for _, fw := range b.Features {
+		plant, ok := plantNames[fw.PlantID]
+		if !ok {
+			plant = NO_PLANT
+		}
+		byPlant[plant] += fw.Weekly
+
+		zone := UNZONED
+		if z := plan.Irrigation.Zones[fw.Zone]; z != nil {
+			zone = z.Name
+		}
+		byZone[zone] += fw.Weekly
+	}
+	b.Plants = sortedTotals(byPlant)
+	b.Zones = sortedTotals(byZone)
+
+	b.Schedule = schedule(b.Features)
+	return b
+}
+
+// Spreads each feature's sessions evenly over the week, starting on
+// Monday. Features watered more than seven times a week are watered
+// several times on some days.
+func schedule(features []FeatureWater) []WaterDay {
+	days := []WaterDay{}
+	for i := 0; i < 7; i++ {
+		days = append(days, WaterDay{
+			Weekday:  time.Weekday((i + 1) % 7),
+			Features: []models.FeatureID{},
+		})
+	}
+
+	for _, fw := range features {
+		watered := map[int]bool{}
+		for session := 0; session < fw.Sessions; session++ {
+			day := session * 7 / fw.Sessions
+			days[day].Gallons += fw.PerSession
+			if !watered[day] {
+				days[day].Features = append(days[day].Features, fw.ID)
+				watered[day] = true
+			}
+		}
+	}
+	return days
+}
+
+func sortedTotals(totals map[string]float64) []WaterTotal {
+	list := []WaterTotal{}
+	for name, weekly := range totals {
+		list = append(list, WaterTotal{Name: name, Weekly: weekly})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+func sortedTubes(irrigation *models.Irrigation) []models.IrrigationID {
+	ids := []models.IrrigationID{}
+	for id := range irrigation.Tubes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Converts US gallons to another volume unit, such as units.Liter.
+func ConvertGallons(gallons float64, to units.Unit) float64 {
+	return units.NewValue(gallons, units.FluidGallon).MustConvert(to).Float()
+}
@@ -0,0 +1,97 @@
+package irrigation
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/bcicen/go-units"
+	"github.com/cpgillem/garden-planner/models"
+)
+
+func TestWaterBudget(t *testing.T) {
+	plan := testPlan()
+	plants := []models.Plant{{ID: 1, Name: "Potato"}}
+
+	// One more row, with a plant, watered three times a week and outside
+	// any zone.
+	extra := models.NewFeatureID()
+	plan.Features[extra] = &models.Feature{
+		Name: "Potatoes",
+		Properties: map[string]models.PropertyValue{
+			REQUIREMENT_PROPERTY: models.NewDecimalValue(6),
+			FREQUENCY_PROPERTY:   models.NewIntegerValue(3),
+			"plant_id":           models.NewPlantValue(1),
+		},
+	}
+
+	b := WaterBudget(plan, plants)
+	if b.Weekly != 26 {
+		t.Errorf("weekly total == %v; want 26", b.Weekly)
+	}
+
+	zones := map[string]float64{}
+	for _, z := range b.Zones {
+		zones[z.Name] = z.Weekly
+	}
+	if zones["Beds"] != 20 || zones[UNZONED] != 6 {
+		t.Errorf("zones == %v; want Beds 20 and %s 6", zones, UNZONED)
+	}
+
+	plantTotals := map[string]float64{}
+	for _, p := range b.Plants {
+		plantTotals[p.Name] = p.Weekly
+	}
+	if plantTotals["Potato"] != 6 || plantTotals[NO_PLANT] != 20 {
+		t.Errorf("plants == %v; want Potato 6 and %s 20", plantTotals, NO_PLANT)
+	}
+
+	// Rows watered twice go out Monday and Thursday, the potatoes Monday,
+	// Wednesday and Friday.
+	want := map[time.Weekday]float64{
+		time.Monday:    12,
+		time.Wednesday: 2,
+		time.Thursday:  10,
+		time.Friday:    2,
+	}
+	total := 0.0
+	for _, day := range b.Schedule {
+		total += day.Gallons
+		if day.Gallons != want[day.Weekday] {
+			t.Errorf("%s == %v gallons; want %v", day.Weekday, day.Gallons, want[day.Weekday])
+		}
+	}
+	if total != b.Weekly {
+		t.Errorf("schedule totals %v gallons; want %v", total, b.Weekly)
+	}
+
+	if b.BusiestDay().Weekday != time.Monday || b.RainBarrels() != 1 {
+		t.Errorf("busiest day %s, %d barrels; want Monday and 1", b.BusiestDay().Weekday, b.RainBarrels())
+	}
+}
+
+func TestWaterBudgetWithoutFrequency(t *testing.T) {
+	plan := models.NewPlan()
+	plan.Features[models.NewFeatureID()] = &models.Feature{
+		Name: "Squash",
+		Properties: map[string]models.PropertyValue{
+			REQUIREMENT_PROPERTY: models.NewDecimalValue(5),
+		},
+	}
+	plan.Features[models.NewFeatureID()] = nil
+
+	// Watered once a week, on Monday.
+	b := WaterBudget(plan, []models.Plant{})
+	if len(b.Features) != 1 || b.Features[0].Sessions != 1 || b.Features[0].PerSession != 5 {
+		t.Fatalf("features == %+v; want one watered once with 5 gallons", b.Features)
+	}
+	if day := b.BusiestDay(); day.Weekday != time.Monday || day.Gallons != 5 {
+		t.Errorf("busiest day %s with %v gallons; want Monday with 5", day.Weekday, day.Gallons)
+	}
+}
+
+func TestConvertGallons(t *testing.T) {
+	if l := ConvertGallons(1, units.Liter); math.Abs(l-3.785) > 0.001 {
+		t.Errorf("1 gallon == %v L; want 3.785", l)
+	}
+}
@@ -0,0 +1,420 @@
+// Package irrigation works out emitters, flows and parts for the drip
+// irrigation network of a plan, and checks it against the limits of its
+// tubing and water sources.
+//
+// Lengths are in base units, flows in gallons per hour and pressures in PSI.
+package irrigation
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/bcicen/go-units"
+	"github.com/cpgillem/garden-planner/geometry"
+	"github.com/cpgillem/garden-planner/models"
+)
+
+// Feature properties that drive emitters.
+const (
+	SPACING_PROPERTY     = "plant_spacing"
+	REQUIREMENT_PROPERTY = "water_requirement"
+	FREQUENCY_PROPERTY   = "water_frequency"
+)
+
+// Spacing of emitters along rows without a plant spacing, in base units.
+const DEFAULT_EMITTER_SPACING = 12
+
+// Flow ratings of the emitters on sale, smallest first.
+var EmitterRatings = []float32{0.5, 1, 2, 4}
+
+// Limits of the tubing and sources a network is built from.
+type Limits struct {
+	// Most water a tube can carry, and the longest run before pressure
+	// drops too far.
+	MainlineMaxFlow   float32
+	MainlineMaxLength float32
+	LateralMaxFlow    float32
+	LateralMaxLength  float32
+
+	// Pressure range emitters work in. Sources above it need a regulator;
+	// sources below it can't drive the emitters.
+	MinPressure float32
+	MaxPressure float32
+
+	// Inside diameters of the tubing in inches, and its Hazen-Williams
+	// roughness, for the pressure lost to friction.
+	MainlineBore float32
+	LateralBore  float32
+	Roughness    float32
+}
+
+// Limits of 3/4 in. mainline and 1/2 in. lateral tubing, from typical
+// manufacturer tables.
+func DefaultLimits() Limits {
+	return Limits{
+		MainlineMaxFlow:   480,
+		MainlineMaxLength: feetToBase(400),
+		LateralMaxFlow:    220,
+		LateralMaxLength:  feetToBase(200),
+		MinPressure:       10,
+		MaxPressure:       30,
+		MainlineBore:      0.82,
+		LateralBore:       0.6,
+		Roughness:         140,
+	}
+}
+
+// Inside diameter of a kind of tubing, in inches.
+func (l Limits) Bore(kind uint8) float32 {
+	if kind == models.LATERAL {
+		return l.LateralBore
+	}
+	return l.MainlineBore
+}
+
+// An emitter on a lateral, dripping onto a plant row.
+type Emitter struct {
+	Tube     models.IrrigationID
+	Feature  models.FeatureID
+	Location geometry.Vector
+	Flow     float32
+
+	// Pressure left at the emitter after friction, in PSI, even when the
+	// source is too weak. 0 for emitters the source doesn't reach.
+	Pressure float32
+}
+
+type ZoneReport struct {
+	ID       models.IrrigationID
+	Name     string
+	Flow     float32
+	Capacity float32
+	Emitters int
+}
+
+// Something wrong with the network. Tube is NoIrrigation for problems with a
+// zone as a whole.
+type Problem struct {
+	Zone    models.IrrigationID
+	Tube    models.IrrigationID
+	Message string
+}
+
+type Report struct {
+	Zones    []ZoneReport
+	Emitters []Emitter
+	Problems []Problem
+	Parts    []Part
+
+	// Water carried by each tube.
+	Flows map[models.IrrigationID]float32
+}
+
+// Places emitters, totals flows and lists parts for a plan's network.
+func Analyze(plan *models.Plan, limits Limits) Report {
+	r := Report{
+		Zones:    []ZoneReport{},
+		Emitters: []Emitter{},
+		Problems: []Problem{},
+		Parts:    []Part{},
+		Flows:    map[models.IrrigationID]float32{},
+	}
+	parts := newPartCounter()
+	irrigation := &plan.Irrigation
+
+	for _, zoneID := range sortedZones(irrigation) {
+		zone := irrigation.Zones[zoneID]
+		problem := func(tube models.IrrigationID, format string, a ...any) {
+			r.Problems = append(r.Problems, Problem{Zone: zoneID, Tube: tube, Message: zone.Name + ": " + fmt.Sprintf(format, a...)})
+		}
+
+		zr := ZoneReport{ID: zoneID, Name: zone.Name}
+		parts.add(PART_VALVE, 1)
+
+		source := irrigation.Nodes[zone.Source]
+		if source == nil || !source.Source {
+			problem(models.NoIrrigation, "has no water source")
+			r.Zones = append(r.Zones, zr)
+			continue
+		}
+		zr.Capacity = source.Capacity
+
+		// Emitters along every row the zone waters.
+		firstEmitter := len(r.Emitters)
+		emitterFlow := map[models.IrrigationID]float32{}
+		rowLength := map[models.IrrigationID]float32{}
+		for _, tubeID := range irrigation.ZoneTubes(zoneID) {
+			tube := irrigation.Tubes[tubeID]
+			if tube.Kind != models.LATERAL || tube.Feature == models.NoFeature {
+				continue
+			}
+			if plan.Features[tube.Feature] == nil {
+				problem(tubeID, "waters a feature that no longer exists")
+				continue
+			}
+
+			emitters, length, err := RowEmitters(plan, tube.Feature, zone.RunTime)
+			if err != nil {
+				problem(tubeID, "%s", err.Error())
+			}
+			for i := range emitters {
+				emitters[i].Tube = tubeID
+				emitterFlow[tubeID] += emitters[i].Flow
+				parts.addEmitter(emitters[i].Flow)
+			}
+			r.Emitters = append(r.Emitters, emitters...)
+			rowLength[tubeID] = length
+			zr.Emitters += len(emitters)
+		}
+
+		// Carry the water from the source out to the emitters.
+		n := buildNetwork(irrigation, zoneID, zone.Source)
+		for _, tubeID := range n.unreached {
+			problem(tubeID, "tube isn't connected to %s", source.Name)
+		}
+		for _, tubeID := range n.loops {
+			problem(tubeID, "tube makes a loop, so its flow can't be worked out")
+		}
+
+		for _, tubeID := range n.order {
+			r.Flows[tubeID] = n.downstreamFlow(tubeID, emitterFlow)
+		}
+		zr.Flow = n.downstreamFlow(models.NoIrrigation, emitterFlow)
+
+		// Tubing limits.
+		longest := float32(0)
+		for _, tubeID := range n.order {
+			tube := irrigation.Tubes[tubeID]
+			maxFlow, name := limits.MainlineMaxFlow, "mainline"
+			if tube.Kind == models.LATERAL {
+				maxFlow, name = limits.LateralMaxFlow, "lateral"
+			}
+
+			if r.Flows[tubeID] > maxFlow {
+				problem(tubeID, "%s carries %.1f GPH, more than its limit of %.0f GPH", name, r.Flows[tubeID], maxFlow)
+			}
+
+			length := n.length(tubeID) + rowLength[tubeID]
+			if tube.Kind == models.LATERAL && length > limits.LateralMaxLength {
+				problem(tubeID, "lateral runs %.0f ft, more than its limit of %.0f ft", baseToFeet(length), baseToFeet(limits.LateralMaxLength))
+			}
+			longest = max(longest, n.distance[tubeID]+rowLength[tubeID])
+
+			if tube.Kind == models.LATERAL {
+				parts.add(PART_LATERAL, length)
+			} else {
+				parts.add(PART_MAINLINE, length)
+			}
+		}
+		if longest > limits.MainlineMaxLength {
+			problem(models.NoIrrigation, "longest run from the source is %.0f ft, more than the limit of %.0f ft", baseToFeet(longest), baseToFeet(limits.MainlineMaxLength))
+		}
+
+		// Source limits. Zones run one at a time, so each has the whole
+		// source to itself.
+		if zr.Flow > source.Capacity {
+			problem(models.NoIrrigation, "needs %.1f GPH, more than %s supplies (%.0f GPH)", zr.Flow, source.Name, source.Capacity)
+		}
+		low := lowPressure(n, r.Emitters[firstEmitter:], r.Flows, source.Pressure, limits)
+		if source.Pressure < limits.MinPressure {
+			problem(models.NoIrrigation, "%s pressure of %.0f PSI is too low for drip emitters (%.0f PSI)", source.Name, source.Pressure, limits.MinPressure)
+		} else {
+			for _, tubeID := range low {
+				problem(tubeID, "friction leaves emitters less than %.0f PSI", limits.MinPressure)
+			}
+		}
+
+		parts.addFittings(n)
+		r.Zones = append(r.Zones, zr)
+	}
+
+	// Every source in use needs a backflow preventer and filter, and a
+	// regulator if its pressure is too high.
+	for _, sourceID := range usedSources(irrigation) {
+		source := irrigation.Nodes[sourceID]
+		parts.add(PART_BACKFLOW, 1)
+		parts.add(PART_FILTER, 1)
+		if source.Pressure > limits.MaxPressure {
+			parts.add(PART_REGULATOR, 1)
+		}
+	}
+
+	r.Parts = parts.list()
+	return r
+}
+
+// Emitters along a plant row, and the length of the row. Emitters are
+// spaced like the plants down the middle of the row's long side, turned
+// with the row and the features it is nested in. Each delivers the row's
+// water for one watering, divided evenly, over the zone's run time.
+func RowEmitters(plan *models.Plan, id models.FeatureID, runTime float32) ([]Emitter, float32, error) {
+	f := plan.Features[id]
+	box, degrees := plan.Placement(id)
+	center := box.Center()
+
+	// Direction and length of the row.
+	start := geometry.NewVector(box.GetX(), center.Y, 0)
+	step := geometry.NewVector(1, 0, 0)
+	length := box.GetWidth()
+	if box.IsVertical() {
+		start = geometry.NewVector(center.X, box.GetY(), 0)
+		step = geometry.NewVector(0, 1, 0)
+		length = box.GetHeight()
+	}
+
+	spacing := float32(DEFAULT_EMITTER_SPACING)
+	if value, ok := f.Properties[SPACING_PROPERTY]; ok {
+		if d, err := value.Dimension(); err == nil {
+			if v, err := d.Convert(models.BaseLengthUnit); err == nil && v.Float() > 0 {
+				spacing = float32(v.Float())
+			}
+		}
+	}
+
+	// Gallons per watering, from gallons per week.
+	requirement, frequency := WaterNeeds(f)
+	if requirement <= 0 {
+		return []Emitter{}, length, nil
+	}
+	if runTime <= 0 {
+		return []Emitter{}, length, fmt.Errorf("%s needs water, but the zone never runs", f.Name)
+	}
+
+	count := max(1, int(math.Floor(float64(length/spacing))))
+	needed := float32(requirement/float64(frequency)) / (runTime / 60) / float32(count)
+	flow, ok := emitterRating(needed)
+
+	emitters := []Emitter{}
+	for i := 0; i < count; i++ {
+		offset := spacing/2 + float32(i)*spacing
+		location := start.Add(step.Scale(min(offset, length)))
+		emitters = append(emitters, Emitter{
+			Feature:  id,
+			Location: location.Rotate(center, degrees),
+			Flow:     flow,
+		})
+	}
+
+	if !ok {
+		return emitters, length, fmt.Errorf("%s needs %.1f GPH emitters, more than the largest; run the zone longer", f.Name, needed)
+	}
+	return emitters, length, nil
+}
+
+// Gallons a feature needs each week, and how many times a week it is
+// watered. Features that need water without saying how often are watered
+// once a week.
+func WaterNeeds(f *models.Feature) (float64, int) {
+	requirement, frequency := 0.0, 0
+	if value, ok := f.Properties[REQUIREMENT_PROPERTY]; ok {
+		requirement, _ = value.Decimal()
+	}
+	if value, ok := f.Properties[FREQUENCY_PROPERTY]; ok {
+		frequency, _ = value.Integer()
+	}
+	if requirement <= 0 {
+		return 0, 0
+	}
+	return requirement, max(frequency, 1)
+}
+
+// Smallest emitter rating delivering at least the flow needed. Reports false
+// if even the largest is too small.
+func emitterRating(needed float32) (float32, bool) {
+	for _, rating := range EmitterRatings {
+		if rating >= needed {
+			return rating, true
+		}
+	}
+	return EmitterRatings[len(EmitterRatings)-1], false
+}
+
+// Zone IDs ordered by name, then ID, so reports are stable.
+func sortedZones(irrigation *models.Irrigation) []models.IrrigationID {
+	ids := []models.IrrigationID{}
+	for id, z := range irrigation.Zones {
+		if z != nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := irrigation.Zones[ids[i]], irrigation.Zones[ids[j]]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// Sources supplying at least one zone.
+func usedSources(irrigation *models.Irrigation) []models.IrrigationID {
+	used := map[models.IrrigationID]bool{}
+	for _, z := range irrigation.Zones {
+		if z == nil {
+			continue
+		}
+		if source := irrigation.Nodes[z.Source]; source != nil && source.Source {
+			used[z.Source] = true
+		}
+	}
+
+	ids := []models.IrrigationID{}
+	for id := range used {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Works out the pressure at each emitter of a zone, and lists the tubes
+// feeding emitters left below the least pressure they work at. A regulator
+// brings sources down to the top of the range. Water runs along each row
+// from the lateral's far end, dropping off at each emitter in turn.
+func lowPressure(n *network, emitters []Emitter, flows map[models.IrrigationID]float32, sourcePressure float32, limits Limits) []models.IrrigationID {
+	irrigation := n.irrigation
+	nodePressure := n.pressures(min(sourcePressure, limits.MaxPressure), func(id models.IrrigationID) float32 {
+		return FrictionLoss(n.length(id), flows[id], limits.Bore(irrigation.Tubes[id].Kind), limits.Roughness)
+	})
+
+	// Flow still to be delivered along each row.
+	remaining := map[models.IrrigationID]float32{}
+	for _, e := range emitters {
+		remaining[e.Tube] += e.Flow
+	}
+
+	low := []models.IrrigationID{}
+	pressure := map[models.IrrigationID]float32{}
+	last := map[models.IrrigationID]geometry.Vector{}
+	for i := range emitters {
+		e := &emitters[i]
+		node, ok := n.down[e.Tube]
+		if !ok {
+			continue
+		}
+		if _, started := pressure[e.Tube]; !started {
+			pressure[e.Tube] = nodePressure[node]
+			last[e.Tube] = irrigation.Nodes[node].Location
+		}
+
+		from := last[e.Tube]
+		pressure[e.Tube] -= FrictionLoss(from.Distance(&e.Location), remaining[e.Tube], limits.LateralBore, limits.Roughness)
+		remaining[e.Tube] -= e.Flow
+		last[e.Tube] = e.Location
+		e.Pressure = pressure[e.Tube]
+
+		if e.Pressure < limits.MinPressure && (len(low) == 0 || low[len(low)-1] != e.Tube) {
+			low = append(low, e.Tube)
+		}
+	}
+	return low
+}
+
+func feetToBase(feet float64) float32 {
+	return float32(units.NewValue(feet, units.Foot).MustConvert(models.BaseLengthUnit).Float())
+}
+
+func baseToFeet(length float32) float64 {
+	return units.NewValue(float64(length), models.BaseLengthUnit).MustConvert(units.Foot).Float()
+}
@@ -0,0 +1,183 @@
+package irrigation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bcicen/go-units"
+	"github.com/cpgillem/garden-planner/geometry"
+	"github.com/cpgillem/garden-planner/models"
+)
+
+// A source feeding two 10 ft rows through a junction. Each row needs 5
+// gallons a watering, so 0.5 GPH emitters a foot apart over an hour.
+func testPlan() *models.Plan {
+	plan := models.NewPlan()
+	plan.Box = geometry.NewBox(0, 0, 480, 480)
+
+	row := func(y float32) models.FeatureID {
+		id := models.NewFeatureID()
+		plan.Features[id] = &models.Feature{
+			Name: "Row",
+			Box:  geometry.NewBox(24, y, 120, 12),
+			Properties: map[string]models.PropertyValue{
+				SPACING_PROPERTY:     models.NewDimensionValue(units.NewValue(12, units.Inch)),
+				REQUIREMENT_PROPERTY: models.NewDecimalValue(10),
+				FREQUENCY_PROPERTY:   models.NewIntegerValue(2),
+			},
+		}
+		return id
+	}
+	node := func(id models.IrrigationID, x, y float32) {
+		plan.Irrigation.Nodes[id] = &models.IrrigationNode{Name: string(id), Location: geometry.NewVector(x, y, 0)}
+	}
+
+	node("source", 0, 0)
+	plan.Irrigation.Nodes["source"].Source = true
+	plan.Irrigation.Nodes["source"].Capacity = 8
+	plan.Irrigation.Nodes["source"].Pressure = 50
+	node("junction", 0, 120)
+	node("a", 24, 96)
+	node("b", 24, 144)
+
+	plan.Irrigation.Zones["zone"] = &models.IrrigationZone{Name: "Beds", Source: "source", RunTime: 60}
+	plan.Irrigation.Tubes["main"] = &models.Tube{From: "source", To: "junction", Kind: models.MAINLINE, Zone: "zone"}
+	plan.Irrigation.Tubes["lateral a"] = &models.Tube{From: "junction", To: "a", Kind: models.LATERAL, Zone: "zone", Feature: row(96)}
+	plan.Irrigation.Tubes["lateral b"] = &models.Tube{From: "junction", To: "b", Kind: models.LATERAL, Zone: "zone", Feature: row(144)}
+
+	return plan
+}
+
+func TestAnalyzeFlows(t *testing.T) {
+	r := Analyze(testPlan(), DefaultLimits())
+
+	if len(r.Emitters) != 20 {
+		t.Fatalf("got %d emitters; want 20", len(r.Emitters))
+	}
+	for _, e := range r.Emitters {
+		if e.Flow != 0.5 {
+			t.Errorf("emitter flow == %v; want 0.5", e.Flow)
+		}
+	}
+
+	if len(r.Zones) != 1 || r.Zones[0].Flow != 10 {
+		t.Fatalf("zones == %+v; want one zone with 10 GPH", r.Zones)
+	}
+	if r.Flows["main"] != 10 || r.Flows["lateral a"] != 5 {
+		t.Errorf("flows == %v; want 10 on the mainline and 5 on each lateral", r.Flows)
+	}
+
+	// The source only supplies 8 GPH.
+	found := false
+	for _, p := range r.Problems {
+		found = found || (p.Tube == models.NoIrrigation && strings.Contains(p.Message, "more than source supplies"))
+	}
+	if !found {
+		t.Errorf("problems == %+v; want the zone to be over capacity", r.Problems)
+	}
+}
+
+func TestAnalyzeParts(t *testing.T) {
+	r := Analyze(testPlan(), DefaultLimits())
+
+	want := map[string]int{
+		PART_BACKFLOW:     1,
+		PART_FILTER:       1,
+		PART_REGULATOR:    1,
+		PART_VALVE:        1,
+		PART_MAINLINE:     10,
+		PART_LATERAL:      26,
+		PART_TEE:          1,
+		PART_END_CAP:      2,
+		"0.5 GPH emitter": 20,
+	}
+	got := map[string]int{}
+	for _, p := range r.Parts {
+		got[p.Name] = p.Quantity
+	}
+	for name, quantity := range want {
+		if got[name] != quantity {
+			t.Errorf("%s quantity == %d; want %d", name, got[name], quantity)
+		}
+	}
+}
+
+func TestAnalyzeDisconnected(t *testing.T) {
+	plan := testPlan()
+	plan.Irrigation.Nodes["x"] = &models.IrrigationNode{Name: "x", Location: geometry.NewVector(400, 400, 0)}
+	plan.Irrigation.Nodes["y"] = &models.IrrigationNode{Name: "y", Location: geometry.NewVector(400, 300, 0)}
+	plan.Irrigation.Tubes["stray"] = &models.Tube{From: "x", To: "y", Kind: models.MAINLINE, Zone: "zone"}
+
+	r := Analyze(plan, DefaultLimits())
+	for _, p := range r.Problems {
+		if p.Tube == "stray" {
+			return
+		}
+	}
+	t.Errorf("problems == %+v; want the stray tube flagged", r.Problems)
+}
+
+func TestAnalyzePressure(t *testing.T) {
+	// The regulated 30 PSI barely drops through tubing this short.
+	r := Analyze(testPlan(), DefaultLimits())
+	for _, e := range r.Emitters {
+		if e.Pressure < 29 || e.Pressure > 30 {
+			t.Errorf("emitter pressure == %v; want just under 30 PSI", e.Pressure)
+		}
+	}
+	for _, p := range r.Problems {
+		if strings.Contains(p.Message, "friction") {
+			t.Errorf("unexpected problem %q", p.Message)
+		}
+	}
+
+	// Narrow laterals lose more along each row, leaving the far emitters
+	// short of pressure.
+	plan := testPlan()
+	plan.Irrigation.Nodes["source"].Pressure = 12
+	limits := DefaultLimits()
+	limits.LateralBore = 0.1
+	r = Analyze(plan, limits)
+
+	low := map[models.IrrigationID]bool{}
+	for _, p := range r.Problems {
+		if strings.Contains(p.Message, "friction") {
+			low[p.Tube] = true
+		}
+	}
+	if !low["lateral a"] || !low["lateral b"] || len(low) != 2 {
+		t.Errorf("tubes short of pressure == %v; want both laterals", low)
+	}
+	first, last := r.Emitters[0], r.Emitters[9]
+	if first.Tube != last.Tube || last.Pressure >= first.Pressure {
+		t.Errorf("pressure along the row goes from %v to %v PSI; want it to drop", first.Pressure, last.Pressure)
+	}
+}
+
+func TestAnalyzeLowSourcePressure(t *testing.T) {
+	// Emitters still show what little pressure reaches them, and the source
+	// is blamed rather than every tube.
+	plan := testPlan()
+	plan.Irrigation.Nodes["source"].Pressure = 5
+	r := Analyze(plan, DefaultLimits())
+	for _, e := range r.Emitters {
+		if e.Pressure < 4 || e.Pressure > 5 {
+			t.Errorf("emitter pressure == %v; want just under 5 PSI", e.Pressure)
+		}
+	}
+	for _, p := range r.Problems {
+		if strings.Contains(p.Message, "friction") {
+			t.Errorf("unexpected problem %q", p.Message)
+		}
+	}
+}
+
+func TestFrictionLoss(t *testing.T) {
+	// 4 GPM through 100 ft of 3/4 in. tubing loses about 1.6 PSI.
+	if got := FrictionLoss(feetToBase(100), 240, 0.82, 140); got < 1.5 || got > 1.7 {
+		t.Errorf("FrictionLoss() == %v PSI; want about 1.6", got)
+	}
+	if got := FrictionLoss(feetToBase(100), 0, 0.82, 140); got != 0 {
+		t.Errorf("FrictionLoss() == %v PSI without flow; want 0", got)
+	}
+}
@@ -0,0 +1,161 @@
+package irrigation
+
+import (
+	"math"
+
+	"github.com/cpgillem/garden-planner/models"
+)
+
+// The tubes of one zone as a tree, walked outwards from its source.
+type network struct {
+	irrigation *models.Irrigation
+	source     models.IrrigationID
+
+	// Tubes reached from the source, nearest first.
+	order []models.IrrigationID
+
+	// Nodes at the upstream and downstream ends of each tube in the tree.
+	// Tubes closing a loop have no downstream node.
+	up   map[models.IrrigationID]models.IrrigationID
+	down map[models.IrrigationID]models.IrrigationID
+
+	// Tubes leaving each node away from the source.
+	children map[models.IrrigationID][]models.IrrigationID
+
+	// Distance along the tubes from the source to the far end of each tube.
+	distance map[models.IrrigationID]float32
+
+	// Number of tubes meeting at each node.
+	degree map[models.IrrigationID]int
+
+	unreached []models.IrrigationID
+	loops     []models.IrrigationID
+}
+
+func buildNetwork(irrigation *models.Irrigation, zone models.IrrigationID, source models.IrrigationID) *network {
+	n := &network{
+		irrigation: irrigation,
+		source:     source,
+		order:      []models.IrrigationID{},
+		up:         map[models.IrrigationID]models.IrrigationID{},
+		down:       map[models.IrrigationID]models.IrrigationID{},
+		children:   map[models.IrrigationID][]models.IrrigationID{},
+		distance:   map[models.IrrigationID]float32{},
+		degree:     map[models.IrrigationID]int{},
+		unreached:  []models.IrrigationID{},
+		loops:      []models.IrrigationID{},
+	}
+
+	tubes := irrigation.ZoneTubes(zone)
+
+	adjacent := map[models.IrrigationID][]models.IrrigationID{}
+	for _, id := range tubes {
+		t := irrigation.Tubes[id]
+		if irrigation.Nodes[t.From] == nil || irrigation.Nodes[t.To] == nil {
+			continue
+		}
+		adjacent[t.From] = append(adjacent[t.From], id)
+		adjacent[t.To] = append(adjacent[t.To], id)
+		n.degree[t.From]++
+		n.degree[t.To]++
+	}
+
+	// Breadth first, so every tube hangs off the shortest route to it.
+	used := map[models.IrrigationID]bool{}
+	visited := map[models.IrrigationID]bool{source: true}
+	nodeDistance := map[models.IrrigationID]float32{source: 0}
+	queue := []models.IrrigationID{source}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for _, id := range adjacent[node] {
+			if used[id] {
+				continue
+			}
+			used[id] = true
+
+			t := irrigation.Tubes[id]
+			other := t.To
+			if other == node {
+				other = t.From
+			}
+
+			n.order = append(n.order, id)
+			n.up[id] = node
+			n.children[node] = append(n.children[node], id)
+			n.distance[id] = nodeDistance[node] + n.length(id)
+
+			if visited[other] {
+				n.loops = append(n.loops, id)
+				continue
+			}
+			visited[other] = true
+			nodeDistance[other] = n.distance[id]
+			n.down[id] = other
+			queue = append(queue, other)
+		}
+	}
+
+	for _, id := range tubes {
+		if !used[id] {
+			n.unreached = append(n.unreached, id)
+		}
+	}
+
+	return n
+}
+
+// Length of a tube between its nodes.
+func (n *network) length(id models.IrrigationID) float32 {
+	t := n.irrigation.Tubes[id]
+	from, to := n.irrigation.Nodes[t.From], n.irrigation.Nodes[t.To]
+	if from == nil || to == nil {
+		return 0
+	}
+	return from.Location.Distance(&to.Location)
+}
+
+// Flow through a tube: its own emitters and everything beyond it.
+// NoIrrigation gives the flow out of the source.
+func (n *network) downstreamFlow(id models.IrrigationID, emitterFlow map[models.IrrigationID]float32) float32 {
+	if id == models.NoIrrigation {
+		flow := float32(0)
+		for _, child := range n.children[n.source] {
+			flow += n.downstreamFlow(child, emitterFlow)
+		}
+		return flow
+	}
+
+	flow := emitterFlow[id]
+	if node, ok := n.down[id]; ok {
+		for _, child := range n.children[node] {
+			flow += n.downstreamFlow(child, emitterFlow)
+		}
+	}
+	return flow
+}
+
+// Pressure at each node reached from the source, in PSI. Water leaves the
+// source at a pressure and loses some to friction along each tube.
+func (n *network) pressures(start float32, loss func(id models.IrrigationID) float32) map[models.IrrigationID]float32 {
+	pressure := map[models.IrrigationID]float32{n.source: start}
+	for _, id := range n.order {
+		if node, ok := n.down[id]; ok {
+			pressure[node] = pressure[n.up[id]] - loss(id)
+		}
+	}
+	return pressure
+}
+
+// Pressure lost to friction, in PSI, along a length of tubing in base units
+// carrying a flow in gallons per hour, by the Hazen-Williams formula. Bore
+// is the inside diameter in inches, and roughness the formula's C factor.
+func FrictionLoss(length float32, flow float32, bore float32, roughness float32) float32 {
+	if length <= 0 || flow <= 0 || bore <= 0 || roughness <= 0 {
+		return 0
+	}
+	gpm := float64(flow) / 60
+	perFoot := 4.52 * math.Pow(gpm/float64(roughness), 1.852) / math.Pow(float64(bore), 4.8704)
+	return float32(perFoot * baseToFeet(length))
+}
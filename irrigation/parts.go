@@ -0,0 +1,113 @@
+package irrigation
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Parts that go into a network.
+const (
+	PART_BACKFLOW  = "Backflow preventer"
+	PART_FILTER    = "Filter"
+	PART_REGULATOR = "Pressure regulator"
+	PART_VALVE     = "Zone valve"
+	PART_MAINLINE  = "3/4 in. mainline tubing"
+	PART_LATERAL   = "1/2 in. lateral tubing"
+	PART_TEE       = "Tee"
+	PART_END_CAP   = "End cap"
+)
+
+// Order parts are listed in, from the source outwards. Emitters follow.
+var partOrder = []string{
+	PART_BACKFLOW,
+	PART_FILTER,
+	PART_REGULATOR,
+	PART_VALVE,
+	PART_MAINLINE,
+	PART_LATERAL,
+	PART_TEE,
+	PART_END_CAP,
+}
+
+// A line of the parts list. Tubing is counted in whole feet, everything else
+// in pieces.
+type Part struct {
+	Name     string
+	Quantity int
+	Unit     string
+}
+
+func (p Part) String() string {
+	if p.Unit == "" {
+		return fmt.Sprintf("%d × %s", p.Quantity, p.Name)
+	}
+	return fmt.Sprintf("%d %s %s", p.Quantity, p.Unit, p.Name)
+}
+
+type partCounter struct {
+	pieces   map[string]int
+	lengths  map[string]float32
+	emitters map[float32]int
+}
+
+func newPartCounter() *partCounter {
+	return &partCounter{
+		pieces:   map[string]int{},
+		lengths:  map[string]float32{},
+		emitters: map[float32]int{},
+	}
+}
+
+// Adds pieces of a part, or a length of tubing in base units.
+func (c *partCounter) add(name string, quantity float32) {
+	if name == PART_MAINLINE || name == PART_LATERAL {
+		c.lengths[name] += quantity
+		return
+	}
+	c.pieces[name] += int(quantity)
+}
+
+func (c *partCounter) addEmitter(flow float32) {
+	c.emitters[flow]++
+}
+
+// Tees where tubes branch and caps where they end.
+func (c *partCounter) addFittings(n *network) {
+	for node, degree := range n.degree {
+		if node == n.source {
+			continue
+		}
+		if degree >= 3 {
+			c.add(PART_TEE, float32(degree-2))
+		} else if degree == 1 {
+			c.add(PART_END_CAP, 1)
+		}
+	}
+}
+
+func (c *partCounter) list() []Part {
+	parts := []Part{}
+	for _, name := range partOrder {
+		if length, ok := c.lengths[name]; ok && length > 0 {
+			parts = append(parts, Part{
+				Name:     name,
+				Quantity: int(math.Ceil(baseToFeet(length))),
+				Unit:     "ft",
+			})
+		}
+		if count := c.pieces[name]; count > 0 {
+			parts = append(parts, Part{Name: name, Quantity: count})
+		}
+	}
+
+	for _, rating := range EmitterRatings {
+		if count := c.emitters[rating]; count > 0 {
+			parts = append(parts, Part{
+				Name:     strconv.FormatFloat(float64(rating), 'f', -1, 32) + " GPH emitter",
+				Quantity: count,
+			})
+		}
+	}
+	return parts
+}
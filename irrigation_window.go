@@ -0,0 +1,709 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/bcicen/go-units"
+	"github.com/cpgillem/garden-planner/geometry"
+	"github.com/cpgillem/garden-planner/irrigation"
+	"github.com/cpgillem/garden-planner/models"
+	"github.com/cpgillem/garden-planner/ui"
+)
+
+// Names of tube kinds, indexed by kind.
+var tubeKindNames = []string{"Mainline", "Lateral"}
+
+// Row option for laterals that don't water a row.
+const noRow = "(none)"
+
+// Length of a new tube whose end has nowhere else to go, in base units.
+const NEW_TUBE_LENGTH = 24
+
+// Window for designing the drip irrigation network of the open plan: water
+// sources and junctions, zones, and the tubes between them. A report tab
+// checks flows and limits and lists the parts to buy.
+type IrrigationWindow struct {
+	instance *GardenPlanner
+	window   fyne.Window
+
+	// Data
+	nodeIDs      []models.IrrigationID
+	zoneIDs      []models.IrrigationID
+	tubeIDs      []models.IrrigationID
+	selectedNode models.IrrigationID
+	selectedZone models.IrrigationID
+	selectedTube models.IrrigationID
+
+	// Select options by displayed name.
+	nodeOptions map[string]models.IrrigationID
+	zoneOptions map[string]models.IrrigationID
+	rowOptions  map[string]models.FeatureID
+
+	// Set while forms are filled in, so their events don't write back.
+	loading bool
+
+	// Node Widgets
+	nodeList     *widget.List
+	nodeName     *widget.Entry
+	nodeX        *ui.DimensionEntry
+	nodeY        *ui.DimensionEntry
+	nodeSource   *widget.Check
+	nodeCapacity *widget.Entry
+	nodePressure *widget.Entry
+
+	// Zone Widgets
+	zoneList    *widget.List
+	zoneName    *widget.Entry
+	zoneSource  *widget.Select
+	zoneRunTime *widget.Entry
+
+	// Tube Widgets
+	tubeList *widget.List
+	tubeFrom *widget.Select
+	tubeTo   *widget.Select
+	tubeKind *widget.Select
+	tubeZone *widget.Select
+	tubeRow  *widget.Select
+
+	// Report Widgets
+	reportLabel *widget.Label
+}
+
+func NewIrrigationWindow(instance *GardenPlanner) IrrigationWindow {
+	w := IrrigationWindow{
+		instance:     instance,
+		window:       instance.App.NewWindow("Irrigation"),
+		nodeIDs:      []models.IrrigationID{},
+		zoneIDs:      []models.IrrigationID{},
+		tubeIDs:      []models.IrrigationID{},
+		nodeOptions:  map[string]models.IrrigationID{},
+		zoneOptions:  map[string]models.IrrigationID{},
+		rowOptions:   map[string]models.FeatureID{},
+		nodeName:     widget.NewEntry(),
+		nodeX:        ui.NewDimensionEntry(units.NewValue(0, models.BaseLengthUnit), instance.Formatter),
+		nodeY:        ui.NewDimensionEntry(units.NewValue(0, models.BaseLengthUnit), instance.Formatter),
+		nodeSource:   widget.NewCheck("Water source", nil),
+		nodeCapacity: widget.NewEntry(),
+		nodePressure: widget.NewEntry(),
+		zoneName:     widget.NewEntry(),
+		zoneSource:   widget.NewSelect([]string{}, nil),
+		zoneRunTime:  widget.NewEntry(),
+		tubeFrom:     widget.NewSelect([]string{}, nil),
+		tubeTo:       widget.NewSelect([]string{}, nil),
+		tubeKind:     widget.NewSelect(tubeKindNames, nil),
+		tubeZone:     widget.NewSelect([]string{}, nil),
+		tubeRow:      widget.NewSelect([]string{}, nil),
+		reportLabel:  widget.NewLabel(""),
+	}
+
+	w.window.SetCloseIntercept(w.window.Hide)
+	w.window.Resize(fyne.NewSize(700, 450))
+
+	return w
+}
+
+// Builds the window contents. Separate from NewIrrigationWindow so callbacks
+// refer to the window stored in the app rather than a copy.
+func (w *IrrigationWindow) Setup() {
+	showError := func(err error) {
+		dialog.ShowError(err, w.window)
+	}
+
+	// Nodes
+	w.nodeList = w.newIDList(&w.nodeIDs, func(id models.IrrigationID) string {
+		n := w.irrigation().Nodes[id]
+		if n.Source {
+			return n.Name + " (source)"
+		}
+		return n.Name
+	}, func(id models.IrrigationID) {
+		w.selectedNode = id
+		w.showNode()
+	})
+	w.nodeName.OnSubmitted = func(s string) {
+		w.updateNode(func(n *models.IrrigationNode) error {
+			n.Name = strings.TrimSpace(s)
+			return nil
+		})
+	}
+	w.nodeX.OnDimensionError = showError
+	w.nodeX.OnValueChanged = func(v units.Value) {
+		w.updateNode(func(n *models.IrrigationNode) error {
+			n.Location.X = float32(v.MustConvert(models.BaseLengthUnit).Float())
+			return nil
+		})
+	}
+	w.nodeY.OnDimensionError = showError
+	w.nodeY.OnValueChanged = func(v units.Value) {
+		w.updateNode(func(n *models.IrrigationNode) error {
+			n.Location.Y = float32(v.MustConvert(models.BaseLengthUnit).Float())
+			return nil
+		})
+	}
+	w.nodeSource.OnChanged = func(b bool) {
+		w.updateNode(func(n *models.IrrigationNode) error {
+			n.Source = b
+			return nil
+		})
+	}
+	w.nodeCapacity.OnSubmitted = func(s string) {
+		w.updateNode(func(n *models.IrrigationNode) error {
+			return parsePositive(s, "capacity", &n.Capacity)
+		})
+	}
+	w.nodePressure.OnSubmitted = func(s string) {
+		w.updateNode(func(n *models.IrrigationNode) error {
+			return parsePositive(s, "pressure", &n.Pressure)
+		})
+	}
+	nodeForm := container.New(
+		layout.NewFormLayout(),
+		widget.NewLabel("Name"), w.nodeName,
+		widget.NewLabel("X"), w.nodeX,
+		widget.NewLabel("Y"), w.nodeY,
+		widget.NewLabel(""), w.nodeSource,
+		widget.NewLabel("Capacity (GPH)"), w.nodeCapacity,
+		widget.NewLabel("Pressure (PSI)"), w.nodePressure,
+	)
+	nodeTools := widget.NewToolbar(
+		widget.NewToolbarAction(theme.ContentAddIcon(), func() { w.AddNode(false) }),
+		widget.NewToolbarAction(theme.UploadIcon(), func() { w.AddNode(true) }),
+		widget.NewToolbarAction(theme.DeleteIcon(), w.RemoveNode),
+	)
+
+	// Zones
+	w.zoneList = w.newIDList(&w.zoneIDs, func(id models.IrrigationID) string {
+		return w.irrigation().Zones[id].Name
+	}, func(id models.IrrigationID) {
+		w.selectedZone = id
+		w.showZone()
+	})
+	w.zoneName.OnSubmitted = func(s string) {
+		w.updateZone(func(z *models.IrrigationZone) error {
+			z.Name = strings.TrimSpace(s)
+			return nil
+		})
+	}
+	w.zoneSource.OnChanged = func(s string) {
+		w.updateZone(func(z *models.IrrigationZone) error {
+			z.Source = w.nodeOptions[s]
+			return nil
+		})
+	}
+	w.zoneRunTime.OnSubmitted = func(s string) {
+		w.updateZone(func(z *models.IrrigationZone) error {
+			return parsePositive(s, "run time", &z.RunTime)
+		})
+	}
+	zoneForm := container.New(
+		layout.NewFormLayout(),
+		widget.NewLabel("Name"), w.zoneName,
+		widget.NewLabel("Source"), w.zoneSource,
+		widget.NewLabel("Run Time (min)"), w.zoneRunTime,
+	)
+	zoneTools := widget.NewToolbar(
+		widget.NewToolbarAction(theme.ContentAddIcon(), w.AddZone),
+		widget.NewToolbarAction(theme.DeleteIcon(), w.RemoveZone),
+	)
+
+	// Tubes
+	w.tubeList = w.newIDList(&w.tubeIDs, w.describeTube, func(id models.IrrigationID) {
+		w.selectedTube = id
+		w.showTube()
+	})
+	w.tubeFrom.OnChanged = func(s string) {
+		w.updateTube(func(t *models.Tube) error {
+			t.From = w.nodeOptions[s]
+			return nil
+		})
+	}
+	w.tubeTo.OnChanged = func(s string) {
+		w.updateTube(func(t *models.Tube) error {
+			t.To = w.nodeOptions[s]
+			return nil
+		})
+	}
+	w.tubeKind.OnChanged = func(s string) {
+		w.updateTube(func(t *models.Tube) error {
+			for kind, name := range tubeKindNames {
+				if name == s {
+					t.Kind = uint8(kind)
+				}
+			}
+			return nil
+		})
+	}
+	w.tubeZone.OnChanged = func(s string) {
+		w.updateTube(func(t *models.Tube) error {
+			t.Zone = w.zoneOptions[s]
+			return nil
+		})
+	}
+	w.tubeRow.OnChanged = func(s string) {
+		w.updateTube(func(t *models.Tube) error {
+			t.Feature = w.rowOptions[s]
+			if t.Feature != models.NoFeature {
+				t.Kind = models.LATERAL
+			}
+			return nil
+		})
+	}
+	tubeForm := container.New(
+		layout.NewFormLayout(),
+		widget.NewLabel("From"), w.tubeFrom,
+		widget.NewLabel("To"), w.tubeTo,
+		widget.NewLabel("Kind"), w.tubeKind,
+		widget.NewLabel("Zone"), w.tubeZone,
+		widget.NewLabel("Waters Row"), w.tubeRow,
+	)
+	tubeTools := widget.NewToolbar(
+		widget.NewToolbarAction(theme.ContentAddIcon(), w.AddTube),
+		widget.NewToolbarAction(theme.DeleteIcon(), w.RemoveTube),
+	)
+
+	w.reportLabel.Wrapping = fyne.TextWrapWord
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem("Nodes", editorTab(nodeTools, w.nodeList, nodeForm)),
+		container.NewTabItem("Zones", editorTab(zoneTools, w.zoneList, zoneForm)),
+		container.NewTabItem("Tubes", editorTab(tubeTools, w.tubeList, tubeForm)),
+		container.NewTabItem("Report", container.NewVScroll(w.reportLabel)),
+	)
+	w.window.SetContent(tabs)
+}
+
+func (w *IrrigationWindow) Show() {
+	w.Refresh()
+	w.window.Show()
+}
+
+// Reloads lists, forms and the report from the plan, e.g. after an undo.
+func (w *IrrigationWindow) Refresh() {
+	irr := w.irrigation()
+
+	w.nodeIDs = sortedIDs(irr.Nodes, func(n *models.IrrigationNode) string { return n.Name })
+	w.zoneIDs = sortedIDs(irr.Zones, func(z *models.IrrigationZone) string { return z.Name })
+	w.tubeIDs = sortedIDs(irr.Tubes, func(t *models.Tube) string { return "" })
+	w.nodeList.Refresh()
+	w.zoneList.Refresh()
+	w.tubeList.Refresh()
+
+	// Options for the selects.
+	w.nodeOptions = map[string]models.IrrigationID{}
+	nodeNames := []string{}
+	sourceNames := []string{}
+	for _, id := range w.nodeIDs {
+		name := uniqueName(irr.Nodes[id].Name, w.nodeOptions)
+		w.nodeOptions[name] = id
+		nodeNames = append(nodeNames, name)
+		if irr.Nodes[id].Source {
+			sourceNames = append(sourceNames, name)
+		}
+	}
+	w.zoneOptions = map[string]models.IrrigationID{}
+	zoneNames := []string{}
+	for _, id := range w.zoneIDs {
+		name := uniqueName(irr.Zones[id].Name, w.zoneOptions)
+		w.zoneOptions[name] = id
+		zoneNames = append(zoneNames, name)
+	}
+	w.rowOptions = map[string]models.FeatureID{noRow: models.NoFeature}
+	rowNames := []string{noRow}
+	plan := w.instance.PlanController.Plan
+	for _, id := range sortedIDs(plan.Features, func(f *models.Feature) string { return f.Name }) {
+		if _, ok := plan.Features[id].Properties[irrigation.REQUIREMENT_PROPERTY]; !ok {
+			continue
+		}
+		name := uniqueName(plan.Features[id].Name, w.rowOptions)
+		w.rowOptions[name] = id
+		rowNames = append(rowNames, name)
+	}
+
+	w.zoneSource.Options = sourceNames
+	w.tubeFrom.Options = nodeNames
+	w.tubeTo.Options = nodeNames
+	w.tubeZone.Options = zoneNames
+	w.tubeRow.Options = rowNames
+
+	w.showNode()
+	w.showZone()
+	w.showTube()
+	w.showReport()
+}
+
+// Adds a junction, or a water source, at the selected feature or the
+// middle of the plan.
+func (w *IrrigationWindow) AddNode(source bool) {
+	node := &models.IrrigationNode{Name: "Junction", Location: w.newNodeLocation()}
+	if source {
+		node.Name = "Spigot"
+		node.Source = true
+		node.Capacity = 240
+		node.Pressure = 40
+	}
+
+	id := models.NewIrrigationID()
+	w.selectedNode = id
+	w.update(func(irr *models.Irrigation) {
+		irr.Nodes[id] = node
+	})
+}
+
+// Removes the selected node and the tubes connected to it.
+func (w *IrrigationWindow) RemoveNode() {
+	id := w.selectedNode
+	w.update(func(irr *models.Irrigation) {
+		delete(irr.Nodes, id)
+		for tubeID, t := range irr.Tubes {
+			if t.From == id || t.To == id {
+				delete(irr.Tubes, tubeID)
+			}
+		}
+		for _, z := range irr.Zones {
+			if z.Source == id {
+				z.Source = models.NoIrrigation
+			}
+		}
+	})
+}
+
+// Adds a zone fed by the first water source.
+func (w *IrrigationWindow) AddZone() {
+	zone := &models.IrrigationZone{
+		Name:    fmt.Sprintf("Zone %d", len(w.zoneIDs)+1),
+		RunTime: 30,
+	}
+	for _, id := range w.nodeIDs {
+		if w.irrigation().Nodes[id].Source {
+			zone.Source = id
+			break
+		}
+	}
+
+	id := models.NewIrrigationID()
+	w.selectedZone = id
+	w.update(func(irr *models.Irrigation) {
+		irr.Zones[id] = zone
+	})
+}
+
+// Removes the selected zone and its tubes.
+func (w *IrrigationWindow) RemoveZone() {
+	id := w.selectedZone
+	w.update(func(irr *models.Irrigation) {
+		delete(irr.Zones, id)
+		for tubeID, t := range irr.Tubes {
+			if t.Zone == id {
+				delete(irr.Tubes, tubeID)
+			}
+		}
+	})
+}
+
+// Adds a tube from the selected node, in the selected zone, ending at a
+// new junction at the selected feature. The junction can be moved, or the
+// tube led to another node, afterwards.
+func (w *IrrigationWindow) AddTube() {
+	if len(w.zoneIDs) == 0 {
+		dialog.ShowError(fmt.Errorf("add a zone for the tube first"), w.window)
+		return
+	}
+	from := w.irrigation().Nodes[w.selectedNode]
+	if from == nil {
+		dialog.ShowError(fmt.Errorf("select the node the tube starts from first"), w.window)
+		return
+	}
+
+	// Without a feature to lead to, the tube runs off to the side.
+	end := &models.IrrigationNode{Name: "Junction", Location: w.newNodeLocation()}
+	if end.Location == from.Location {
+		end.Location.X += NEW_TUBE_LENGTH
+	}
+	endID := models.NewIrrigationID()
+
+	tube := &models.Tube{
+		From: w.selectedNode,
+		To:   endID,
+		Kind: models.MAINLINE,
+		Zone: w.selectedZone,
+	}
+	if w.irrigation().Zones[tube.Zone] == nil {
+		tube.Zone = w.zoneIDs[0]
+	}
+
+	id := models.NewIrrigationID()
+	w.selectedTube = id
+	w.update(func(irr *models.Irrigation) {
+		irr.Nodes[endID] = end
+		irr.Tubes[id] = tube
+	})
+}
+
+func (w *IrrigationWindow) RemoveTube() {
+	id := w.selectedTube
+	w.update(func(irr *models.Irrigation) {
+		delete(irr.Tubes, id)
+	})
+}
+
+// Where new nodes go: at the selected feature, or the middle of the plan.
+func (w *IrrigationWindow) newNodeLocation() geometry.Vector {
+	c := &w.instance.PlanController
+	if !c.HasSelection() {
+		return c.Plan.Box.Center()
+	}
+	box, _ := c.Plan.Placement(c.GetSelectedFeature())
+	return box.Center()
+}
+
+func (w *IrrigationWindow) irrigation() *models.Irrigation {
+	return &w.instance.PlanController.Plan.Irrigation
+}
+
+// Changes a copy of the network and hands it to the controller, so the
+// change can be undone.
+func (w *IrrigationWindow) update(change func(irr *models.Irrigation)) {
+	irr := w.irrigation().Copy()
+	change(&irr)
+	w.instance.PlanController.SetIrrigation(irr)
+}
+
+func (w *IrrigationWindow) updateNode(change func(n *models.IrrigationNode) error) {
+	w.updateItem(w.irrigation().Nodes[w.selectedNode] != nil, func(irr *models.Irrigation) error {
+		return change(irr.Nodes[w.selectedNode])
+	})
+}
+
+func (w *IrrigationWindow) updateZone(change func(z *models.IrrigationZone) error) {
+	w.updateItem(w.irrigation().Zones[w.selectedZone] != nil, func(irr *models.Irrigation) error {
+		return change(irr.Zones[w.selectedZone])
+	})
+}
+
+func (w *IrrigationWindow) updateTube(change func(t *models.Tube) error) {
+	w.updateItem(w.irrigation().Tubes[w.selectedTube] != nil, func(irr *models.Irrigation) error {
+		return change(irr.Tubes[w.selectedTube])
+	})
+}
+
+// Applies a change from a form, unless the form is being filled in. Shows
+// an error and restores the form if the change is rejected.
+func (w *IrrigationWindow) updateItem(exists bool, change func(irr *models.Irrigation) error) {
+	if w.loading || !exists {
+		return
+	}
+
+	irr := w.irrigation().Copy()
+	if err := change(&irr); err != nil {
+		dialog.ShowError(err, w.window)
+		w.Refresh()
+		return
+	}
+	w.instance.PlanController.SetIrrigation(irr)
+}
+
+func (w *IrrigationWindow) showNode() {
+	w.loading = true
+	defer func() { w.loading = false }()
+
+	n, ok := w.irrigation().Nodes[w.selectedNode]
+	if !ok {
+		w.selectedNode = models.NoIrrigation
+		setEnabled(false, w.nodeName, w.nodeX, w.nodeY, w.nodeSource, w.nodeCapacity, w.nodePressure)
+		w.nodeName.SetText("")
+		return
+	}
+
+	setEnabled(true, w.nodeName, w.nodeX, w.nodeY, w.nodeSource)
+	setEnabled(n.Source, w.nodeCapacity, w.nodePressure)
+	w.nodeName.SetText(n.Name)
+	w.nodeX.SetValue(units.NewValue(float64(n.Location.X), models.BaseLengthUnit))
+	w.nodeY.SetValue(units.NewValue(float64(n.Location.Y), models.BaseLengthUnit))
+	w.nodeSource.SetChecked(n.Source)
+	w.nodeCapacity.SetText(strconv.FormatFloat(float64(n.Capacity), 'f', -1, 32))
+	w.nodePressure.SetText(strconv.FormatFloat(float64(n.Pressure), 'f', -1, 32))
+}
+
+func (w *IrrigationWindow) showZone() {
+	w.loading = true
+	defer func() { w.loading = false }()
+
+	z, ok := w.irrigation().Zones[w.selectedZone]
+	if !ok {
+		w.selectedZone = models.NoIrrigation
+		setEnabled(false, w.zoneName, w.zoneSource, w.zoneRunTime)
+		w.zoneName.SetText("")
+		return
+	}
+
+	setEnabled(true, w.zoneName, w.zoneSource, w.zoneRunTime)
+	w.zoneName.SetText(z.Name)
+	setSelection(w.zoneSource, optionName(w.nodeOptions, z.Source))
+	w.zoneRunTime.SetText(strconv.FormatFloat(float64(z.RunTime), 'f', -1, 32))
+}
+
+func (w *IrrigationWindow) showTube() {
+	w.loading = true
+	defer func() { w.loading = false }()
+
+	t, ok := w.irrigation().Tubes[w.selectedTube]
+	if !ok {
+		w.selectedTube = models.NoIrrigation
+		setEnabled(false, w.tubeFrom, w.tubeTo, w.tubeKind, w.tubeZone, w.tubeRow)
+		return
+	}
+
+	setEnabled(true, w.tubeFrom, w.tubeTo, w.tubeKind, w.tubeZone, w.tubeRow)
+	setSelection(w.tubeFrom, optionName(w.nodeOptions, t.From))
+	setSelection(w.tubeTo, optionName(w.nodeOptions, t.To))
+	w.tubeKind.SetSelected(tubeKindNames[min(int(t.Kind), len(tubeKindNames)-1)])
+	setSelection(w.tubeZone, optionName(w.zoneOptions, t.Zone))
+	setSelection(w.tubeRow, optionName(w.rowOptions, t.Feature))
+}
+
+// Fills the report tab with zone flows, problems and the parts list.
+func (w *IrrigationWindow) showReport() {
+	report := irrigation.Analyze(w.instance.PlanController.Plan, irrigation.DefaultLimits())
+
+	lines := []string{"Zones"}
+	for _, z := range report.Zones {
+		lines = append(lines, fmt.Sprintf("  %s: %.1f of %.0f GPH, %d emitters", z.Name, z.Flow, z.Capacity, z.Emitters))
+	}
+	if len(report.Zones) == 0 {
+		lines = append(lines, "  No zones yet.")
+	}
+
+	lines = append(lines, "", "Problems")
+	for _, p := range report.Problems {
+		lines = append(lines, "  "+p.Message)
+	}
+	if len(report.Problems) == 0 {
+		lines = append(lines, "  None.")
+	}
+
+	lines = append(lines, "", "Parts")
+	for _, p := range report.Parts {
+		lines = append(lines, "  "+p.String())
+	}
+
+	w.reportLabel.SetText(strings.Join(lines, "\n"))
+}
+
+// e.g. "Mainline: Spigot to Junction (12.0 GPH)".
+func (w *IrrigationWindow) describeTube(id models.IrrigationID) string {
+	irr := w.irrigation()
+	t := irr.Tubes[id]
+	name := func(node models.IrrigationID) string {
+		if n, ok := irr.Nodes[node]; ok {
+			return n.Name
+		}
+		return "?"
+	}
+
+	text := fmt.Sprintf("%s: %s to %s", tubeKindNames[min(int(t.Kind), len(tubeKindNames)-1)], name(t.From), name(t.To))
+	if f, ok := w.instance.PlanController.Plan.Features[t.Feature]; ok {
+		text += ", " + f.Name
+	}
+	return text
+}
+
+// List of IDs that calls back with the ID of the selected row.
+func (w *IrrigationWindow) newIDList(ids *[]models.IrrigationID, label func(id models.IrrigationID) string, selected func(id models.IrrigationID)) *widget.List {
+	list := widget.NewList(
+		func() int {
+			return len(*ids)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(label((*ids)[i]))
+		},
+	)
+	list.OnSelected = func(i widget.ListItemID) {
+		selected((*ids)[i])
+	}
+	return list
+}
+
+// A list beside its form, with tools above.
+func editorTab(tools *widget.Toolbar, list *widget.List, form *fyne.Container) fyne.CanvasObject {
+	split := container.NewHSplit(list, container.NewVBox(form))
+	split.Offset = 0.4
+	return container.NewBorder(tools, nil, nil, nil, split)
+}
+
+// Map keys ordered by a name, then by key.
+func sortedIDs[K ~string, V any](items map[K]*V, name func(v *V) string) []K {
+	ids := []K{}
+	for id := range items {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := name(items[ids[i]]), name(items[ids[j]])
+		if a != b {
+			return a < b
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// A name not yet used as an option, numbering repeats.
+func uniqueName[V any](name string, options map[string]V) string {
+	unique := name
+	for i := 2; ; i++ {
+		if _, taken := options[unique]; !taken {
+			return unique
+		}
+		unique = fmt.Sprintf("%s (%d)", name, i)
+	}
+}
+
+// Option name for an ID, or nothing if it isn't an option.
+func optionName[V comparable](options map[string]V, id V) string {
+	for name, v := range options {
+		if v == id {
+			return name
+		}
+	}
+	return ""
+}
+
+// Selects an option, or clears the select for an empty name.
+func setSelection(s *widget.Select, name string) {
+	if name == "" {
+		s.ClearSelected()
+		return
+	}
+	s.SetSelected(name)
+}
+
+// Parses a number that can't be negative into a field.
+func parsePositive(s string, name string, field *float32) error {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 32)
+	if err != nil || v < 0 {
+		return fmt.Errorf("%s must be a number of at least 0", name)
+	}
+	*field = float32(v)
+	return nil
+}
+
+func setEnabled(enabled bool, widgets ...fyne.Disableable) {
+	for _, w := range widgets {
+		if enabled {
+			w.Enable()
+		} else {
+			w.Disable()
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/cpgillem/garden-planner/validation"
+)
+
+// Shows the problems found while loading files. Does nothing if there are none.
+func ShowLoadReport(title string, report *validation.Report, window fyne.Window) {
+	if report.IsEmpty() {
+		return
+	}
+
+	problems := report.Problems()
+	list := widget.NewList(
+		func() int {
+			return len(problems)
+		},
+		func() fyne.CanvasObject {
+			return container.NewHBox(widget.NewIcon(theme.WarningIcon()), widget.NewLabel(""))
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			row := o.(*fyne.Container)
+			icon := row.Objects[0].(*widget.Icon)
+			label := row.Objects[1].(*widget.Label)
+
+			switch problems[i].Severity {
+			case validation.ERROR:
+				icon.SetResource(theme.ErrorIcon())
+			case validation.WARNING:
+				icon.SetResource(theme.WarningIcon())
+			default:
+				icon.SetResource(theme.InfoIcon())
+			}
+
+			location := problems[i].Path
+			if problems[i].File != "" {
+				location = problems[i].File + " " + location
+			}
+			label.SetText(location + "\n" + problems[i].Message)
+		},
+	)
+
+	content := container.NewStack(list)
+	d := dialog.NewCustom(title, "OK", content, window)
+	d.Resize(fyne.NewSize(640, 400))
+	d.Show()
+}
@@ -1,21 +1,24 @@
 package main
 
 import (
-	"fmt"
-
 	"github.com/cpgillem/garden-planner/models"
+	"github.com/cpgillem/garden-planner/validation"
 )
 
 func main() {
 	// Setup instance of UI.
 	gardenPlanner := NewGardenPlanner()
 
+	// Report problems with the data files.
+	ShowLoadReport("Data Problems", gardenPlanner.GardenData.Report, gardenPlanner.Window)
+
 	// Load test plan for now.
-	testPlan, err := ReadObjectFromFile[models.Plan]("test_data/layout1.json")
+	report := validation.NewReport()
+	testPlan, err := ReadObjectFromFile[models.Plan]("test_data/layout1.json", report)
 	if err != nil {
-		fmt.Println(err.Error())
+		testPlan = models.NewPlan()
 	}
-	gardenPlanner.OpenPlan(testPlan)
+	gardenPlanner.LoadPlan(testPlan, report.In("test_data/layout1.json"))
 
 	// Display UI.
 	gardenPlanner.Start()
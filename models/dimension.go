@@ -0,0 +1,54 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/bcicen/go-units"
+)
+
+// Unit of plain numbers that are used as lengths, matching DisplayConfig.
+var BaseLengthUnit = units.Inch
+
+var dimensionFmt = units.FmtOptions{
+	Label:     true,
+	Short:     true,
+	Precision: 6,
+}
+
+// Parses a dimension with a quantity and a unit, separated by a space,
+// e.g. "18 in".
+func ParseDimension(s string) (units.Value, error) {
+	zero := units.NewValue(0, BaseLengthUnit)
+
+	// Check format.
+	s = strings.TrimSpace(s)
+	firstSpace := strings.Index(s, " ")
+	if firstSpace < 0 {
+		return zero, errors.New("Dimension format: [quantity] [unit].")
+	}
+
+	// Parse out quantity and unit string.
+	qty := strings.TrimSpace(s[:firstSpace])
+	unitStr := strings.TrimSpace(s[firstSpace+1:])
+
+	// Parse quantity to float.
+	f, err := strconv.ParseFloat(qty, 32)
+	if err != nil {
+		return zero, errors.New("Quantity must be a number.")
+	}
+
+	// Parse unit string.
+	unit, err := units.Find(unitStr)
+	if err != nil {
+		return zero, errors.New("Unrecognizable unit.")
+	}
+
+	return units.NewValue(f, unit), nil
+}
+
+// Formats a dimension the way ParseDimension reads it.
+func FormatDimension(value units.Value) string {
+	return value.Fmt(dimensionFmt)
+}
@@ -1,31 +1,93 @@
 package models
 
-import "github.com/cpgillem/garden-planner/geometry"
+import (
+	"sort"
 
-type FeatureID int
+	"github.com/cpgillem/garden-planner/geometry"
+	"github.com/google/uuid"
+)
+
+// Globally unique identifier of a feature, stored as a UUID string so that
+// features can be copied between plans without collisions.
+type FeatureID string
+
+// Refers to no feature at all.
+const NoFeature = FeatureID("")
+
+func NewFeatureID() FeatureID {
+	return FeatureID(uuid.NewString())
+}
+
+// Whether the ID is a well-formed UUID.
+func (id FeatureID) IsValid() bool {
+	_, err := uuid.Parse(string(id))
+	return err == nil
+}
 
 // A landscaping feature, such as a row of plants, planter, garden bed, tree, or obstacle.
 // The whole yard, fenced off area, etc. can serve as the root feature.
+//
+// Features can be nested, e.g. yard, fenced garden, raised bed, rows. The box
+// of a feature with a parent is relative to the parent's location, so moving
+// the parent moves everything inside it.
 type Feature struct {
-	Box  geometry.Box `json:"box"`
-	Name string       `json:"name"`
+	Box    geometry.Box `json:"box"`
+	Name   string       `json:"name"`
+	Parent FeatureID    `json:"parent,omitempty"`
+
+	// Outline inside the box. Nil for plain rectangles.
+	Shape *FeatureShape `json:"shape,omitempty"`
+
+	// Angle in degrees, clockwise, that the feature is turned around the
+	// center of its box. Nested features turn with their parent, around the
+	// parent's center, on top of their own rotation.
+	Rotation float32 `json:"rotation,omitempty"`
 
 	// Table of data properties depending on what type of feature this is.
-	Properties map[string]any `json:"properties"`
+	Properties map[string]PropertyValue `json:"properties"`
+
+	// Template the feature was made from, whose required properties it must
+	// keep. Empty for features made before templates were recorded.
+	Template string `json:"template,omitempty"`
 }
 
 func NewFeature(propMap map[string]Property, template *FeatureTemplate) Feature {
 	f := Feature{
 		Name:       template.DisplayName,
+		Template:   template.Name,
 		Box:        template.Box.Copy(),
-		Properties: map[string]any{},
+		Shape:      template.Shape.Copy(),
+		Properties: map[string]PropertyValue{},
 	}
 
 	// Set default properties.
 	for _, propName := range template.Properties {
-		prop := propMap[propName]
-		f.Properties[prop.Name] = prop.Default
+		// Undefined properties are reported when the templates are loaded.
+		prop, ok := propMap[propName]
+		if !ok {
+			continue
+		}
+		f.Properties[prop.Name] = prop.DefaultValue()
 	}
 
 	return f
 }
+
+// The plant grown in a feature, from its first plant property that is set.
+// Returns NO_PLANT if there is none.
+func (f *Feature) PlantID() int {
+	names := []string{}
+	for name, value := range f.Properties {
+		if value.Type == PLANT {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if id, err := f.Properties[name].PlantID(); err == nil && id != NO_PLANT {
+			return id
+		}
+	}
+	return NO_PLANT
+}
@@ -8,4 +8,7 @@ type FeatureTemplate struct {
 	DisplayName string       `json:"display_name"`
 	Properties  []string     `json:"properties"`
 	Box         geometry.Box `json:"box"`
+
+	// Outline of new features. Nil for rectangles.
+	Shape *FeatureShape `json:"shape,omitempty"`
 }
@@ -0,0 +1,175 @@
+package models
+
+import (
+	"sort"
+
+	"github.com/cpgillem/garden-planner/geometry"
+)
+
+// Whether the feature has no parent in the plan. Features whose parent is
+// missing are treated as root features.
+func (p *Plan) IsRoot(id FeatureID) bool {
+	f := p.Features[id]
+	return f == nil || p.Features[f.Parent] == nil
+}
+
+// Features without a parent, sorted by name.
+func (p *Plan) Roots() []FeatureID {
+	ids := []FeatureID{}
+	for id, f := range p.Features {
+		if f != nil && p.IsRoot(id) {
+			ids = append(ids, id)
+		}
+	}
+	p.sortByName(ids)
+	return ids
+}
+
+// Direct children of a feature, sorted by name.
+func (p *Plan) Children(parent FeatureID) []FeatureID {
+	ids := []FeatureID{}
+	for id, f := range p.Features {
+		if f != nil && f.Parent == parent && parent != NoFeature && id != parent {
+			ids = append(ids, id)
+		}
+	}
+	p.sortByName(ids)
+	return ids
+}
+
+// All features nested under a feature, parents before their children.
+func (p *Plan) Descendants(id FeatureID) []FeatureID {
+	ids := []FeatureID{}
+	seen := map[FeatureID]bool{id: true}
+
+	var walk func(parent FeatureID)
+	walk = func(parent FeatureID) {
+		for _, child := range p.Children(parent) {
+			// Guard against cycles in hand-edited plans.
+			if seen[child] {
+				continue
+			}
+			seen[child] = true
+			ids = append(ids, child)
+			walk(child)
+		}
+	}
+	walk(id)
+
+	return ids
+}
+
+// Parents of a feature, nearest first. Stops at a missing parent or a cycle.
+func (p *Plan) Ancestors(id FeatureID) []FeatureID {
+	ids := []FeatureID{}
+	seen := map[FeatureID]bool{id: true}
+
+	for f := p.Features[id]; f != nil; {
+		parent := f.Parent
+		if p.Features[parent] == nil || seen[parent] {
+			break
+		}
+		seen[parent] = true
+		ids = append(ids, parent)
+		f = p.Features[parent]
+	}
+
+	return ids
+}
+
+// Whether following parents from a feature leads back to it.
+func (p *Plan) InCycle(id FeatureID) bool {
+	f := p.Features[id]
+	for steps := 0; f != nil && steps < len(p.Features); steps++ {
+		if f.Parent == id {
+			return true
+		}
+		f = p.Features[f.Parent]
+	}
+	return false
+}
+
+// Whether a feature is nested anywhere under another.
+func (p *Plan) IsDescendant(id FeatureID, ancestor FeatureID) bool {
+	for _, a := range p.Ancestors(id) {
+		if a == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// Number of parents above a feature. Root features have depth 0.
+func (p *Plan) Depth(id FeatureID) int {
+	return len(p.Ancestors(id))
+}
+
+// Box of a feature in plan coordinates rather than relative to its parent,
+// before it or its ancestors are turned. See Placement for where it is
+// drawn.
+func (p *Plan) AbsoluteBox(id FeatureID) geometry.Box {
+	f := p.Features[id]
+	if f == nil {
+		return geometry.NewBoxZero()
+	}
+
+	box := f.Box.Copy()
+	for _, a := range p.Ancestors(id) {
+		box.Location.AddTo(&p.Features[a].Box.Location)
+	}
+	return box
+}
+
+// Box of a feature moved to where it appears on the plan, and how far it is
+// turned around the box's center, in degrees clockwise. Turning a parent
+// swings the features nested in it around the parent's center and turns
+// them with it, so the turns of all its ancestors add up.
+func (p *Plan) Placement(id FeatureID) (geometry.Box, float32) {
+	f := p.Features[id]
+	if f == nil {
+		return geometry.NewBoxZero(), 0
+	}
+
+	box := p.AbsoluteBox(id)
+	center := box.Center()
+	turned := center.Copy()
+	degrees := f.Rotation
+	for _, a := range p.Ancestors(id) {
+		parent := p.AbsoluteBox(a)
+		turned = turned.Rotate(parent.Center(), p.Features[a].Rotation)
+		degrees += p.Features[a].Rotation
+	}
+
+	box.Location.X += turned.X - center.X
+	box.Location.Y += turned.Y - center.Y
+	return box, degrees
+}
+
+// Undoes the turns of a feature and its ancestors, so a point on the plan
+// can be placed among the feature's children. The reverse of the swing
+// that Placement gives nested features. NoFeature leaves points as they
+// are.
+func (p *Plan) Unturn(id FeatureID, point geometry.Vector) geometry.Vector {
+	frames := []FeatureID{}
+	if p.Features[id] != nil {
+		frames = append([]FeatureID{id}, p.Ancestors(id)...)
+	}
+
+	// Outermost first, the reverse of the order they were turned in.
+	for i := len(frames) - 1; i >= 0; i-- {
+		box := p.AbsoluteBox(frames[i])
+		point = point.Rotate(box.Center(), -p.Features[frames[i]].Rotation)
+	}
+	return point
+}
+
+// Sorts features by name, then by ID so the order is stable.
+func (p *Plan) sortByName(ids []FeatureID) {
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := p.Features[ids[i]], p.Features[ids[j]]
+		if a.Name == b.Name {
+			return ids[i] < ids[j]
+		}
+		return a.Name < b.Name
+	})
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/cpgillem/garden-planner/geometry"
+)
+
+func TestNormalizeParents(t *testing.T) {
+	a, b, c := NewFeatureID(), NewFeatureID(), NewFeatureID()
+	plan := NewPlan()
+	plan.Features[a] = &Feature{Name: "A", Parent: b, Box: geometry.NewBox(1, 1, 1, 1)}
+	plan.Features[b] = &Feature{Name: "B", Parent: a, Box: geometry.NewBox(1, 1, 1, 1)}
+	plan.Features[c] = &Feature{Name: "C", Parent: NewFeatureID()}
+
+	if !plan.InCycle(a) {
+		t.Fatalf("InCycle(a) == false; want true")
+	}
+
+	NormalizeParents(plan)
+	if plan.InCycle(a) || plan.InCycle(b) {
+		t.Errorf("cycle still present after NormalizeParents")
+	}
+	if plan.Features[c].Parent != NoFeature {
+		t.Errorf("feature with a missing parent was not made a root feature")
+	}
+	if got := len(plan.Roots()); got != 2 {
+		t.Errorf("len(Roots()) == %d; want 2", got)
+	}
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"sort"
+
+	"github.com/cpgillem/garden-planner/geometry"
+	"github.com/google/uuid"
+)
+
+// Identifier of an irrigation node, tube or zone. UUIDs, like feature IDs.
+type IrrigationID string
+
+// Refers to no irrigation item at all.
+const NoIrrigation = IrrigationID("")
+
+func NewIrrigationID() IrrigationID {
+	return IrrigationID(uuid.NewString())
+}
+
+// Kinds of tubing.
+const (
+	// Carries water from a source to the laterals.
+	MAINLINE uint8 = iota
+
+	// Carries water to a plant row, where emitters drip it onto the plants.
+	LATERAL
+)
+
+// A point in the irrigation network: a water source, such as a spigot, or a
+// junction where tubes meet. Locations are in plan coordinates.
+type IrrigationNode struct {
+	Name     string          `json:"name"`
+	Location geometry.Vector `json:"location"`
+
+	// Sources supply water to zones. Capacity is in gallons per hour and
+	// pressure in PSI.
+	Source   bool    `json:"source,omitempty"`
+	Capacity float32 `json:"capacity,omitempty"`
+	Pressure float32 `json:"pressure,omitempty"`
+}
+
+// A straight length of tubing between two nodes.
+type Tube struct {
+	From IrrigationID `json:"from"`
+	To   IrrigationID `json:"to"`
+	Kind uint8        `json:"kind"`
+	Zone IrrigationID `json:"zone"`
+
+	// Plant row watered by a lateral. The lateral continues along the row,
+	// with emitters spaced like the plants.
+	Feature FeatureID `json:"feature,omitempty"`
+}
+
+// Tubes watered together from one source, through one valve.
+type IrrigationZone struct {
+	Name   string       `json:"name"`
+	Source IrrigationID `json:"source"`
+
+	// Minutes the zone runs each time the garden is watered.
+	RunTime float32 `json:"run_time"`
+}
+
+// Drip irrigation network of a plan.
+type Irrigation struct {
+	Nodes map[IrrigationID]*IrrigationNode `json:"nodes"`
+	Tubes map[IrrigationID]*Tube           `json:"tubes"`
+	Zones map[IrrigationID]*IrrigationZone `json:"zones"`
+}
+
+func NewIrrigation() Irrigation {
+	return Irrigation{
+		Nodes: map[IrrigationID]*IrrigationNode{},
+		Tubes: map[IrrigationID]*Tube{},
+		Zones: map[IrrigationID]*IrrigationZone{},
+	}
+}
+
+// Deep copy, so one can be changed without affecting the other.
+func (i *Irrigation) Copy() Irrigation {
+	c := NewIrrigation()
+	for id, n := range i.Nodes {
+		node := *n
+		node.Location = n.Location.Copy()
+		c.Nodes[id] = &node
+	}
+	for id, t := range i.Tubes {
+		tube := *t
+		c.Tubes[id] = &tube
+	}
+	for id, z := range i.Zones {
+		zone := *z
+		c.Zones[id] = &zone
+	}
+	return c
+}
+
+// Fills in maps missing from older or hand-written plans and drops empty
+// entries.
+func (i *Irrigation) Normalize() {
+	for id, n := range i.Nodes {
+		if n == nil {
+			delete(i.Nodes, id)
+		}
+	}
+	for id, t := range i.Tubes {
+		if t == nil {
+			delete(i.Tubes, id)
+		}
+	}
+	for id, z := range i.Zones {
+		if z == nil {
+			delete(i.Zones, id)
+		}
+	}
+
+	if i.Nodes == nil {
+		i.Nodes = map[IrrigationID]*IrrigationNode{}
+	}
+	if i.Tubes == nil {
+		i.Tubes = map[IrrigationID]*Tube{}
+	}
+	if i.Zones == nil {
+		i.Zones = map[IrrigationID]*IrrigationZone{}
+	}
+}
+
+// Tubes belonging to a zone, sorted by ID so the order is stable.
+func (i *Irrigation) ZoneTubes(zone IrrigationID) []IrrigationID {
+	ids := []IrrigationID{}
+	for id, t := range i.Tubes {
+		if t != nil && t.Zone == zone {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+	return ids
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Version of the plan file format written by this build. Bump it and register
+// a migration from the previous version whenever the saved format changes.
+const PlanFormatVersion = 6
+
+// Upgrades a decoded plan document from one format version to the next.
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(doc map[string]any) error
+}
+
+var planMigrations = map[int]Migration{}
+
+// Registers the migration from a format version to the one after it.
+func RegisterPlanMigration(m Migration) {
+	planMigrations[m.From] = m
+}
+
+// Returned when a file was written in a format this build doesn't understand.
+type FormatVersionError struct {
+	Version   int
+	Supported int
+}
+
+func (e FormatVersionError) Error() string {
+	return fmt.Sprintf(
+		"this plan was saved in format version %d, but this version of Garden Planner only reads up to version %d; please update the app",
+		e.Version,
+		e.Supported,
+	)
+}
+
+// Upgrades plan JSON of any older format version to PlanFormatVersion, one
+// version at a time. Content that is already current is returned unchanged.
+func MigratePlan(content []byte) ([]byte, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+
+	// Plans from before versioning have no version field.
+	version := 0
+	if v, ok := doc["format_version"].(float64); ok {
+		version = int(v)
+	}
+
+	if version > PlanFormatVersion {
+		return nil, FormatVersionError{Version: version, Supported: PlanFormatVersion}
+	}
+	if version == PlanFormatVersion {
+		return content, nil
+	}
+
+	for ; version < PlanFormatVersion; version++ {
+		m, ok := planMigrations[version]
+		if !ok {
+			return nil, fmt.Errorf("no migration from plan format version %d", version)
+		}
+		if err := m.Apply(doc); err != nil {
+			return nil, fmt.Errorf("migrating plan from format version %d: %w", version, err)
+		}
+		doc["format_version"] = version + 1
+	}
+
+	return json.Marshal(doc)
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMigrateLegacyPlan(t *testing.T) {
+	content := []byte(`{
+		"name": "Legacy",
+		"features": {
+			"0": {"name": "PotatoRow", "properties": {}},
+			"1": {"name": "BeanRow", "properties": {}}
+		}
+	}`)
+
+	migrated, err := MigratePlan(content)
+	if err != nil {
+		t.Fatalf("MigratePlan: %s", err.Error())
+	}
+
+	var plan Plan
+	if err := json.Unmarshal(migrated, &plan); err != nil {
+		t.Fatalf("Unmarshal: %s", err.Error())
+	}
+
+	if plan.FormatVersion != PlanFormatVersion {
+		t.Errorf("format version == %d; want %d", plan.FormatVersion, PlanFormatVersion)
+	}
+	if len(plan.Features) != 2 {
+		t.Fatalf("got %d features; want 2", len(plan.Features))
+	}
+	for id := range plan.Features {
+		if !id.IsValid() {
+			t.Errorf("feature ID %q was not upgraded to a UUID", id)
+		}
+	}
+}
+
+func TestMigrateNewerPlan(t *testing.T) {
+	content := []byte(`{"format_version": 9999, "name": "Future"}`)
+
+	_, err := MigratePlan(content)
+	var versionErr FormatVersionError
+	if !errors.As(err, &versionErr) {
+		t.Fatalf("MigratePlan(newer) error == %v; want FormatVersionError", err)
+	}
+}
+
+func TestMarshalStampsVersion(t *testing.T) {
+	content, err := json.Marshal(&Plan{})
+	if err != nil {
+		t.Fatalf("Marshal: %s", err.Error())
+	}
+
+	var doc map[string]any
+	json.Unmarshal(content, &doc)
+	if doc["format_version"] != float64(PlanFormatVersion) {
+		t.Errorf("format_version == %v; want %d", doc["format_version"], PlanFormatVersion)
+	}
+}
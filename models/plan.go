@@ -1,17 +1,39 @@
 package models
 
-import "github.com/cpgillem/garden-planner/geometry"
+import (
+	"encoding/json"
+
+	"github.com/cpgillem/garden-planner/geometry"
+)
 
 type Plan struct {
-	Name     string                 `json:"name"`
-	Box      geometry.Box           `json:"box"`
-	Features map[FeatureID]*Feature `json:"features"`
+	FormatVersion int                    `json:"format_version"`
+	Name          string                 `json:"name"`
+	Box           geometry.Box           `json:"box"`
+	Features      map[FeatureID]*Feature `json:"features"`
+	Irrigation    Irrigation             `json:"irrigation"`
 }
 
 func NewPlan() *Plan {
 	return &Plan{
-		Name:     "",
-		Box:      geometry.NewBoxZero(),
-		Features: map[FeatureID]*Feature{},
+		FormatVersion: PlanFormatVersion,
+		Name:          "",
+		Box:           geometry.NewBoxZero(),
+		Features:      map[FeatureID]*Feature{},
+		Irrigation:    NewIrrigation(),
 	}
 }
+
+// Encodes a plan, always stamping it with the current format version.
+func (p Plan) MarshalJSON() ([]byte, error) {
+	// Alias without methods to avoid recursing into this function.
+	type plan Plan
+	encoded := plan(p)
+	encoded.FormatVersion = PlanFormatVersion
+	return json.Marshal(encoded)
+}
+
+// Upgrades plan content from older format versions.
+func (p *Plan) Migrate(content []byte) ([]byte, error) {
+	return MigratePlan(content)
+}
@@ -0,0 +1,128 @@
+package models
+
+import "fmt"
+
+// Migrations between plan format versions, oldest first.
+func init() {
+	RegisterPlanMigration(Migration{
+		From:        0,
+		Description: "Key features by UUID instead of integer IDs.",
+		Apply: func(doc map[string]any) error {
+			features, ok := doc["features"].(map[string]any)
+			if !ok {
+				return nil
+			}
+
+			upgraded := map[string]any{}
+			for key, f := range features {
+				id := FeatureID(key)
+				if !id.IsValid() {
+					id = NewFeatureID()
+				}
+				upgraded[string(id)] = f
+			}
+			doc["features"] = upgraded
+
+			return nil
+		},
+	})
+
+	RegisterPlanMigration(Migration{
+		From:        1,
+		Description: "Store property values with their type.",
+		Apply: func(doc map[string]any) error {
+			features, err := docFeatures(doc)
+			if err != nil {
+				return err
+			}
+
+			for _, f := range features {
+				properties, ok := f["properties"].(map[string]any)
+				if !ok {
+					continue
+				}
+
+				for name, value := range properties {
+					// Types are guessed here and corrected against the property
+					// definitions once the plan is loaded.
+					switch value.(type) {
+					case string:
+						properties[name] = map[string]any{"type": string(STRING), "value": value}
+					case float64:
+						properties[name] = map[string]any{"type": string(DECIMAL), "value": value}
+					case bool:
+						properties[name] = map[string]any{"type": string(BOOLEAN), "value": value}
+					}
+				}
+			}
+
+			return nil
+		},
+	})
+
+	RegisterPlanMigration(Migration{
+		From:        2,
+		Description: "Allow features to be nested under a parent feature.",
+		Apply: func(doc map[string]any) error {
+			// Older plans are flat; every feature stays a root feature. The
+			// version still changes so older versions of the app refuse plans
+			// they would flatten.
+			return nil
+		},
+	})
+
+	RegisterPlanMigration(Migration{
+		From:        3,
+		Description: "Add a drip irrigation network.",
+		Apply: func(doc map[string]any) error {
+			if _, ok := doc["irrigation"]; !ok {
+				doc["irrigation"] = map[string]any{}
+			}
+			return nil
+		},
+	})
+
+	RegisterPlanMigration(Migration{
+		From:        4,
+		Description: "Allow polygon, ellipse and arc feature outlines.",
+		Apply: func(doc map[string]any) error {
+			// Older plans only have rectangles. The version still changes so
+			// older versions of the app refuse plans whose outlines they would
+			// drop.
+			return nil
+		},
+	})
+
+	RegisterPlanMigration(Migration{
+		From:        5,
+		Description: "Allow features to be turned.",
+		Apply: func(doc map[string]any) error {
+			// Older plans have no turned features.
+			return nil
+		},
+	})
+}
+
+// Reads the feature map out of a plan document, failing on unexpected shapes.
+func docFeatures(doc map[string]any) (map[string]map[string]any, error) {
+	features := map[string]map[string]any{}
+	raw, ok := doc["features"]
+	if !ok || raw == nil {
+		return features, nil
+	}
+
+	rawMap, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("features must be an object")
+	}
+
+	for id, f := range rawMap {
+		fMap, ok := f.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("feature %s must be an object", id)
+		}
+		features[id] = fMap
+	}
+
+	return features, nil
+}
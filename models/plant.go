@@ -1,5 +1,8 @@
 package models
 
+// Plant ID of a plant reference that doesn't point to any plant.
+const NO_PLANT = 0
+
 // Plant species, cultivar, or whatever else (author is not a botanist).
 // Examples: potato, cabbage, broccoli. Does not cover different variants, such as "better boy" tomatoes.
 // In the future, this will be divided into variants where only some properties
@@ -8,6 +11,19 @@ type Plant struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
 
+	// Botanical family, e.g. Solanaceae, used to group plants.
+	Family string `json:"family,omitempty"`
+
 	// List of interactions with other plant types for intercropping.
 	Interactions []PlantInteraction `json:"interactions"`
 }
+
+// How this plant is affected by another. Plants without an entry are NEUTRAL.
+func (p *Plant) InteractionWith(id int) uint16 {
+	for _, interaction := range p.Interactions {
+		if interaction.TargetPlantID == id {
+			return interaction.InteractionType
+		}
+	}
+	return NEUTRAL
+}
@@ -11,3 +11,35 @@ type PlantInteraction struct {
 	TargetPlantID   int    `json:"target_plant_id"`
 	InteractionType uint16 `json:"interaction_type"`
 }
+
+// Interaction types in order, for selectors.
+var InteractionTypes = []uint16{NEUTRAL, BENEFICIAL, ANTAGONISTIC}
+
+// Name of an interaction type as shown to users.
+func InteractionTypeName(t uint16) string {
+	switch t {
+	case NEUTRAL:
+		return "Neutral"
+	case BENEFICIAL:
+		return "Beneficial"
+	case ANTAGONISTIC:
+		return "Antagonistic"
+	default:
+		return "Unknown"
+	}
+}
+
+// Combined interaction of two plants grown near each other. Either plant
+// harming the other makes the pair antagonistic; otherwise either helping
+// makes it beneficial.
+func CombinedInteraction(a Plant, b Plant) uint16 {
+	ab, ba := a.InteractionWith(b.ID), b.InteractionWith(a.ID)
+	switch {
+	case ab == ANTAGONISTIC || ba == ANTAGONISTIC:
+		return ANTAGONISTIC
+	case ab == BENEFICIAL || ba == BENEFICIAL:
+		return BENEFICIAL
+	default:
+		return NEUTRAL
+	}
+}
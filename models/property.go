@@ -1,9 +1,202 @@
 package models
 
+import (
+	"fmt"
+	"math"
+
+	"github.com/bcicen/go-units"
+)
+
+// The kind of value a property holds, which decides how it is edited.
+type PropertyType string
+
+const (
+	DIMENSION = PropertyType("dimension")
+	DECIMAL   = PropertyType("decimal")
+	INTEGER   = PropertyType("integer")
+	STRING    = PropertyType("string")
+	BOOLEAN   = PropertyType("boolean")
+	ENUM      = PropertyType("enum")
+	PLANT     = PropertyType("plant")
+)
+
+// Every property type the editor understands.
+var PropertyTypes = []PropertyType{DIMENSION, DECIMAL, INTEGER, STRING, BOOLEAN, ENUM, PLANT}
+
+func IsPropertyType(t PropertyType) bool {
+	for _, known := range PropertyTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Families of units a dimension property can accept, named after go-units
+// quantities, with the unit plain numbers are read in.
+var UnitFamilies = map[string]units.Unit{
+	"length": BaseLengthUnit,
+	"volume": units.FluidGallon,
+}
+
 type Property struct {
-	Name         string `json:"name"`
-	DisplayName  string `json:"display_name"`
-	Default      any    `json:"default"`
-	Description  string `json:"description"`
-	PropertyType string `json:"property_type"`
+	Name         string        `json:"name"`
+	DisplayName  string        `json:"display_name"`
+	Default      PropertyValue `json:"default"`
+	Description  string        `json:"description"`
+	PropertyType PropertyType  `json:"property_type"`
+
+	// Allowed values of an enum or string property. Any string is allowed if
+	// a string property has no options.
+	Options []string `json:"options,omitempty"`
+
+	// Numeric limits, in the property's type. Dimension limits may use any
+	// unit of the property's unit family.
+	Min  *PropertyValue `json:"min,omitempty"`
+	Max  *PropertyValue `json:"max,omitempty"`
+	Step *PropertyValue `json:"step,omitempty"`
+
+	// Whether the value may be left empty.
+	Required bool `json:"required,omitempty"`
+
+	// Family of units a dimension accepts, e.g. "length" or "volume".
+	// Defaults to length.
+	UnitFamily string `json:"unit_family,omitempty"`
+}
+
+// Returned when a value breaks one of a property's constraints.
+type ConstraintError struct {
+	Property string
+	Message  string
+}
+
+func (e ConstraintError) Error() string {
+	return e.Property + " " + e.Message
+}
+
+// The unit family of a dimension property.
+func (p *Property) Family() string {
+	if p.UnitFamily == "" {
+		return "length"
+	}
+	return p.UnitFamily
+}
+
+// The unit plain numbers are read in for this property.
+func (p *Property) BaseUnit() units.Unit {
+	if unit, ok := UnitFamilies[p.Family()]; ok {
+		return unit
+	}
+	return BaseLengthUnit
+}
+
+// Converts a value to this property's type. Plain numbers given for a
+// dimension are read in the base unit of its family.
+func (p *Property) Convert(v PropertyValue) (PropertyValue, error) {
+	if p.PropertyType == DIMENSION && (v.Type == DECIMAL || v.Type == INTEGER) {
+		f, _ := v.number()
+		return NewDimensionValue(units.NewValue(f, p.BaseUnit())), nil
+	}
+	return v.Convert(p.PropertyType)
+}
+
+// The default value, converted to this property's type. Falls back to the
+// zero value of the type if the default can't be converted.
+func (p *Property) DefaultValue() PropertyValue {
+	v, err := p.Convert(p.Default)
+	if err != nil && p.PropertyType == DIMENSION {
+		return NewDimensionValue(units.NewValue(0, p.BaseUnit()))
+	}
+	return v
+}
+
+// Converts a value to this property's type and checks it against every
+// constraint. The converted value is returned even if a constraint fails.
+func (p *Property) Check(v PropertyValue) (PropertyValue, error) {
+	v, err := p.Convert(v)
+	if err != nil {
+		return v, err
+	}
+
+	fail := func(format string, args ...any) (PropertyValue, error) {
+		return v, ConstraintError{Property: p.displayName(), Message: fmt.Sprintf(format, args...)}
+	}
+
+	// Required values must not be empty or zero, whatever their type.
+	if p.Required && v.IsEmpty() {
+		return fail("is required")
+	}
+
+	// Dimensions must use the right kind of unit.
+	if v.Type == DIMENSION && v.dimension.Unit().Quantity != p.Family() {
+		return fail("must be a %s, not %q", p.Family(), v.String())
+	}
+
+	// Options
+	if len(p.Options) > 0 && (v.Type == STRING || v.Type == ENUM) && !(v.text == "" && !p.Required) {
+		found := false
+		for _, option := range p.Options {
+			found = found || option == v.text
+		}
+		if !found {
+			return fail("must be one of %v", p.Options)
+		}
+	}
+
+	// Range and step, compared in the value's own unit.
+	value, ok := v.number()
+	if !ok || v.Type == PLANT || v.Type == BOOLEAN {
+		return v, nil
+	}
+	if p.Min != nil {
+		if min, err := p.limit(*p.Min, v); err == nil && value < min {
+			return fail("must be at least %s", p.Min.String())
+		}
+	}
+	if p.Max != nil {
+		if max, err := p.limit(*p.Max, v); err == nil && value > max {
+			return fail("must be at most %s", p.Max.String())
+		}
+	}
+	if p.Step != nil {
+		step, err := p.limit(*p.Step, v)
+		base := 0.0
+		if p.Min != nil {
+			base, _ = p.limit(*p.Min, v)
+		}
+		if err == nil && step > 0 {
+			steps := (value - base) / step
+			if math.Abs(steps-math.Round(steps)) > 1e-6 {
+				return fail("must be in steps of %s", p.Step.String())
+			}
+		}
+	}
+
+	return v, nil
+}
+
+// Reads a limit as a number comparable with v, converting units if needed.
+func (p *Property) limit(limit PropertyValue, v PropertyValue) (float64, error) {
+	limit, err := p.Convert(limit)
+	if err != nil {
+		return 0, err
+	}
+
+	if v.Type == DIMENSION {
+		converted, err := limit.dimension.Convert(v.dimension.Unit())
+		if err != nil {
+			return 0, err
+		}
+		return converted.Float(), nil
+	}
+
+	f, _ := limit.number()
+	return f, nil
+}
+
+func (p *Property) displayName() string {
+	if p.DisplayName != "" {
+		return p.DisplayName
+	}
+	return p.Name
 }
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPropertyCheck(t *testing.T) {
+	var properties []Property
+	err := json.Unmarshal([]byte(`[
+		{"name": "spacing", "property_type": "dimension", "unit_family": "length", "min": "1 in", "max": "20 ft"},
+		{"name": "frequency", "property_type": "integer", "min": 0, "max": 14},
+		{"name": "volume", "property_type": "dimension", "unit_family": "volume", "step": "0.5 gal"},
+		{"name": "method", "property_type": "enum", "options": ["drip", "spray"], "required": true},
+		{"name": "row_width", "property_type": "dimension", "unit_family": "length", "required": true}
+	]`), &properties)
+	if err != nil {
+		t.Fatalf("Unmarshal: %s", err.Error())
+	}
+
+	cases := []struct {
+		property int
+		in       PropertyValue
+		wantErr  bool
+	}{
+		{0, NewStringValue("18 in"), false},
+		{0, NewStringValue("-2 in"), true},
+		{0, NewStringValue("21 ft"), true},
+		{0, NewStringValue("1 gal"), true},
+		{1, NewDecimalValue(3), false},
+		{1, NewDecimalValue(500), true},
+		{2, NewStringValue("1.5 gal"), false},
+		{2, NewStringValue("1.2 gal"), true},
+		{3, NewEnumValue("drip"), false},
+		{3, NewEnumValue("flood"), true},
+		{3, NewEnumValue(""), true},
+		{4, NewStringValue("18 in"), false},
+		{4, NewStringValue("0 in"), true},
+	}
+
+	for _, c := range cases {
+		p := properties[c.property]
+		_, err := p.Check(c.in)
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s.Check(%q) error == %v; want error: %v", p.Name, c.in.String(), err, c.wantErr)
+		}
+	}
+}
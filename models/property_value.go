@@ -0,0 +1,306 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/bcicen/go-units"
+	"github.com/cpgillem/garden-planner/validation"
+)
+
+// The value of a property on a feature. Only the field matching Type is used.
+//
+// In JSON, values are written as {"type": "dimension", "value": "18 in"}.
+// Bare JSON strings, numbers and booleans are also read, as untyped string,
+// decimal and boolean values, so hand-written data files stay simple; use
+// Convert to turn them into the type a property expects.
+type PropertyValue struct {
+	Type PropertyType
+
+	dimension units.Value
+	decimal   float64
+	integer   int
+	text      string
+	boolean   bool
+}
+
+func NewDimensionValue(v units.Value) PropertyValue {
+	return PropertyValue{Type: DIMENSION, dimension: v}
+}
+
+func NewDecimalValue(f float64) PropertyValue {
+	return PropertyValue{Type: DECIMAL, decimal: f}
+}
+
+func NewIntegerValue(i int) PropertyValue {
+	return PropertyValue{Type: INTEGER, integer: i}
+}
+
+func NewStringValue(s string) PropertyValue {
+	return PropertyValue{Type: STRING, text: s}
+}
+
+func NewBooleanValue(b bool) PropertyValue {
+	return PropertyValue{Type: BOOLEAN, boolean: b}
+}
+
+func NewEnumValue(option string) PropertyValue {
+	return PropertyValue{Type: ENUM, text: option}
+}
+
+// A reference to a plant by ID.
+func NewPlantValue(plantID int) PropertyValue {
+	return PropertyValue{Type: PLANT, integer: plantID}
+}
+
+// Returned when a value can't be read as another type.
+type ConversionError struct {
+	Value PropertyValue
+	To    PropertyType
+	Cause string
+}
+
+func (e ConversionError) Error() string {
+	msg := fmt.Sprintf("cannot use %s value %q as %s", e.Value.Type, e.Value.String(), e.To)
+	if e.Cause != "" {
+		msg += ": " + e.Cause
+	}
+	return msg
+}
+
+// The empty value of a type, e.g. 0 in, "" or false.
+func ZeroValue(t PropertyType) PropertyValue {
+	if t == DIMENSION {
+		return NewDimensionValue(units.NewValue(0, BaseLengthUnit))
+	}
+	return PropertyValue{Type: t}
+}
+
+// Whether the value has no type, e.g. when it was null in JSON.
+func (v PropertyValue) IsZero() bool {
+	return v.Type == ""
+}
+
+// Accessors. Each converts the value if it isn't already of that type.
+
+func (v PropertyValue) Dimension() (units.Value, error) {
+	c, err := v.Convert(DIMENSION)
+	return c.dimension, err
+}
+
+func (v PropertyValue) Decimal() (float64, error) {
+	c, err := v.Convert(DECIMAL)
+	return c.decimal, err
+}
+
+func (v PropertyValue) Integer() (int, error) {
+	c, err := v.Convert(INTEGER)
+	return c.integer, err
+}
+
+func (v PropertyValue) Boolean() (bool, error) {
+	c, err := v.Convert(BOOLEAN)
+	return c.boolean, err
+}
+
+func (v PropertyValue) PlantID() (int, error) {
+	c, err := v.Convert(PLANT)
+	return c.integer, err
+}
+
+// Text form of the value, as shown to users.
+func (v PropertyValue) String() string {
+	switch v.Type {
+	case DIMENSION:
+		return FormatDimension(v.dimension)
+	case DECIMAL:
+		return strconv.FormatFloat(v.decimal, 'f', -1, 64)
+	case INTEGER, PLANT:
+		return strconv.Itoa(v.integer)
+	case STRING, ENUM:
+		return v.text
+	case BOOLEAN:
+		return strconv.FormatBool(v.boolean)
+	default:
+		return ""
+	}
+}
+
+// Whether the value is empty: blank text, zero, false or no plant.
+func (v PropertyValue) IsEmpty() bool {
+	if v.Type == STRING || v.Type == ENUM {
+		return v.text == ""
+	}
+	f, _ := v.number()
+	return f == 0
+}
+
+// Numeric form of the value. Dimensions are given in their own unit.
+func (v PropertyValue) number() (float64, bool) {
+	switch v.Type {
+	case DIMENSION:
+		return v.dimension.Float(), true
+	case DECIMAL:
+		return v.decimal, true
+	case INTEGER, PLANT:
+		return float64(v.integer), true
+	case BOOLEAN:
+		if v.boolean {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// Converts the value to another type. Plain numbers become dimensions in
+// BaseLengthUnit; text is parsed. Never panics, whatever the value holds.
+func (v PropertyValue) Convert(t PropertyType) (PropertyValue, error) {
+	if v.Type == t {
+		return v, nil
+	}
+
+	fail := func(cause string) (PropertyValue, error) {
+		return ZeroValue(t), ConversionError{Value: v, To: t, Cause: cause}
+	}
+
+	switch t {
+	case DIMENSION:
+		if v.Type == STRING || v.Type == ENUM {
+			d, err := ParseDimension(v.text)
+			if err != nil {
+				return fail(err.Error())
+			}
+			return NewDimensionValue(d), nil
+		}
+		if f, ok := v.number(); ok && v.Type != BOOLEAN {
+			return NewDimensionValue(units.NewValue(f, BaseLengthUnit)), nil
+		}
+	case DECIMAL:
+		if v.Type == STRING || v.Type == ENUM {
+			f, err := strconv.ParseFloat(strings.TrimSpace(v.text), 64)
+			if err != nil {
+				return fail("not a number")
+			}
+			return NewDecimalValue(f), nil
+		}
+		if f, ok := v.number(); ok {
+			return NewDecimalValue(f), nil
+		}
+	case INTEGER, PLANT:
+		f, ok := v.number()
+		if v.Type == STRING || v.Type == ENUM {
+			i, err := strconv.Atoi(strings.TrimSpace(v.text))
+			f, ok = float64(i), err == nil
+		}
+		if !ok || v.Type == DIMENSION {
+			return fail("not a whole number")
+		}
+		if f != math.Trunc(f) || math.Abs(f) > math.MaxInt32 {
+			return fail("not a whole number")
+		}
+		return PropertyValue{Type: t, integer: int(f)}, nil
+	case STRING:
+		if v.Type != "" {
+			return NewStringValue(v.String()), nil
+		}
+	case ENUM:
+		if v.Type == STRING {
+			return NewEnumValue(v.text), nil
+		}
+	case BOOLEAN:
+		if v.Type == STRING || v.Type == ENUM {
+			b, err := strconv.ParseBool(strings.TrimSpace(v.text))
+			if err != nil {
+				return fail("not true or false")
+			}
+			return NewBooleanValue(b), nil
+		}
+		if f, ok := v.number(); ok && v.Type != DIMENSION {
+			return NewBooleanValue(f != 0), nil
+		}
+	}
+
+	return fail("")
+}
+
+// JSON form of a typed value.
+type propertyValueJSON struct {
+	Type  PropertyType `json:"type"`
+	Value any          `json:"value"`
+}
+
+func (v PropertyValue) MarshalJSON() ([]byte, error) {
+	if v.Type == "" {
+		return []byte("null"), nil
+	}
+
+	encoded := propertyValueJSON{Type: v.Type}
+	switch v.Type {
+	case DIMENSION, STRING, ENUM:
+		encoded.Value = v.String()
+	case DECIMAL:
+		encoded.Value = v.decimal
+	case INTEGER, PLANT:
+		encoded.Value = v.integer
+	case BOOLEAN:
+		encoded.Value = v.boolean
+	default:
+		return nil, fmt.Errorf("unknown property type %q", v.Type)
+	}
+
+	return json.Marshal(encoded)
+}
+
+func (v *PropertyValue) UnmarshalJSON(content []byte) error {
+	var raw any
+	if err := json.Unmarshal(content, &raw); err != nil {
+		return err
+	}
+
+	// Malformed values don't fail the whole file. Whatever could be read is
+	// kept, and CheckJSON reports the problem during validation.
+	*v, _ = propertyValueFromJSON(raw)
+	return nil
+}
+
+// Reports problems with the JSON form of a value without failing the load.
+func (v PropertyValue) CheckJSON(raw any, path string, r *validation.Report) {
+	if _, err := propertyValueFromJSON(raw); err != nil {
+		r.Errorf(path, "%s", err.Error())
+	}
+}
+
+// Reads either the typed object form or a bare scalar.
+func propertyValueFromJSON(raw any) (PropertyValue, error) {
+	switch r := raw.(type) {
+	case nil:
+		return PropertyValue{}, nil
+	case string:
+		return NewStringValue(r), nil
+	case float64:
+		return NewDecimalValue(r), nil
+	case bool:
+		return NewBooleanValue(r), nil
+	case map[string]any:
+		t, _ := r["type"].(string)
+		if !IsPropertyType(PropertyType(t)) {
+			return PropertyValue{}, fmt.Errorf("unknown property type %q", t)
+		}
+		if _, nested := r["value"].(map[string]any); nested {
+			return PropertyValue{}, fmt.Errorf("property value must be a string, number or boolean")
+		}
+		scalar, _ := propertyValueFromJSON(r["value"])
+		converted, err := scalar.Convert(PropertyType(t))
+		if err != nil {
+			return scalar, err
+		}
+		return converted, nil
+	default:
+		return PropertyValue{}, fmt.Errorf("property value must be a string, number, boolean or typed object")
+	}
+}
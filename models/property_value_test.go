@@ -0,0 +1,93 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bcicen/go-units"
+)
+
+func TestPropertyValueJSON(t *testing.T) {
+	cases := []struct {
+		in   string
+		want PropertyValue
+	}{
+		{`{"type": "dimension", "value": "18 in"}`, NewDimensionValue(units.NewValue(18, units.Inch))},
+		{`{"type": "integer", "value": 3}`, NewIntegerValue(3)},
+		{`{"type": "decimal", "value": 1.5}`, NewDecimalValue(1.5)},
+		{`{"type": "boolean", "value": true}`, NewBooleanValue(true)},
+		{`{"type": "enum", "value": "drip"}`, NewEnumValue("drip")},
+		{`{"type": "plant", "value": 2}`, NewPlantValue(2)},
+		{`"18 in"`, NewStringValue("18 in")},
+		{`12`, NewDecimalValue(12)},
+	}
+
+	for _, c := range cases {
+		var got PropertyValue
+		if err := json.Unmarshal([]byte(c.in), &got); err != nil {
+			t.Errorf("Unmarshal(%s): %s", c.in, err.Error())
+			continue
+		}
+		if got.Type != c.want.Type || got.String() != c.want.String() {
+			t.Errorf("Unmarshal(%s) == %s %q; want %s %q", c.in, got.Type, got.String(), c.want.Type, c.want.String())
+		}
+
+		// Typed values survive a round trip.
+		content, err := json.Marshal(got)
+		if err != nil {
+			t.Errorf("Marshal(%s): %s", c.in, err.Error())
+			continue
+		}
+		var again PropertyValue
+		json.Unmarshal(content, &again)
+		if again.Type != got.Type || again.String() != got.String() {
+			t.Errorf("round trip of %s gave %s", c.in, content)
+		}
+	}
+}
+
+func TestPropertyValueMalformedJSON(t *testing.T) {
+	// None of these may panic or fail the surrounding document.
+	for _, in := range []string{
+		`{"type": "integer", "value": 1.5}`,
+		`{"type": "widget", "value": 1}`,
+		`{"type": "dimension", "value": {"x": 1}}`,
+		`[1, 2]`,
+	} {
+		var got PropertyValue
+		if err := json.Unmarshal([]byte(in), &got); err != nil {
+			t.Errorf("Unmarshal(%s) failed: %s", in, err.Error())
+		}
+	}
+}
+
+func TestPropertyValueConvert(t *testing.T) {
+	cases := []struct {
+		in      PropertyValue
+		to      PropertyType
+		want    string
+		wantErr bool
+	}{
+		// JSON numbers are decimals until converted.
+		{NewDecimalValue(1), INTEGER, "1", false},
+		{NewDecimalValue(1.5), INTEGER, "0", true},
+		{NewDecimalValue(18), DIMENSION, "18 in", false},
+		{NewStringValue("1.5 ft"), DIMENSION, "1.5 ft", false},
+		{NewStringValue("tall"), DIMENSION, "0 in", true},
+		{NewStringValue("true"), BOOLEAN, "true", false},
+		{NewIntegerValue(2), PLANT, "2", false},
+		{NewDimensionValue(units.NewValue(2, units.Foot)), INTEGER, "0", true},
+		{NewDimensionValue(units.NewValue(2, units.Foot)), STRING, "2 ft", false},
+		{PropertyValue{}, DECIMAL, "0", true},
+	}
+
+	for _, c := range cases {
+		got, err := c.in.Convert(c.to)
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s %q Convert(%s) error == %v; want error: %v", c.in.Type, c.in.String(), c.to, err, c.wantErr)
+		}
+		if got.Type != c.to || got.String() != c.want {
+			t.Errorf("%s %q Convert(%s) == %s %q; want %q", c.in.Type, c.in.String(), c.to, got.Type, got.String(), c.want)
+		}
+	}
+}
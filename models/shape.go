@@ -0,0 +1,180 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/cpgillem/garden-planner/geometry"
+)
+
+// Kinds of feature outline.
+const (
+	SHAPE_RECTANGLE = "rectangle"
+	SHAPE_POLYGON   = "polygon"
+	SHAPE_ELLIPSE   = "ellipse"
+	SHAPE_ARC       = "arc"
+)
+
+var ShapeKinds = []string{SHAPE_RECTANGLE, SHAPE_POLYGON, SHAPE_ELLIPSE, SHAPE_ARC}
+
+// Outline of a feature inside its box. The outline is stored relative to the
+// box so moving or resizing the feature moves or stretches its outline.
+type FeatureShape struct {
+	Kind string `json:"kind"`
+
+	// Polygon corners as fractions of the box: (0, 0) is the top left corner
+	// and (1, 1) the bottom right.
+	Points []geometry.Vector `json:"points,omitempty"`
+
+	// Arc angles in degrees, clockwise from the right, and the width of the
+	// band as a fraction of the radius.
+	Start     float32 `json:"start,omitempty"`
+	Sweep     float32 `json:"sweep,omitempty"`
+	Thickness float32 `json:"thickness,omitempty"`
+}
+
+// Creates a new shape of a kind filling its box. Polygons start as a
+// diamond and arcs as the top half of a ring. Rectangles are nil.
+func NewFeatureShape(kind string) *FeatureShape {
+	switch kind {
+	case SHAPE_POLYGON:
+		return &FeatureShape{Kind: kind, Points: []geometry.Vector{
+			geometry.NewVector(0.5, 0, 0),
+			geometry.NewVector(1, 0.5, 0),
+			geometry.NewVector(0.5, 1, 0),
+			geometry.NewVector(0, 0.5, 0),
+		}}
+	case SHAPE_ELLIPSE:
+		return &FeatureShape{Kind: kind}
+	case SHAPE_ARC:
+		return &FeatureShape{Kind: kind, Start: 180, Sweep: 180, Thickness: 0.5}
+	}
+	return nil
+}
+
+func (s *FeatureShape) Copy() *FeatureShape {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	c.Points = make([]geometry.Vector, len(s.Points))
+	copy(c.Points, s.Points)
+	return &c
+}
+
+// Kind of the shape. Nil shapes are rectangles.
+func (s *FeatureShape) GetKind() string {
+	if s == nil || s.Kind == "" {
+		return SHAPE_RECTANGLE
+	}
+	return s.Kind
+}
+
+// The outline placed in a box.
+func (s *FeatureShape) In(box geometry.Box) geometry.Shape {
+	center := box.Center()
+	radiusX, radiusY := box.Size.X/2, box.Size.Y/2
+
+	switch s.GetKind() {
+	case SHAPE_POLYGON:
+		points := []geometry.Vector{}
+		for _, p := range s.Points {
+			points = append(points, geometry.NewVector(
+				box.Location.X+p.X*box.Size.X,
+				box.Location.Y+p.Y*box.Size.Y,
+				0,
+			))
+		}
+		return geometry.NewPolygon(points...)
+	case SHAPE_ELLIPSE:
+		return geometry.NewEllipse(center, radiusX, radiusY)
+	case SHAPE_ARC:
+		return &geometry.Arc{
+			Center:    center,
+			RadiusX:   radiusX,
+			RadiusY:   radiusY,
+			Start:     s.Start,
+			Sweep:     s.Sweep,
+			Thickness: s.Thickness,
+		}
+	}
+	return &box
+}
+
+// Box around polygon corners, and the corners as fractions of that box.
+func FitPoints(points []geometry.Vector) (geometry.Box, []geometry.Vector) {
+	box := geometry.NewPolygon(points...).Bounds()
+	fractions := []geometry.Vector{}
+	for _, p := range points {
+		f := geometry.NewVector(0, 0, 0)
+		if box.Size.X > 0 {
+			f.X = (p.X - box.Location.X) / box.Size.X
+		}
+		if box.Size.Y > 0 {
+			f.Y = (p.Y - box.Location.Y) / box.Size.Y
+		}
+		fractions = append(fractions, f)
+	}
+	return box, fractions
+}
+
+// Checks the kind and parameters of a shape.
+func (s *FeatureShape) Check() error {
+	if s == nil {
+		return nil
+	}
+
+	switch s.Kind {
+	case SHAPE_RECTANGLE, SHAPE_ELLIPSE:
+	case SHAPE_POLYGON:
+		if len(s.Points) < 3 {
+			return fmt.Errorf("polygon has %d points; needs at least 3", len(s.Points))
+		}
+	case SHAPE_ARC:
+		if s.Sweep <= 0 || s.Sweep > 360 {
+			return fmt.Errorf("arc sweep %v is not between 0 and 360 degrees", s.Sweep)
+		}
+		if s.Thickness <= 0 || s.Thickness > 1 {
+			return fmt.Errorf("arc thickness %v is not between 0 and 1", s.Thickness)
+		}
+	default:
+		return fmt.Errorf("unknown shape %q", s.Kind)
+	}
+	return nil
+}
+
+// Outline of a feature in plan coordinates, turned by its rotation and
+// those of its ancestors.
+func (p *Plan) Shape(id FeatureID) geometry.Shape {
+	f := p.Features[id]
+	if f == nil {
+		return nil
+	}
+	box, degrees := p.Placement(id)
+	return geometry.NewRotated(f.Shape.In(box), box.Center(), degrees)
+}
+
+// Topmost feature whose outline contains a point, or NoFeature. Nested
+// features are above their parents, and later IDs above earlier ones, the
+// same order they are drawn in.
+func (p *Plan) FeatureAt(point geometry.Vector) FeatureID {
+	ids := []FeatureID{}
+	for id := range p.Features {
+		ids = append(ids, id)
+	}
+	return p.TopmostAt(point, ids)
+}
+
+// Topmost of some features whose outline contains a point, or NoFeature.
+func (p *Plan) TopmostAt(point geometry.Vector, ids []FeatureID) FeatureID {
+	found, foundDepth := NoFeature, -1
+	for _, id := range ids {
+		if p.Features[id] == nil || !p.Shape(id).Contains(point) {
+			continue
+		}
+		depth := p.Depth(id)
+		if depth > foundDepth || (depth == foundDepth && id > found) {
+			found, foundDepth = id, depth
+		}
+	}
+	return found
+}
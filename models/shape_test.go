@@ -0,0 +1,73 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpgillem/garden-planner/geometry"
+)
+
+func TestFeatureAt(t *testing.T) {
+	bed, tree := NewFeatureID(), NewFeatureID()
+	plan := NewPlan()
+	plan.Features[bed] = &Feature{Name: "Bed", Box: geometry.NewBox(0, 0, 20, 20)}
+	plan.Features[tree] = &Feature{Name: "Tree", Parent: bed, Box: geometry.NewBox(0, 0, 10, 10), Shape: NewFeatureShape(SHAPE_ELLIPSE)}
+
+	tests := []struct {
+		p    geometry.Vector
+		want FeatureID
+	}{
+		{geometry.NewVector(5, 5, 0), tree},
+		// Inside the tree's box, but outside its canopy.
+		{geometry.NewVector(1, 1, 0), bed},
+		{geometry.NewVector(30, 30, 0), NoFeature},
+	}
+
+	for _, test := range tests {
+		if got := plan.FeatureAt(test.p); got != test.want {
+			t.Errorf("FeatureAt(%v) == %s; want %s", test.p, got, test.want)
+		}
+	}
+}
+
+func TestFeatureAtRotated(t *testing.T) {
+	id := NewFeatureID()
+	plan := NewPlan()
+	plan.Features[id] = &Feature{Name: "Bed", Box: geometry.NewBox(0, 9, 20, 2), Rotation: 90}
+
+	if got := plan.FeatureAt(geometry.NewVector(10, 1, 0)); got != id {
+		t.Errorf("FeatureAt() == %s above the center; want the turned bed", got)
+	}
+	if got := plan.FeatureAt(geometry.NewVector(1, 10, 0)); got != NoFeature {
+		t.Errorf("FeatureAt() == %s at the unturned end; want none", got)
+	}
+}
+
+func TestShapeTurnedWithParent(t *testing.T) {
+	bed, row := NewFeatureID(), NewFeatureID()
+	plan := NewPlan()
+	plan.Features[bed] = &Feature{Name: "Bed", Box: geometry.NewBox(0, 0, 20, 10), Rotation: 90}
+	plan.Features[row] = &Feature{Name: "Row", Parent: bed, Box: geometry.NewBox(0, 0, 20, 2)}
+
+	// The row along the top of the bed swings around the bed's center to
+	// its right side, and turns with it.
+	box, degrees := plan.Placement(row)
+	if center := box.Center(); !near(center.X, 14) || !near(center.Y, 5) || degrees != 90 {
+		t.Errorf("Placement() == %v, %v; want centered on (14, 5), turned 90", center, degrees)
+	}
+	bounds := plan.Shape(row).Bounds()
+	if !near(bounds.Location.X, 13) || !near(bounds.Location.Y, -5) || !near(bounds.Size.X, 2) || !near(bounds.Size.Y, 20) {
+		t.Errorf("Shape().Bounds() == %+v; want 2 wide and 20 high at (13, -5)", bounds)
+	}
+	if got := plan.FeatureAt(geometry.NewVector(14, 0, 0)); got != row {
+		t.Errorf("FeatureAt() == %s on the turned row; want the row", got)
+	}
+
+	if got := plan.Unturn(bed, box.Center()); !near(got.X, 10) || !near(got.Y, 1) {
+		t.Errorf("Unturn() == %v; want the row's unturned center (10, 1)", got)
+	}
+}
+
+func near(a float32, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-3
+}
@@ -0,0 +1,287 @@
+package models
+
+import (
+	"sort"
+
+	"github.com/cpgillem/garden-planner/validation"
+)
+
+// Checks property definitions for duplicates, unknown types and defaults that
+// don't match their type.
+func ValidateProperties(properties []Property, r *validation.Report) {
+	seen := map[string]bool{}
+	for i, p := range properties {
+		path := validation.Index(validation.Root(), i)
+
+		if p.Name == "" {
+			r.Errorf(validation.Key(path, "name"), "property has no name")
+		} else if seen[p.Name] {
+			r.Errorf(validation.Key(path, "name"), "duplicate property %q", p.Name)
+		}
+		seen[p.Name] = true
+
+		if !IsPropertyType(p.PropertyType) {
+			r.Errorf(validation.Key(path, "property_type"), "unknown property type %q", p.PropertyType)
+			continue
+		}
+
+		if p.PropertyType == ENUM && len(p.Options) == 0 {
+			r.Errorf(validation.Key(path, "options"), "enum property %q has no options", p.Name)
+		}
+
+		if p.PropertyType == DIMENSION {
+			if _, ok := UnitFamilies[p.Family()]; !ok {
+				r.Errorf(validation.Key(path, "unit_family"), "unknown unit family %q", p.UnitFamily)
+			}
+		}
+
+		// Limits must have the property's type.
+		limits := map[string]*PropertyValue{"min": p.Min, "max": p.Max, "step": p.Step}
+		for key, limit := range limits {
+			if limit == nil {
+				continue
+			}
+			if _, err := p.Convert(*limit); err != nil {
+				r.Errorf(validation.Key(path, key), "%s: %s", p.Name, err.Error())
+			}
+		}
+		if p.Min != nil && p.Max != nil {
+			if _, err := p.Check(*p.Min); err != nil {
+				r.Errorf(validation.Key(path, "min"), "%s: min %s does not fit the other constraints", p.Name, p.Min.String())
+			}
+		}
+
+		checkPropertyValue(p, p.Default, validation.Key(path, "default"), r)
+	}
+}
+
+// Checks that templates are unique and only use defined properties.
+func ValidateFeatureTemplates(templates []FeatureTemplate, properties map[string]Property, r *validation.Report) {
+	seen := map[string]bool{}
+	for i, t := range templates {
+		path := validation.Index(validation.Root(), i)
+
+		if t.Name == "" {
+			r.Errorf(validation.Key(path, "name"), "template has no name")
+		} else if seen[t.Name] {
+			r.Errorf(validation.Key(path, "name"), "duplicate template %q", t.Name)
+		}
+		seen[t.Name] = true
+
+		for j, name := range t.Properties {
+			if _, ok := properties[name]; !ok {
+				r.Errorf(validation.Index(validation.Key(path, "properties"), j), "property %q is not defined in properties.json", name)
+			}
+		}
+
+		if err := t.Shape.Check(); err != nil {
+			r.Errorf(validation.Key(path, "shape"), "%s: %s", t.Name, err.Error())
+		}
+	}
+}
+
+// Checks plants for duplicate IDs and interactions with unknown plants.
+func ValidatePlants(plants []Plant, r *validation.Report) {
+	ids := map[int]bool{}
+	for i, p := range plants {
+		path := validation.Index(validation.Root(), i)
+		if ids[p.ID] {
+			r.Errorf(validation.Key(path, "id"), "duplicate plant id %d (%s)", p.ID, p.Name)
+		}
+		ids[p.ID] = true
+
+		if p.Name == "" {
+			r.Warnf(validation.Key(path, "name"), "plant %d has no name", p.ID)
+		}
+	}
+
+	for i, p := range plants {
+		targets := map[int]bool{}
+		for j, interaction := range p.Interactions {
+			path := validation.Index(validation.Key(validation.Index(validation.Root(), i), "interactions"), j)
+
+			switch {
+			case !ids[interaction.TargetPlantID]:
+				r.Errorf(validation.Key(path, "target_plant_id"), "%s interacts with unknown plant %d", p.Name, interaction.TargetPlantID)
+			case interaction.TargetPlantID == p.ID:
+				r.Warnf(validation.Key(path, "target_plant_id"), "%s interacts with itself", p.Name)
+			case targets[interaction.TargetPlantID]:
+				r.Warnf(validation.Key(path, "target_plant_id"), "%s lists plant %d more than once", p.Name, interaction.TargetPlantID)
+			}
+			targets[interaction.TargetPlantID] = true
+
+			if interaction.InteractionType > ANTAGONISTIC {
+				r.Errorf(validation.Key(path, "interaction_type"), "unknown interaction type %d", interaction.InteractionType)
+			}
+		}
+	}
+}
+
+// Checks feature IDs, boxes and property values of a plan, and that
+// features made from a template have its required properties.
+func ValidatePlan(plan *Plan, properties map[string]Property, templates map[string]FeatureTemplate, r *validation.Report) {
+	if plan.Box.GetWidth() < 0 || plan.Box.GetHeight() < 0 {
+		r.Errorf(validation.Key(validation.Root(), "box"), "plan has a negative size")
+	}
+
+	featuresPath := validation.Key(validation.Root(), "features")
+	for id, f := range plan.Features {
+		path := validation.Key(featuresPath, string(id))
+
+		if !id.IsValid() {
+			r.Errorf(path, "feature ID %q is not a UUID", id)
+		}
+		if f == nil {
+			r.Errorf(path, "feature is empty")
+			continue
+		}
+		if f.Box.GetWidth() < 0 || f.Box.GetHeight() < 0 {
+			r.Errorf(validation.Key(path, "box"), "%s has a negative size", f.Name)
+		}
+		if err := f.Shape.Check(); err != nil {
+			r.Errorf(validation.Key(path, "shape"), "%s: %s", f.Name, err.Error())
+		}
+		if f.Parent != NoFeature {
+			if plan.Features[f.Parent] == nil {
+				r.Errorf(validation.Key(path, "parent"), "%s is nested in missing feature %s", f.Name, f.Parent)
+			} else if plan.InCycle(id) {
+				r.Errorf(validation.Key(path, "parent"), "%s is nested inside itself", f.Name)
+			}
+		}
+
+		for name, value := range f.Properties {
+			propertyPath := validation.Key(validation.Key(path, "properties"), name)
+			property, ok := properties[name]
+			if !ok {
+				r.Warnf(propertyPath, "unknown property %q", name)
+				continue
+			}
+			checkPropertyValue(property, value, propertyPath, r)
+		}
+
+		if t, ok := templates[f.Template]; ok {
+			for _, name := range t.Properties {
+				if _, ok := f.Properties[name]; !ok && properties[name].Required {
+					r.Errorf(validation.Key(path, "properties"), "%s is missing required property %q", f.Name, name)
+				}
+			}
+		}
+	}
+
+	validateIrrigation(plan, r)
+}
+
+// Checks that irrigation tubes and zones refer to things in the plan.
+func validateIrrigation(plan *Plan, r *validation.Report) {
+	irrigation := &plan.Irrigation
+	irrigationPath := validation.Key(validation.Root(), "irrigation")
+
+	zonesPath := validation.Key(irrigationPath, "zones")
+	for id, z := range irrigation.Zones {
+		path := validation.Key(zonesPath, string(id))
+		if z == nil {
+			r.Errorf(path, "zone is empty")
+			continue
+		}
+		if source := irrigation.Nodes[z.Source]; source == nil || !source.Source {
+			r.Errorf(validation.Key(path, "source"), "zone %s has no water source", z.Name)
+		}
+		if z.RunTime <= 0 {
+			r.Warnf(validation.Key(path, "run_time"), "zone %s never runs", z.Name)
+		}
+	}
+
+	tubesPath := validation.Key(irrigationPath, "tubes")
+	for id, t := range irrigation.Tubes {
+		path := validation.Key(tubesPath, string(id))
+		if t == nil {
+			r.Errorf(path, "tube is empty")
+			continue
+		}
+		if irrigation.Nodes[t.From] == nil || irrigation.Nodes[t.To] == nil {
+			r.Errorf(path, "tube is connected to a missing node")
+		}
+		if irrigation.Zones[t.Zone] == nil {
+			r.Errorf(validation.Key(path, "zone"), "tube is in missing zone %s", t.Zone)
+		}
+		if t.Kind > LATERAL {
+			r.Errorf(validation.Key(path, "kind"), "unknown tube kind %d", t.Kind)
+		}
+		if t.Feature != NoFeature && plan.Features[t.Feature] == nil {
+			r.Warnf(validation.Key(path, "feature"), "tube waters missing feature %s", t.Feature)
+		}
+	}
+}
+
+// Checks that a value can be used as its property's type and fits its
+// constraints.
+func checkPropertyValue(property Property, value PropertyValue, path string, r *validation.Report) {
+	if _, err := property.Check(value); err != nil {
+		r.Errorf(path, "%s: %s", property.Name, err.Error())
+	}
+}
+
+// Checks that plant references in a plan point to known plants.
+func ValidatePlantReferences(plan *Plan, plants []Plant, r *validation.Report) {
+	ids := map[int]bool{}
+	for _, p := range plants {
+		ids[p.ID] = true
+	}
+
+	featuresPath := validation.Key(validation.Root(), "features")
+	for id, f := range plan.Features {
+		if f == nil {
+			continue
+		}
+		for name, value := range f.Properties {
+			if value.Type != PLANT {
+				continue
+			}
+			plantID, _ := value.PlantID()
+			if plantID != NO_PLANT && !ids[plantID] {
+				path := validation.Key(validation.Key(validation.Key(featuresPath, string(id)), "properties"), name)
+				r.Warnf(path, "%s references unknown plant %d", f.Name, plantID)
+			}
+		}
+	}
+}
+
+// Converts every property value in a plan to the type of its definition, so
+// that e.g. legacy numbers become dimensions. Values that can't be converted
+// are left as they are; ValidatePlan reports them.
+func NormalizePropertyValues(plan *Plan, properties map[string]Property) {
+	for _, f := range plan.Features {
+		if f == nil {
+			continue
+		}
+		for name, value := range f.Properties {
+			property, ok := properties[name]
+			if !ok {
+				continue
+			}
+			if converted, err := property.Convert(value); err == nil {
+				f.Properties[name] = converted
+			}
+		}
+	}
+}
+
+// Makes features with a missing parent, or that are nested inside
+// themselves, root features. ValidatePlan reports them.
+func NormalizeParents(plan *Plan) {
+	ids := []FeatureID{}
+	for id := range plan.Features {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		f := plan.Features[id]
+		if f == nil || f.Parent == NoFeature {
+			continue
+		}
+		if plan.Features[f.Parent] == nil || plan.InCycle(id) {
+			f.Parent = NoFeature
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cpgillem/garden-planner/validation"
+)
+
+func TestValidatePlanRequired(t *testing.T) {
+	properties := map[string]Property{
+		"row_width": {Name: "row_width", PropertyType: DIMENSION, UnitFamily: "length", Required: true},
+		"notes":     {Name: "notes", PropertyType: STRING},
+	}
+	templates := map[string]FeatureTemplate{
+		"row": {Name: "row", DisplayName: "Row", Properties: []string{"row_width", "notes"}},
+	}
+
+	plan := NewPlan()
+	row := NewFeature(properties, &FeatureTemplate{Name: "row", DisplayName: "Row"})
+	plan.Features[NewFeatureID()] = &row
+	loose := Feature{Name: "Loose", Properties: map[string]PropertyValue{}}
+	plan.Features[NewFeatureID()] = &loose
+
+	r := validation.NewReport()
+	ValidatePlan(plan, properties, templates, r)
+
+	if got := r.Count(validation.ERROR); got != 1 {
+		t.Fatalf("Count(ERROR) == %d; want 1:\n%s", got, r.String())
+	}
+	if !strings.Contains(r.String(), `"row_width"`) {
+		t.Errorf("report does not name the missing property:\n%s", r.String())
+	}
+}
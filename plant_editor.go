@@ -1,15 +1,33 @@
 package main
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/cpgillem/garden-planner/controllers"
+	"github.com/cpgillem/garden-planner/models"
 	"github.com/cpgillem/garden-planner/ui"
 )
 
-type FeatureEditor struct {
+// Family filter option that shows every plant.
+const allFamilies = "All families"
+
+// Window for maintaining the plant database shared by all plans.
+type PlantEditor struct {
+	instance *GardenPlanner
+
 	// Data
 	controller *controllers.PlantController
+	plants     []models.Plant
+	selectedID int
 
 	// Window
 	window fyne.Window
@@ -19,13 +37,299 @@ type FeatureEditor struct {
 	plantForm     *fyne.Container
 
 	// Widgets
-	toolbar   *widget.Toolbar
-	plantList *widget.List
+	toolbar     *widget.Toolbar
+	searchEntry *widget.Entry
+	plantList   *widget.List
 
 	// Form Widgets
 	idLabel           *widget.Label
 	nameLabel         *widget.Label
 	nameEntry         *widget.Entry
+	familyLabel       *widget.Label
+	familyEntry       *widget.Entry
 	interactionLabel  *widget.Label
 	interactionEditor *ui.InteractionEditor
+
+	// Matrix Widgets
+	familySelect      *widget.Select
+	sortSelect        *widget.Select
+	interactionMatrix *ui.InteractionMatrix
+}
+
+func NewPlantEditor(instance *GardenPlanner) PlantEditor {
+	e := PlantEditor{
+		instance:          instance,
+		controller:        &instance.PlantController,
+		plants:            []models.Plant{},
+		selectedID:        models.NO_PLANT,
+		window:            instance.App.NewWindow("Plants"),
+		toolbar:           widget.NewToolbar(),
+		searchEntry:       widget.NewEntry(),
+		idLabel:           widget.NewLabel(""),
+		nameLabel:         widget.NewLabel("Name"),
+		nameEntry:         widget.NewEntry(),
+		familyLabel:       widget.NewLabel("Family"),
+		familyEntry:       widget.NewEntry(),
+		interactionLabel:  widget.NewLabel("Interactions"),
+		interactionEditor: ui.NewInteractionEditor(&instance.PlantController),
+		interactionMatrix: ui.NewInteractionMatrix(&instance.PlantController),
+	}
+
+	// Keep the window around to show again.
+	e.window.SetCloseIntercept(e.window.Hide)
+	e.window.Resize(fyne.NewSize(700, 450))
+
+	return e
+}
+
+// Builds the window contents. Separate from NewPlantEditor so callbacks
+// refer to the editor stored in the app rather than a copy.
+func (e *PlantEditor) Setup() {
+	e.toolbar.Append(widget.NewToolbarAction(theme.ContentAddIcon(), e.AddPlant))
+	e.toolbar.Append(widget.NewToolbarAction(theme.DeleteIcon(), e.RemoveSelected))
+	e.toolbar.Append(widget.NewToolbarSeparator())
+	e.toolbar.Append(widget.NewToolbarAction(theme.ContentUndoIcon(), e.Undo))
+	e.toolbar.Append(widget.NewToolbarAction(theme.ContentRedoIcon(), e.Redo))
+
+	// Undo: Ctrl+Z, Redo: Ctrl+Shift+Z, as in the main window.
+	e.window.Canvas().AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyZ,
+		Modifier: fyne.KeyModifierShortcutDefault,
+	}, func(shortcut fyne.Shortcut) {
+		e.Undo()
+	})
+	e.window.Canvas().AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyZ,
+		Modifier: fyne.KeyModifierShortcutDefault | fyne.KeyModifierShift,
+	}, func(shortcut fyne.Shortcut) {
+		e.Redo()
+	})
+
+	e.searchEntry.SetPlaceHolder("Search plants...")
+	e.searchEntry.OnChanged = func(s string) {
+		e.Refresh()
+	}
+
+	e.plantList = widget.NewList(
+		func() int {
+			return len(e.plants)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(e.plants[i].Name)
+		},
+	)
+	e.plantList.OnSelected = func(i widget.ListItemID) {
+		e.SelectPlant(e.plants[i].ID)
+	}
+
+	e.nameEntry.OnSubmitted = func(s string) {
+		e.updateSelected(func(p *models.Plant) error {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				return fmt.Errorf("plant name can't be empty")
+			}
+			if other, ok := e.controller.FindPlantByName(s); ok && other.ID != p.ID {
+				return fmt.Errorf("there is already a plant named %s", other.Name)
+			}
+			p.Name = s
+			return nil
+		})
+	}
+	e.familyEntry.OnSubmitted = func(s string) {
+		e.updateSelected(func(p *models.Plant) error {
+			p.Family = strings.TrimSpace(s)
+			return nil
+		})
+	}
+
+	e.interactionEditor.OnError = func(err error) {
+		dialog.ShowError(err, e.window)
+	}
+
+	e.plantForm = container.New(
+		layout.NewFormLayout(),
+		widget.NewLabel("ID"),
+		e.idLabel,
+		e.nameLabel,
+		e.nameEntry,
+		e.familyLabel,
+		e.familyEntry,
+	)
+
+	listContainer := container.NewBorder(e.searchEntry, nil, nil, nil, e.plantList)
+	formContainer := container.NewBorder(
+		container.NewVBox(e.plantForm, e.interactionLabel),
+		nil, nil, nil,
+		e.interactionEditor,
+	)
+	split := container.NewHSplit(listContainer, formContainer)
+	split.Offset = 0.3
+
+	// Matrix of all interactions, for auditing the whole database.
+	e.interactionMatrix.OnError = func(err error) {
+		dialog.ShowError(err, e.window)
+	}
+	e.familySelect = widget.NewSelect([]string{}, func(s string) {
+		if s == allFamilies {
+			s = ""
+		}
+		e.interactionMatrix.SetFamily(s)
+	})
+	e.sortSelect = widget.NewSelect(ui.MatrixSortOrders, e.interactionMatrix.SetSortOrder)
+	e.sortSelect.SetSelected(ui.SORT_BY_NAME)
+	matrixTools := container.NewHBox(
+		widget.NewLabel("Family"),
+		e.familySelect,
+		widget.NewLabel("Sort by"),
+		e.sortSelect,
+	)
+	legend := widget.NewLabel("Rows are affected by columns. Green: beneficial, red: antagonistic. Orange outlines mark pairs that disagree; tap a cell to change it.")
+	legend.Wrapping = fyne.TextWrapWord
+	matrixContainer := container.NewBorder(
+		matrixTools,
+		legend,
+		nil, nil,
+		container.NewScroll(e.interactionMatrix),
+	)
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem("Plants", split),
+		container.NewTabItem("Interaction Matrix", matrixContainer),
+	)
+
+	e.mainContainer = container.NewBorder(e.toolbar, nil, nil, nil, tabs)
+	e.window.SetContent(e.mainContainer)
+
+	e.SelectPlant(models.NO_PLANT)
+}
+
+func (e *PlantEditor) Show() {
+	e.Refresh()
+	e.window.Show()
+}
+
+// Reloads the plant list and the selected plant, e.g. after the database
+// changed.
+func (e *PlantEditor) Refresh() {
+	search := strings.ToLower(strings.TrimSpace(e.searchEntry.Text))
+	e.plants = []models.Plant{}
+	for _, p := range e.controller.Plants() {
+		if strings.Contains(strings.ToLower(p.Name), search) || strings.Contains(strings.ToLower(p.Family), search) {
+			e.plants = append(e.plants, p)
+		}
+	}
+	e.plantList.Refresh()
+
+	// Keep the list selection in step with the edited plant.
+	e.plantList.UnselectAll()
+	for i, p := range e.plants {
+		if p.ID == e.selectedID {
+			e.plantList.Select(i)
+		}
+	}
+
+	if !e.controller.HasPlant(e.selectedID) {
+		e.selectedID = models.NO_PLANT
+	}
+	e.showPlant()
+
+	// Families may have been added or renamed.
+	e.familySelect.Options = append([]string{allFamilies}, e.controller.Families()...)
+	known := false
+	for _, family := range e.familySelect.Options {
+		known = known || family == e.familySelect.Selected
+	}
+	if !known {
+		e.familySelect.SetSelected(allFamilies)
+	}
+	e.familySelect.Refresh()
+	e.interactionMatrix.Refresh()
+}
+
+// Shows a plant in the form. NO_PLANT empties and disables the form.
+func (e *PlantEditor) SelectPlant(id int) {
+	e.selectedID = id
+	e.showPlant()
+}
+
+func (e *PlantEditor) showPlant() {
+	plant, ok := e.controller.GetPlant(e.selectedID)
+	if !ok {
+		e.idLabel.SetText("")
+		e.nameEntry.SetText("")
+		e.nameEntry.Disable()
+		e.familyEntry.SetText("")
+		e.familyEntry.Disable()
+		e.interactionEditor.SetPlant(models.NO_PLANT)
+		return
+	}
+
+	e.idLabel.SetText(strconv.Itoa(plant.ID))
+	e.nameEntry.SetText(plant.Name)
+	e.nameEntry.Enable()
+	e.familyEntry.SetText(plant.Family)
+	e.familyEntry.Enable()
+	e.interactionEditor.SetPlant(plant.ID)
+}
+
+// Adds a blank plant and selects it for editing.
+func (e *PlantEditor) AddPlant() {
+	name := "New Plant"
+	for i := 2; ; i++ {
+		if _, taken := e.controller.FindPlantByName(name); !taken {
+			break
+		}
+		name = fmt.Sprintf("New Plant %d", i)
+	}
+
+	plant, err := e.controller.AddPlant(models.Plant{Name: name, Interactions: []models.PlantInteraction{}})
+	if err != nil {
+		dialog.ShowError(err, e.window)
+		return
+	}
+
+	e.searchEntry.SetText("")
+	e.selectedID = plant.ID
+	e.Refresh()
+	e.window.Canvas().Focus(e.nameEntry)
+}
+
+func (e *PlantEditor) RemoveSelected() {
+	if e.controller.HasPlant(e.selectedID) {
+		e.instance.ConfirmRemovePlant(e.selectedID, e.window)
+	}
+}
+
+func (e *PlantEditor) Undo() {
+	if err := e.controller.Undo(); err != nil {
+		dialog.ShowError(err, e.window)
+	}
+}
+
+func (e *PlantEditor) Redo() {
+	if err := e.controller.Redo(); err != nil {
+		dialog.ShowError(err, e.window)
+	}
+}
+
+// Applies a change to the selected plant, showing an error if it fails.
+func (e *PlantEditor) updateSelected(change func(p *models.Plant) error) {
+	plant, ok := e.controller.GetPlant(e.selectedID)
+	if !ok {
+		return
+	}
+
+	if err := change(&plant); err != nil {
+		dialog.ShowError(err, e.window)
+		e.showPlant()
+		return
+	}
+
+	if err := e.controller.UpdatePlant(plant); err != nil {
+		dialog.ShowError(err, e.window)
+		e.showPlant()
+	}
 }
@@ -16,8 +16,13 @@ type SettingsWindow struct {
 	instance *GardenPlanner
 	window   fyne.Window
 
-	systemEntry *widget.SelectEntry
-	gridEntry   *ui.DimensionEntry
+	systemEntry    *widget.SelectEntry
+	gridEntry      *ui.DimensionEntry
+	snapGridCheck  *widget.Check
+	snapAlignCheck *widget.Check
+	companionEntry *ui.DimensionEntry
+	pathEntry      *ui.DimensionEntry
+	overlapCheck   *widget.Check
 
 	okButton     *widget.Button
 	cancelButton *widget.Button
@@ -35,6 +40,17 @@ func NewSettingsWindow(instance *GardenPlanner) SettingsWindow {
 			units.NewValue(0, ui.AnyUnit),
 			instance.Formatter,
 		),
+		snapGridCheck:  widget.NewCheck("Snap to Grid", func(bool) {}),
+		snapAlignCheck: widget.NewCheck("Line Up With Features", func(bool) {}),
+		companionEntry: ui.NewDimensionEntry(
+			units.NewValue(0, ui.AnyUnit),
+			instance.Formatter,
+		),
+		pathEntry: ui.NewDimensionEntry(
+			units.NewValue(0, ui.AnyUnit),
+			instance.Formatter,
+		),
+		overlapCheck: widget.NewCheck("Prevent Overlap", func(bool) {}),
 		okButton:     widget.NewButton("OK", func() {}),
 		cancelButton: widget.NewButton("Cancel", func() {}),
 		OnOk:         func() {},
@@ -46,6 +62,8 @@ func NewSettingsWindow(instance *GardenPlanner) SettingsWindow {
 
 	systemLabel := widget.NewLabel("Measurement System")
 	gridLabel := widget.NewLabel("Grid Spacing")
+	companionLabel := widget.NewLabel("Companion Distance")
+	pathLabel := widget.NewLabel("Minimum Path Width")
 
 	// Containers
 	measurementForm := container.New(
@@ -54,9 +72,23 @@ func NewSettingsWindow(instance *GardenPlanner) SettingsWindow {
 		w.systemEntry,
 		gridLabel,
 		w.gridEntry,
+		layout.NewSpacer(),
+		w.snapGridCheck,
+		layout.NewSpacer(),
+		w.snapAlignCheck,
 	)
 	measurementTab := container.NewTabItem("Measurement", measurementForm)
-	settingsTabs := container.NewAppTabs(measurementTab)
+	plantingForm := container.New(
+		layout.NewFormLayout(),
+		companionLabel,
+		w.companionEntry,
+		pathLabel,
+		w.pathEntry,
+		layout.NewSpacer(),
+		w.overlapCheck,
+	)
+	plantingTab := container.NewTabItem("Planting", plantingForm)
+	settingsTabs := container.NewAppTabs(measurementTab, plantingTab)
 	buttonContainer := container.NewHBox(w.cancelButton, w.okButton)
 	settingsWinContainer := container.NewVBox(settingsTabs, buttonContainer)
 	w.window.SetContent(settingsWinContainer)
@@ -85,6 +117,29 @@ func (w *SettingsWindow) Show() {
 		w.gridEntry.SetValueAndBaseUnit(v)
 	}
 
+	// Snapping
+	w.snapGridCheck.SetChecked(w.instance.App.Preferences().BoolWithFallback("snap_to_grid", true))
+	w.snapAlignCheck.SetChecked(w.instance.App.Preferences().BoolWithFallback("snap_to_features", true))
+
+	// Companion distance
+	v, err = w.instance.Formatter.ToDimension(w.instance.App.Preferences().StringWithFallback("companion_distance", DEFAULT_COMPANION_DISTANCE))
+	if err != nil {
+		fmt.Println(err.Error())
+		w.companionEntry.SetValueAndBaseUnit(units.NewValue(24, units.Inch))
+	} else {
+		w.companionEntry.SetValueAndBaseUnit(v)
+	}
+
+	// Path width
+	v, err = w.instance.Formatter.ToDimension(w.instance.App.Preferences().StringWithFallback("path_width", DEFAULT_PATH_WIDTH))
+	if err != nil {
+		fmt.Println(err.Error())
+		w.pathEntry.SetValueAndBaseUnit(units.NewValue(0, units.Inch))
+	} else {
+		w.pathEntry.SetValueAndBaseUnit(v)
+	}
+	w.overlapCheck.SetChecked(w.instance.App.Preferences().Bool("prevent_overlap"))
+
 	// Show window
 	w.window.Show()
 }
@@ -104,6 +159,17 @@ func (w *SettingsWindow) OK() {
 	// Grid spacing
 	w.instance.App.Preferences().SetString("grid_spacing", w.gridEntry.GetValueAsText())
 
+	// Snapping
+	w.instance.App.Preferences().SetBool("snap_to_grid", w.snapGridCheck.Checked)
+	w.instance.App.Preferences().SetBool("snap_to_features", w.snapAlignCheck.Checked)
+
+	// Companion distance
+	w.instance.App.Preferences().SetString("companion_distance", w.companionEntry.GetValueAsText())
+
+	// Collisions
+	w.instance.App.Preferences().SetString("path_width", w.pathEntry.GetValueAsText())
+	w.instance.App.Preferences().SetBool("prevent_overlap", w.overlapCheck.Checked)
+
 	w.Close()
 	w.OnOk()
 }
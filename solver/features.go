@@ -0,0 +1,42 @@
+package solver
+
+import (
+	"fmt"
+
+	"github.com/cpgillem/garden-planner/models"
+)
+
+// Turns placements into features made from a template, named after their
+// plants. The template's first plant property is set to each row's plant.
+// Boxes are in the same coordinates as the problem's area.
+func (s Solution) Features(template *models.FeatureTemplate, properties map[string]models.Property, plants []models.Plant) []models.Feature {
+	names := map[int]string{}
+	for _, p := range plants {
+		names[p.ID] = p.Name
+	}
+
+	// Find the property holding the plant.
+	plantProperty := ""
+	for _, name := range template.Properties {
+		if p, ok := properties[name]; ok && p.PropertyType == models.PLANT {
+			plantProperty = name
+			break
+		}
+	}
+
+	features := []models.Feature{}
+	counts := map[int]int{}
+	for _, placement := range s.Placements {
+		counts[placement.PlantID]++
+
+		f := models.NewFeature(properties, template)
+		f.Box = placement.Box.Copy()
+		f.Name = fmt.Sprintf("%s %d", names[placement.PlantID], counts[placement.PlantID])
+		if plantProperty != "" {
+			f.Properties[plantProperty] = models.NewPlantValue(placement.PlantID)
+		}
+		features = append(features, f)
+	}
+
+	return features
+}
@@ -0,0 +1,269 @@
+// Package solver proposes where to put plant rows in a garden, keeping
+// antagonistic plants apart and beneficial plants together.
+package solver
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/cpgillem/garden-planner/geometry"
+	"github.com/cpgillem/garden-planner/models"
+)
+
+// Rows of one plant to place. Sizes are in base units.
+type Request struct {
+	PlantID  int
+	Quantity int
+
+	// Size of each row. Rows may be turned to fit.
+	RowWidth  float32
+	RowLength float32
+}
+
+// Everything the solver needs to know about the garden.
+type Problem struct {
+	// Area to fill and boxes in it that rows must not cover.
+	Area      geometry.Box
+	Obstacles []geometry.Box
+
+	Requests []Request
+	Plants   []models.Plant
+
+	// Minimum gap between rows, and between rows and obstacles.
+	Clearance float32
+
+	// Rows at most this far apart count as neighbours. Should be at least
+	// Clearance, or no rows will ever be neighbours.
+	NeighbourDistance float32
+
+	// Same seed, same problem, same solution.
+	Seed int64
+
+	// Number of orderings to try. The first is always largest first.
+	Iterations int
+}
+
+// A proposed row.
+type Placement struct {
+	PlantID int
+	Box     geometry.Box
+}
+
+type Solution struct {
+	Placements []Placement
+
+	// Plants of rows that didn't fit, one entry per row.
+	Unplaced []int
+
+	// Neighbouring pairs by combined interaction.
+	BeneficialPairs   int
+	AntagonisticPairs int
+
+	Score float64
+}
+
+// Weights of the parts of a solution's score. Placing every row matters
+// most, then keeping enemies apart, then friends together, then compactness.
+const (
+	placedWeight       = 1000.0
+	antagonisticWeight = -500.0
+	beneficialWeight   = 50.0
+	compactnessWeight  = -1.0
+)
+
+// Proposes row placements. Tries Iterations orderings of the rows, each
+// placed greedily, and keeps the best.
+func Solve(p Problem) Solution {
+	if p.Iterations < 1 {
+		p.Iterations = 1
+	}
+
+	plants := map[int]models.Plant{}
+	for _, plant := range p.Plants {
+		plants[plant.ID] = plant
+	}
+
+	// One item per row, largest first.
+	items := []Request{}
+	for _, r := range p.Requests {
+		for i := 0; i < r.Quantity; i++ {
+			items = append(items, r)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].RowWidth*items[i].RowLength > items[j].RowWidth*items[j].RowLength
+	})
+
+	random := rand.New(rand.NewSource(p.Seed))
+	best := Solution{}
+	for i := 0; i < p.Iterations; i++ {
+		order := append([]Request{}, items...)
+		if i > 0 {
+			random.Shuffle(len(order), func(a, b int) {
+				order[a], order[b] = order[b], order[a]
+			})
+		}
+
+		s := p.place(order, plants)
+		if i == 0 || s.Score > best.Score {
+			best = s
+		}
+	}
+
+	return best
+}
+
+// Places rows in order, each at the best free spot.
+func (p *Problem) place(order []Request, plants map[int]models.Plant) Solution {
+	s := Solution{Placements: []Placement{}, Unplaced: []int{}}
+
+	for _, item := range order {
+		candidate, ok := p.bestSpot(item, s.Placements, plants)
+		if !ok {
+			s.Unplaced = append(s.Unplaced, item.PlantID)
+			continue
+		}
+		s.Placements = append(s.Placements, candidate)
+	}
+
+	p.score(&s, plants)
+	return s
+}
+
+// Finds the best spot for a row, trying both orientations at every corner
+// next to the area edge, obstacles and rows placed so far.
+func (p *Problem) bestSpot(item Request, placed []Placement, plants map[int]models.Plant) (Placement, bool) {
+	blocked := append([]geometry.Box{}, p.Obstacles...)
+	for _, other := range placed {
+		blocked = append(blocked, other.Box)
+	}
+
+	best := Placement{}
+	bestScore := 0.0
+	found := false
+
+	sizes := [][2]float32{{item.RowWidth, item.RowLength}, {item.RowLength, item.RowWidth}}
+	for _, point := range p.corners(blocked) {
+		for _, size := range sizes {
+			box := geometry.NewBox(point.X, point.Y, size[0], size[1])
+			if !p.fits(box, blocked) {
+				continue
+			}
+
+			candidate := Placement{PlantID: item.PlantID, Box: box}
+			score := p.spotScore(candidate, placed, plants)
+			if !found || score > bestScore {
+				best, bestScore, found = candidate, score, true
+			}
+		}
+	}
+
+	return best, found
+}
+
+// Candidate top-left corners: the area's corner, and points just past the
+// right and bottom edges of every blocked box.
+func (p *Problem) corners(blocked []geometry.Box) []geometry.Vector {
+	points := []geometry.Vector{p.Area.Location.Copy()}
+	for _, b := range blocked {
+		right := b.GetX() + b.GetWidth() + p.Clearance
+		bottom := b.GetY() + b.GetHeight() + p.Clearance
+		points = append(points,
+			geometry.NewVector(right, b.GetY(), 0),
+			geometry.NewVector(b.GetX(), bottom, 0),
+			geometry.NewVector(right, p.Area.GetY(), 0),
+			geometry.NewVector(p.Area.GetX(), bottom, 0),
+		)
+	}
+	return points
+}
+
+// Whether a box lies inside the area and keeps its clearance from every
+// blocked box.
+func (p *Problem) fits(box geometry.Box, blocked []geometry.Box) bool {
+	if box.GetX() < p.Area.GetX() || box.GetY() < p.Area.GetY() ||
+		box.GetX()+box.GetWidth() > p.Area.GetX()+p.Area.GetWidth() ||
+		box.GetY()+box.GetHeight() > p.Area.GetY()+p.Area.GetHeight() {
+		return false
+	}
+
+	for i := range blocked {
+		if overlaps(&box, &blocked[i]) || box.Distance(&blocked[i]) < p.Clearance {
+			return false
+		}
+	}
+	return true
+}
+
+// How good a spot is for one row, given the rows already placed.
+func (p *Problem) spotScore(candidate Placement, placed []Placement, plants map[int]models.Plant) float64 {
+	score := 0.0
+	for _, other := range placed {
+		if candidate.Box.Distance(&other.Box) > p.NeighbourDistance {
+			continue
+		}
+		switch combined(plants, candidate.PlantID, other.PlantID) {
+		case models.BENEFICIAL:
+			score += beneficialWeight
+		case models.ANTAGONISTIC:
+			score += antagonisticWeight
+		}
+	}
+
+	// Prefer spots near the area's corner, so rows pack together.
+	score += compactnessWeight * float64(candidate.Box.GetX()-p.Area.GetX()+candidate.Box.GetY()-p.Area.GetY())
+	return score
+}
+
+// Scores a whole solution and counts its neighbouring pairs.
+func (p *Problem) score(s *Solution, plants map[int]models.Plant) {
+	for i := range s.Placements {
+		for j := i + 1; j < len(s.Placements); j++ {
+			a, b := s.Placements[i], s.Placements[j]
+			if a.Box.Distance(&b.Box) > p.NeighbourDistance {
+				continue
+			}
+			switch combined(plants, a.PlantID, b.PlantID) {
+			case models.BENEFICIAL:
+				s.BeneficialPairs++
+			case models.ANTAGONISTIC:
+				s.AntagonisticPairs++
+			}
+		}
+	}
+
+	// Size of the box around all rows, so tighter layouts win ties.
+	extent := float64(0)
+	if len(s.Placements) > 0 {
+		minX, minY := s.Placements[0].Box.GetX(), s.Placements[0].Box.GetY()
+		maxX, maxY := minX, minY
+		for _, pl := range s.Placements {
+			minX = min(minX, pl.Box.GetX())
+			minY = min(minY, pl.Box.GetY())
+			maxX = max(maxX, pl.Box.GetX()+pl.Box.GetWidth())
+			maxY = max(maxY, pl.Box.GetY()+pl.Box.GetHeight())
+		}
+		extent = float64(maxX - minX + maxY - minY)
+	}
+
+	s.Score = placedWeight*float64(len(s.Placements)) +
+		antagonisticWeight*float64(s.AntagonisticPairs) +
+		beneficialWeight*float64(s.BeneficialPairs) +
+		compactnessWeight*extent
+}
+
+// Combined interaction of two plants by ID. Unknown plants are neutral.
+func combined(plants map[int]models.Plant, a int, b int) uint16 {
+	plantA, okA := plants[a]
+	plantB, okB := plants[b]
+	if !okA || !okB || a == b {
+		return models.NEUTRAL
+	}
+	return models.CombinedInteraction(plantA, plantB)
+}
+
+// Whether two boxes share any area. Touching edges don't count.
+func overlaps(a *geometry.Box, b *geometry.Box) bool {
+	return a.GetX() < b.GetX()+b.GetWidth() && b.GetX() < a.GetX()+a.GetWidth() &&
+		a.GetY() < b.GetY()+b.GetHeight() && b.GetY() < a.GetY()+a.GetHeight()
+}
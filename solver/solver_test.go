@@ -0,0 +1,65 @@
+package solver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cpgillem/garden-planner/geometry"
+	"github.com/cpgillem/garden-planner/models"
+)
+
+func testProblem(seed int64) Problem {
+	return Problem{
+		Area:      geometry.NewBox(0, 0, 100, 60),
+		Obstacles: []geometry.Box{geometry.NewBox(40, 0, 20, 20)},
+		Requests: []Request{
+			{PlantID: 1, Quantity: 2, RowWidth: 10, RowLength: 40},
+			{PlantID: 2, Quantity: 2, RowWidth: 10, RowLength: 40},
+			{PlantID: 3, Quantity: 1, RowWidth: 10, RowLength: 40},
+		},
+		Plants: []models.Plant{
+			{ID: 1, Name: "Potato", Interactions: []models.PlantInteraction{{TargetPlantID: 2, InteractionType: models.BENEFICIAL}}},
+			{ID: 2, Name: "Bean"},
+			{ID: 3, Name: "Tomato", Interactions: []models.PlantInteraction{{TargetPlantID: 1, InteractionType: models.ANTAGONISTIC}}},
+		},
+		Clearance:         4,
+		NeighbourDistance: 6,
+		Seed:              seed,
+		Iterations:        20,
+	}
+}
+
+func TestSolveIsDeterministic(t *testing.T) {
+	a := Solve(testProblem(7))
+	b := Solve(testProblem(7))
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("Solve() with the same seed gave different solutions:\n%v\n%v", a, b)
+	}
+}
+
+func TestSolveRespectsConstraints(t *testing.T) {
+	p := testProblem(1)
+	s := Solve(p)
+
+	if len(s.Placements)+len(s.Unplaced) != 5 {
+		t.Fatalf("placed %d and left out %d rows; want 5 in total", len(s.Placements), len(s.Unplaced))
+	}
+	if len(s.Unplaced) > 0 {
+		t.Errorf("left out %d rows that fit", len(s.Unplaced))
+	}
+	if s.AntagonisticPairs > 0 {
+		t.Errorf("placed %d antagonistic pairs next to each other", s.AntagonisticPairs)
+	}
+
+	for i, a := range s.Placements {
+		if !p.fits(a.Box, p.Obstacles) {
+			t.Errorf("row %d at %v is outside the area or too close to an obstacle", i, a.Box)
+		}
+		for j := i + 1; j < len(s.Placements); j++ {
+			b := s.Placements[j]
+			if a.Box.Distance(&b.Box) < p.Clearance || overlaps(&a.Box, &b.Box) {
+				t.Errorf("rows %d and %d are closer than the clearance", i, j)
+			}
+		}
+	}
+}
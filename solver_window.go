@@ -0,0 +1,331 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/bcicen/go-units"
+	"github.com/cpgillem/garden-planner/controllers"
+	"github.com/cpgillem/garden-planner/geometry"
+	"github.com/cpgillem/garden-planner/models"
+	"github.com/cpgillem/garden-planner/solver"
+	"github.com/cpgillem/garden-planner/ui"
+)
+
+// Template used for rows proposed by the solver.
+const SOLVER_TEMPLATE = "plant_row"
+
+// Window for laying out plant rows with the companion planting solver. Rows
+// fill the selected feature, or the whole plan if nothing is selected.
+type SolverWindow struct {
+	instance *GardenPlanner
+	window   fyne.Window
+
+	// Data
+	requests []solver.Request
+	area     models.FeatureID
+	solution solver.Solution
+
+	// Request Widgets
+	plantEntry    *ui.IdSelectEntry
+	quantityEntry *widget.Entry
+	lengthEntry   *ui.DimensionEntry
+	widthEntry    *ui.DimensionEntry
+	requestList   *widget.List
+
+	// Solver Widgets
+	clearanceEntry *ui.DimensionEntry
+	seedEntry      *widget.Entry
+	summaryLabel   *widget.Label
+
+	// Preview
+	previewController *controllers.PlanController
+	preview           *ui.GardenWidget
+
+	acceptButton *widget.Button
+}
+
+func NewSolverWindow(instance *GardenPlanner) SolverWindow {
+	previewController := controllers.NewPlanController(models.NewPlan())
+	w := SolverWindow{
+		instance:          instance,
+		window:            instance.App.NewWindow("Layout Solver"),
+		requests:          []solver.Request{},
+		plantEntry:        ui.NewIdSelectEntry([]ui.IdOption{}),
+		quantityEntry:     widget.NewEntry(),
+		lengthEntry:       ui.NewDimensionEntry(units.NewValue(48, units.Inch), instance.Formatter),
+		widthEntry:        ui.NewDimensionEntry(units.NewValue(18, units.Inch), instance.Formatter),
+		clearanceEntry:    ui.NewDimensionEntry(units.NewValue(12, units.Inch), instance.Formatter),
+		seedEntry:         widget.NewEntry(),
+		summaryLabel:      widget.NewLabel(""),
+		previewController: &previewController,
+	}
+	w.preview = ui.NewGardenWidget(w.previewController, 1, 12)
+
+	w.window.SetCloseIntercept(w.window.Hide)
+	w.window.Resize(fyne.NewSize(900, 600))
+
+	return w
+}
+
+// Builds the window contents. Separate from NewSolverWindow so callbacks
+// refer to the window stored in the app rather than a copy.
+func (w *SolverWindow) Setup() {
+	showError := func(err error) {
+		dialog.ShowError(err, w.window)
+	}
+	w.lengthEntry.OnDimensionError = showError
+	w.widthEntry.OnDimensionError = showError
+	w.clearanceEntry.OnDimensionError = showError
+
+	w.quantityEntry.SetText("1")
+	w.seedEntry.SetText("1")
+	w.preview.Plants = &w.instance.PlantController
+
+	// Rows to place.
+	requestForm := container.New(
+		layout.NewFormLayout(),
+		widget.NewLabel("Plant"), w.plantEntry,
+		widget.NewLabel("Rows"), w.quantityEntry,
+		widget.NewLabel("Row Length"), w.lengthEntry,
+		widget.NewLabel("Row Width"), w.widthEntry,
+	)
+	addButton := widget.NewButtonWithIcon("Add", theme.ContentAddIcon(), w.AddRequest)
+
+	w.requestList = widget.NewList(
+		func() int {
+			return len(w.requests)
+		},
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil, nil, widget.NewButtonWithIcon("", theme.DeleteIcon(), nil), widget.NewLabel(""))
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			r := w.requests[i]
+			plant, _ := w.instance.PlantController.GetPlant(r.PlantID)
+			row := o.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(fmt.Sprintf(
+				"%d × %s (%s × %s)",
+				r.Quantity,
+				plant.Name,
+				w.instance.Formatter.FormatDimension(units.NewValue(float64(r.RowLength), models.BaseLengthUnit)),
+				w.instance.Formatter.FormatDimension(units.NewValue(float64(r.RowWidth), models.BaseLengthUnit)),
+			))
+			row.Objects[1].(*widget.Button).OnTapped = func() {
+				w.requests = append(w.requests[:i], w.requests[i+1:]...)
+				w.requestList.Refresh()
+			}
+		},
+	)
+
+	// Solver settings.
+	solverForm := container.New(
+		layout.NewFormLayout(),
+		widget.NewLabel("Clearance"), w.clearanceEntry,
+		widget.NewLabel("Seed"), w.seedEntry,
+	)
+	solveButton := widget.NewButtonWithIcon("Solve", theme.MediaPlayIcon(), w.Solve)
+	rerollButton := widget.NewButtonWithIcon("New Seed", theme.ViewRefreshIcon(), func() {
+		w.seedEntry.SetText(strconv.FormatInt(rand.Int63n(1_000_000), 10))
+		w.Solve()
+	})
+
+	w.acceptButton = widget.NewButtonWithIcon("Accept", theme.ConfirmIcon(), w.Accept)
+	w.acceptButton.Importance = widget.HighImportance
+	w.acceptButton.Disable()
+	rejectButton := widget.NewButtonWithIcon("Reject", theme.CancelIcon(), w.window.Hide)
+
+	w.summaryLabel.Wrapping = fyne.TextWrapWord
+
+	controls := container.NewBorder(
+		container.NewVBox(
+			requestForm,
+			addButton,
+			widget.NewSeparator(),
+		),
+		container.NewVBox(
+			widget.NewSeparator(),
+			solverForm,
+			container.NewGridWithColumns(2, solveButton, rerollButton),
+			w.summaryLabel,
+			container.NewGridWithColumns(2, rejectButton, w.acceptButton),
+		),
+		nil, nil,
+		w.requestList,
+	)
+
+	split := container.NewHSplit(controls, container.NewScroll(w.preview))
+	split.Offset = 0.35
+	w.window.SetContent(split)
+}
+
+// Opens the solver for the selected feature, or the whole plan.
+func (w *SolverWindow) Show() {
+	w.area = models.NoFeature
+	if w.instance.PlanController.HasSelection() {
+		w.area = w.instance.PlanController.GetSelectedFeature()
+	}
+
+	// Plants may have changed since the window was last open.
+	options := []ui.IdOption{}
+	for _, p := range w.instance.PlantController.Plants() {
+		options = append(options, ui.IdOption{ID: p.ID, Name: p.Name})
+	}
+	w.plantEntry.SetOptions(options)
+
+	w.clearPreview()
+	w.window.Show()
+}
+
+// Adds the row request in the form to the list.
+func (w *SolverWindow) AddRequest() {
+	if !w.instance.PlantController.HasPlant(w.plantEntry.SelectedID()) {
+		dialog.ShowError(fmt.Errorf("choose a plant first"), w.window)
+		return
+	}
+	quantity, err := strconv.Atoi(w.quantityEntry.Text)
+	if err != nil || quantity < 1 {
+		dialog.ShowError(fmt.Errorf("number of rows must be a whole number above 0"), w.window)
+		return
+	}
+
+	length := w.lengthEntry.GetValue().MustConvert(models.BaseLengthUnit).Float()
+	width := w.widthEntry.GetValue().MustConvert(models.BaseLengthUnit).Float()
+	if length <= 0 || width <= 0 {
+		dialog.ShowError(fmt.Errorf("rows must have a length and width"), w.window)
+		return
+	}
+
+	w.requests = append(w.requests, solver.Request{
+		PlantID:   w.plantEntry.SelectedID(),
+		Quantity:  quantity,
+		RowLength: float32(length),
+		RowWidth:  float32(width),
+	})
+	w.requestList.Refresh()
+}
+
+// Runs the solver and shows the result in the preview.
+func (w *SolverWindow) Solve() {
+	seed, err := strconv.ParseInt(w.seedEntry.Text, 10, 64)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("seed must be a whole number"), w.window)
+		return
+	}
+	if len(w.requests) == 0 {
+		dialog.ShowError(fmt.Errorf("add some rows to place first"), w.window)
+		return
+	}
+
+	clearance := float32(w.clearanceEntry.GetValue().MustConvert(models.BaseLengthUnit).Float())
+	area, obstacles := w.areaAndObstacles()
+	w.solution = solver.Solve(solver.Problem{
+		Area:              area,
+		Obstacles:         obstacles,
+		Requests:          w.requests,
+		Plants:            w.instance.PlantController.Plants(),
+		Clearance:         clearance,
+		NeighbourDistance: max(clearance, w.instance.GardenWidget.CompanionDistance()),
+		Seed:              seed,
+		Iterations:        50,
+	})
+
+	// Preview in area coordinates: obstacles, then proposed rows.
+	plan := models.NewPlan()
+	plan.Box = geometry.NewBox(0, 0, area.GetWidth(), area.GetHeight())
+	for _, o := range obstacles {
+		o.Location.AddTo(area.Location.Negate())
+		plan.Features[models.NewFeatureID()] = &models.Feature{Name: "Existing", Box: o, Properties: map[string]models.PropertyValue{}}
+	}
+	for _, f := range w.features() {
+		f := f
+		f.Box.Location.AddTo(area.Location.Negate())
+		plan.Features[models.NewFeatureID()] = &f
+	}
+	*w.previewController = controllers.NewPlanController(plan)
+	w.preview.OpenPlan(w.previewController)
+
+	w.summaryLabel.SetText(fmt.Sprintf(
+		"Placed %d rows, %d didn't fit. %d beneficial and %d antagonistic neighbours.",
+		len(w.solution.Placements),
+		len(w.solution.Unplaced),
+		w.solution.BeneficialPairs,
+		w.solution.AntagonisticPairs,
+	))
+	w.acceptButton.Enable()
+}
+
+// Adds the proposed rows to the plan as one undo step.
+func (w *SolverWindow) Accept() {
+	features := w.features()
+	if len(features) == 0 {
+		return
+	}
+
+	// Rows go inside the area feature, relative to it.
+	if w.instance.PlanController.HasFeature(w.area) {
+		origin := w.instance.PlanController.AbsoluteBox(w.area).Location
+		for i := range features {
+			features[i].Parent = w.area
+			features[i].Box.Location.AddTo(origin.Negate())
+		}
+	}
+
+	w.instance.PlanController.AddFeatures(features)
+	w.window.Hide()
+}
+
+// Features for the current solution, in plan coordinates.
+func (w *SolverWindow) features() []models.Feature {
+	template, ok := w.instance.GardenData.FeatureTemplates[SOLVER_TEMPLATE]
+	if !ok {
+		dialog.ShowError(fmt.Errorf("feature template %s is missing", SOLVER_TEMPLATE), w.window)
+		return []models.Feature{}
+	}
+	return w.solution.Features(&template, w.instance.GardenData.Properties, w.instance.PlantController.Plants())
+}
+
+// The box to fill, in plan coordinates before the area is turned, and the
+// features already in it. Only features without children are obstacles,
+// since parents contain them. Turned and curved features block the box
+// around them, as seen from the area.
+func (w *SolverWindow) areaAndObstacles() (geometry.Box, []geometry.Box) {
+	c := &w.instance.PlanController
+	area := c.Plan.Box.Copy()
+	candidates := []models.FeatureID{}
+	if c.HasFeature(w.area) {
+		area = c.AbsoluteBox(w.area)
+		candidates = c.Plan.Descendants(w.area)
+	} else {
+		for id := range c.Plan.Features {
+			candidates = append(candidates, id)
+		}
+	}
+
+	obstacles := []geometry.Box{}
+	for _, id := range candidates {
+		if len(c.Plan.Children(id)) == 0 {
+			// Rows are laid out in the area's frame and turn with it.
+			points := []geometry.Vector{}
+			for _, p := range c.Plan.Shape(id).Outline() {
+				points = append(points, c.Plan.Unturn(w.area, p))
+			}
+			obstacles = append(obstacles, geometry.NewPolygon(points...).Bounds())
+		}
+	}
+	return area, obstacles
+}
+
+func (w *SolverWindow) clearPreview() {
+	w.solution = solver.Solution{}
+	*w.previewController = controllers.NewPlanController(models.NewPlan())
+	w.preview.OpenPlan(w.previewController)
+	w.summaryLabel.SetText("")
+	w.acceptButton.Disable()
+}
@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"strconv"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/layout"
@@ -18,11 +20,17 @@ type BoxEditor struct {
 	WidthLabel  *widget.Label
 	HeightLabel *widget.Label
 
+	RotationLabel *widget.Label
+
 	XEntry      *DimensionEntry
 	YEntry      *DimensionEntry
 	WidthEntry  *DimensionEntry
 	HeightEntry *DimensionEntry
 
+	// Degrees, clockwise.
+	RotationEntry *widget.Entry
+	rotation      float32
+
 	// Container
 	container *fyne.Container
 
@@ -30,7 +38,8 @@ type BoxEditor struct {
 	Formatter *DimensionFormatter
 
 	// Events
-	OnSubmitted func(newBox geometry.Box)
+	OnSubmitted         func(newBox geometry.Box)
+	OnRotationSubmitted func(degrees float32)
 }
 
 func NewBoxEditor(initialBox geometry.Box, baseUnit units.Unit, formatter *DimensionFormatter) *BoxEditor {
@@ -46,6 +55,21 @@ func NewBoxEditor(initialBox geometry.Box, baseUnit units.Unit, formatter *Dimen
 		container:   container.New(layout.NewFormLayout()),
 		Formatter:   formatter,
 		OnSubmitted: func(newBox geometry.Box) {},
+
+		RotationLabel:       widget.NewLabel("Rotation (°)"),
+		RotationEntry:       widget.NewEntry(),
+		OnRotationSubmitted: func(degrees float32) {},
+	}
+	boxEditor.SetRotation(0)
+
+	// Numbers that can't be read put back the current rotation.
+	boxEditor.RotationEntry.OnSubmitted = func(text string) {
+		degrees, err := strconv.ParseFloat(text, 32)
+		if err != nil {
+			boxEditor.SetRotation(boxEditor.rotation)
+			return
+		}
+		boxEditor.OnRotationSubmitted(float32(degrees))
 	}
 
 	boxEditor.XEntry.OnValueChanged = func(val units.Value) {
@@ -72,6 +96,8 @@ func NewBoxEditor(initialBox geometry.Box, baseUnit units.Unit, formatter *Dimen
 	boxEditor.container.Add(boxEditor.WidthEntry)
 	boxEditor.container.Add(boxEditor.HeightLabel)
 	boxEditor.container.Add(boxEditor.HeightEntry)
+	boxEditor.container.Add(boxEditor.RotationLabel)
+	boxEditor.container.Add(boxEditor.RotationEntry)
 
 	boxEditor.ExtendBaseWidget(boxEditor)
 
@@ -91,11 +117,16 @@ func (b *BoxEditor) UpdateBox() {
 
 func (b *BoxEditor) SetBox(box geometry.Box) {
 	b.XEntry.SetValue(units.NewValue(float64(box.GetX()), b.XEntry.baseUnit))
-	b.YEntry.SetValue(units.NewValue(float64(box.GetX()), b.YEntry.baseUnit))
+	b.YEntry.SetValue(units.NewValue(float64(box.GetY()), b.YEntry.baseUnit))
 	b.WidthEntry.SetValue(units.NewValue(float64(box.GetWidth()), b.WidthEntry.baseUnit))
 	b.HeightEntry.SetValue(units.NewValue(float64(box.GetHeight()), b.HeightEntry.baseUnit))
 }
 
+func (b *BoxEditor) SetRotation(degrees float32) {
+	b.rotation = degrees
+	b.RotationEntry.SetText(strconv.FormatFloat(float64(degrees), 'f', -1, 32))
+}
+
 func (b *BoxEditor) CreateRenderer() fyne.WidgetRenderer {
 	renderer := widget.NewSimpleRenderer(b.container)
 	return renderer
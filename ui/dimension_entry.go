@@ -42,6 +42,16 @@ func NewDimensionEntry(value units.Value, dimensionFormatter *DimensionFormatter
 			return
 		}
 
+		// Reject units that can't be converted, e.g. volumes for a length.
+		if dimensionEntry.baseUnit.Name != AnyUnit.Name {
+			value, err = value.Convert(dimensionEntry.baseUnit)
+			if err != nil {
+				dimensionEntry.Reset()
+				dimensionEntry.OnDimensionError(NewDimensionError(s, "Unit must be convertible to "+dimensionEntry.baseUnit.Name+"."))
+				return
+			}
+		}
+
 		dimensionEntry.SetValue(value)
 		dimensionEntry.OnValueChanged(value)
 	}
@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+	"github.com/cpgillem/garden-planner/controllers"
+	"github.com/cpgillem/garden-planner/models"
+)
+
+// Node standing for the plan itself. Features dropped on it become root
+// features. Not a UUID, so it can't clash with a feature ID.
+const planNodeID = "plan"
+
+// Shows the features of a plan nested under their parents. Dragging a feature
+// onto another nests it there; dropping it on the plan node un-nests it.
+type FeatureTree struct {
+	widget.Tree
+
+	// Node under the pointer while dragging.
+	dropTarget string
+
+	// Controller reference
+	Controller *controllers.PlanController
+
+	// Events
+	OnReparentError func(err error)
+}
+
+func NewFeatureTree(controller *controllers.PlanController) *FeatureTree {
+	t := &FeatureTree{
+		Controller:      controller,
+		OnReparentError: func(err error) {},
+	}
+
+	t.ChildUIDs = t.childUIDs
+	t.IsBranch = t.isBranch
+	t.CreateNode = func(branch bool) fyne.CanvasObject {
+		return newFeatureTreeNode(t)
+	}
+	t.UpdateNode = t.updateNode
+	t.OnSelected = func(uid widget.TreeNodeID) {
+		id := models.FeatureID(uid)
+		if uid != planNodeID && id != t.Controller.GetSelectedFeature() {
+			t.Controller.SelectFeature(id)
+		}
+	}
+
+	t.ExtendBaseWidget(t)
+	t.OpenBranch(planNodeID)
+	return t
+}
+
+// Selects a feature's node, opening its parents so it can be seen.
+func (t *FeatureTree) SelectFeature(id models.FeatureID) {
+	if !t.Controller.HasFeature(id) {
+		t.UnselectAll()
+		return
+	}
+
+	for _, parent := range t.Controller.Plan.Ancestors(id) {
+		t.OpenBranch(string(parent))
+	}
+	t.Select(string(id))
+}
+
+func (t *FeatureTree) childUIDs(uid widget.TreeNodeID) []widget.TreeNodeID {
+	var ids []models.FeatureID
+	switch uid {
+	case "":
+		return []widget.TreeNodeID{planNodeID}
+	case planNodeID:
+		ids = t.Controller.Plan.Roots()
+	default:
+		ids = t.Controller.Plan.Children(models.FeatureID(uid))
+	}
+
+	uids := []widget.TreeNodeID{}
+	for _, id := range ids {
+		uids = append(uids, string(id))
+	}
+	return uids
+}
+
+func (t *FeatureTree) isBranch(uid widget.TreeNodeID) bool {
+	return uid == "" || uid == planNodeID || len(t.childUIDs(uid)) > 0
+}
+
+func (t *FeatureTree) updateNode(uid widget.TreeNodeID, branch bool, node fyne.CanvasObject) {
+	n := node.(*featureTreeNode)
+	n.uid = uid
+
+	if uid == planNodeID {
+		name := t.Controller.Plan.Name
+		if name == "" {
+			name = "Plan"
+		}
+		n.SetText(name)
+		return
+	}
+
+	if f := t.Controller.Plan.Features[models.FeatureID(uid)]; f != nil {
+		n.SetText(f.Name)
+	}
+}
+
+// Nests the dragged feature under the node it was dropped on.
+func (t *FeatureTree) drop(uid widget.TreeNodeID) {
+	target := t.dropTarget
+	t.dropTarget = ""
+	if target == "" || target == uid || uid == planNodeID {
+		return
+	}
+
+	parent := models.FeatureID(target)
+	if target == planNodeID {
+		parent = models.NoFeature
+	}
+
+	if err := t.Controller.SetFeatureParent(models.FeatureID(uid), parent); err != nil {
+		t.OnReparentError(err)
+		return
+	}
+
+	if parent != models.NoFeature {
+		t.OpenBranch(target)
+	}
+	t.Refresh()
+}
+
+// A tree row that can be dragged onto other rows.
+type featureTreeNode struct {
+	widget.Label
+
+	tree *FeatureTree
+	uid  widget.TreeNodeID
+}
+
+func newFeatureTreeNode(tree *FeatureTree) *featureTreeNode {
+	n := &featureTreeNode{tree: tree}
+	n.Truncation = fyne.TextTruncateEllipsis
+	n.ExtendBaseWidget(n)
+	return n
+}
+
+func (n *featureTreeNode) Dragged(e *fyne.DragEvent) {
+	// Other rows only get hover events while the pointer is over them, so
+	// note when it is back over this one.
+	if e.Position.X >= 0 && e.Position.Y >= 0 && e.Position.X <= n.Size().Width && e.Position.Y <= n.Size().Height {
+		n.tree.dropTarget = n.uid
+	}
+}
+
+func (n *featureTreeNode) DragEnd() {
+	n.tree.drop(n.uid)
+}
+
+// Implement desktop.Hoverable to track the drop target.
+
+func (n *featureTreeNode) MouseIn(e *desktop.MouseEvent) {
+	n.tree.dropTarget = n.uid
+}
+
+func (n *featureTreeNode) MouseMoved(e *desktop.MouseEvent) {
+	n.tree.dropTarget = n.uid
+}
+
+func (n *featureTreeNode) MouseOut() {
+	if n.tree.dropTarget == n.uid {
+		n.tree.dropTarget = ""
+	}
+}
@@ -1,8 +1,12 @@
 package ui
 
 import (
+	"image/color"
+	"math"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/widget"
 	"github.com/cpgillem/garden-planner/controllers"
 	"github.com/cpgillem/garden-planner/geometry"
@@ -10,54 +14,129 @@ import (
 	"golang.org/x/image/colornames"
 )
 
+// Diameter of the handles on a feature, and how far the rotation handle
+// sits above it, in pixels.
+const (
+	HANDLE_SIZE              = 10
+	ROTATION_HANDLE_DISTANCE = 20
+)
+
+// Smallest width or height, in base units, a feature can be resized to.
+const MIN_FEATURE_SIZE = 1
+
 type FeatureWidget struct {
 	widget.BaseWidget
 
 	// Internal widgets
 	Label        *widget.Label
 	Border       *canvas.Rectangle
+	Fill         *canvas.Raster
 	TopHandle    *Handle
 	BottomHandle *Handle
 	LeftHandle   *Handle
 	RightHandle  *Handle
 
+	// Resize two edges at once.
+	TopLeftHandle     *Handle
+	TopRightHandle    *Handle
+	BottomRightHandle *Handle
+	BottomLeftHandle  *Handle
+
+	// Turns the feature. Shown while it is selected.
+	RotationHandle *Handle
+
+	// Corner handles of a selected polygon, and handles halfway along its
+	// sides for adding corners.
+	VertexHandles   []*VertexHandle
+	MidpointHandles []*VertexHandle
+
 	// Drawing configuration
 	scale float32
 
 	// Internal data
 	FeatureID models.FeatureID
 	selected  bool
+	colliding bool
+	dragging  bool
+	warning   string
+
+	// Outline on the plan, and the box around it that the widget covers.
+	outline geometry.Shape
+	bounds  geometry.Box
+
+	// Feature moved by the current drag. Drags that start outside the
+	// outline move the feature beneath instead.
+	dragTarget models.FeatureID
+
+	// Box of the dragged feature when the drag started, the box around its
+	// outline on the plan, and how far the pointer has moved since, in base
+	// units. Snapping works from these so small moves aren't lost.
+	dragStart  geometry.Box
+	dragBounds geometry.Box
+	dragDelta  geometry.Vector
+	snapper    *geometry.Snapper
 
 	// Controller Reference
 	Controller *controllers.PlanController
 
+	// Snaps drags of a feature. Returns nil while snapping is off.
+	NewSnapper func(id models.FeatureID) *geometry.Snapper
+
+	// Take drags and taps away from the feature while one of the garden's
+	// tools uses them, e.g. to pan or measure. Return whether they did.
+	// Tapped positions are on the garden.
+	ToolDragged func(e *fyne.DragEvent) bool
+	ToolTapped  func(pos fyne.Position) bool
+
 	// Events
-	OnDragged       func(e *fyne.DragEvent)
-	OnDragEnd       func()
+	OnDragged       func(id models.FeatureID, e *fyne.DragEvent)
+	OnDragEnd       func(id models.FeatureID)
 	OnHandleDragged func(edge geometry.BoxEdge, e *fyne.DragEvent)
 	OnHandleDragEnd func(edge geometry.BoxEdge)
-	OnTapped        func()
+	OnCornerDragged func(corner geometry.BoxCorner, e *fyne.DragEvent)
+	OnCornerDragEnd func(corner geometry.BoxCorner)
+	OnTapped        func(id models.FeatureID)
+	OnGuides        func(guides []geometry.Guide)
+	OnMouseDown     func(e *desktop.MouseEvent)
+	OnMouseUp       func(e *desktop.MouseEvent)
 }
 
 // Create a new widget representing a landscaping feature.
 func NewFeatureWidget(id models.FeatureID, controller *controllers.PlanController, scale float32) *FeatureWidget {
 	fw := FeatureWidget{
-		FeatureID:       id,
-		Controller:      controller,
-		scale:           scale,
-		selected:        false,
-		OnDragEnd:       func() {},
-		OnDragged:       func(e *fyne.DragEvent) {},
-		OnHandleDragged: func(edge geometry.BoxEdge, e *fyne.DragEvent) {},
-		OnHandleDragEnd: func(edge geometry.BoxEdge) {},
-		OnTapped:        func() {},
-		Label:           widget.NewLabel(""),
-		Border:          canvas.NewRectangle(colornames.Lawngreen),
-		TopHandle:       NewHandle(),
-		BottomHandle:    NewHandle(),
-		LeftHandle:      NewHandle(),
-		RightHandle:     NewHandle(),
+		FeatureID:         id,
+		Controller:        controller,
+		scale:             scale,
+		selected:          false,
+		dragTarget:        models.NoFeature,
+		OnDragEnd:         func(id models.FeatureID) {},
+		OnDragged:         func(id models.FeatureID, e *fyne.DragEvent) {},
+		OnHandleDragged:   func(edge geometry.BoxEdge, e *fyne.DragEvent) {},
+		OnHandleDragEnd:   func(edge geometry.BoxEdge) {},
+		OnCornerDragged:   func(corner geometry.BoxCorner, e *fyne.DragEvent) {},
+		OnCornerDragEnd:   func(corner geometry.BoxCorner) {},
+		OnTapped:          func(id models.FeatureID) {},
+		OnGuides:          func(guides []geometry.Guide) {},
+		NewSnapper:        func(id models.FeatureID) *geometry.Snapper { return nil },
+		ToolDragged:       func(e *fyne.DragEvent) bool { return false },
+		ToolTapped:        func(pos fyne.Position) bool { return false },
+		OnMouseDown:       func(e *desktop.MouseEvent) {},
+		OnMouseUp:         func(e *desktop.MouseEvent) {},
+		Label:             widget.NewLabel(""),
+		Border:            canvas.NewRectangle(colornames.Lawngreen),
+		TopHandle:         NewHandle(),
+		BottomHandle:      NewHandle(),
+		LeftHandle:        NewHandle(),
+		RightHandle:       NewHandle(),
+		TopLeftHandle:     NewHandle(),
+		TopRightHandle:    NewHandle(),
+		BottomRightHandle: NewHandle(),
+		BottomLeftHandle:  NewHandle(),
+		RotationHandle:    NewHandle(),
+		VertexHandles:     []*VertexHandle{},
+		MidpointHandles:   []*VertexHandle{},
 	}
+	fw.Fill = canvas.NewRasterWithPixels(fw.fillPixel)
 
 	// Handle drag events.
 	fw.TopHandle.OnDragged = func(e *fyne.DragEvent) {
@@ -86,77 +165,424 @@ func NewFeatureWidget(id models.FeatureID, controller *controllers.PlanControlle
 		fw.HandleDragEnd(geometry.RIGHT)
 	}
 
+	fw.TopLeftHandle.OnDragged = func(e *fyne.DragEvent) {
+		fw.CornerDragged(geometry.TOP_LEFT, e)
+	}
+	fw.TopRightHandle.OnDragged = func(e *fyne.DragEvent) {
+		fw.CornerDragged(geometry.TOP_RIGHT, e)
+	}
+	fw.BottomRightHandle.OnDragged = func(e *fyne.DragEvent) {
+		fw.CornerDragged(geometry.BOTTOM_RIGHT, e)
+	}
+	fw.BottomLeftHandle.OnDragged = func(e *fyne.DragEvent) {
+		fw.CornerDragged(geometry.BOTTOM_LEFT, e)
+	}
+
+	fw.TopLeftHandle.OnDragEnd = func() {
+		fw.CornerDragEnd(geometry.TOP_LEFT)
+	}
+	fw.TopRightHandle.OnDragEnd = func() {
+		fw.CornerDragEnd(geometry.TOP_RIGHT)
+	}
+	fw.BottomRightHandle.OnDragEnd = func() {
+		fw.CornerDragEnd(geometry.BOTTOM_RIGHT)
+	}
+	fw.BottomLeftHandle.OnDragEnd = func() {
+		fw.CornerDragEnd(geometry.BOTTOM_LEFT)
+	}
+
+	fw.RotationHandle.Circle.FillColor = colornames.Lightskyblue
+	fw.RotationHandle.Hide()
+	fw.RotationHandle.OnDragged = fw.RotationDragged
+	fw.RotationHandle.OnDragEnd = func() {
+		fw.endGesture()
+		fw.OnDragEnd(fw.FeatureID)
+	}
+
 	fw.ExtendBaseWidget(&fw)
 
 	return &fw
 }
 
-// Implement the Tappable interface to define click behavior.
+// Implement the Tappable interface to define click behavior. Taps outside
+// the outline select the feature beneath.
 func (fw *FeatureWidget) Tapped(e *fyne.PointEvent) {
-	fw.Controller.SelectFeature(fw.FeatureID)
-	fw.OnTapped()
+	if fw.ToolTapped(fw.Position().Add(e.Position)) {
+		return
+	}
+	id := fw.featureAt(e.Position)
+	if id == models.NoFeature {
+		return
+	}
+	fw.Controller.SelectFeature(id)
+	fw.OnTapped(id)
 }
+
+// Implement the Mouseable interface so the garden hears which buttons are
+// pressed and released over features.
+func (fw *FeatureWidget) MouseDown(e *desktop.MouseEvent) {
+	fw.OnMouseDown(e)
+}
+
+func (fw *FeatureWidget) MouseUp(e *desktop.MouseEvent) {
+	fw.OnMouseUp(e)
+}
+
 func (fw *FeatureWidget) Dragged(e *fyne.DragEvent) {
-	boxDelta := geometry.NewBox(
-		e.Dragged.DX/fw.scale,
-		e.Dragged.DY/fw.scale,
+	if fw.ToolDragged(e) {
+		return
+	}
+	if !fw.dragging {
+		fw.dragTarget = fw.featureAt(e.Position.SubtractXY(e.Dragged.DX, e.Dragged.DY))
+		if fw.dragTarget == models.NoFeature {
+			return
+		}
+		fw.startDrag(fw.dragTarget)
+	}
+
+	fw.beginGesture()
+	drag := geometry.NewVector(e.Dragged.DX/fw.scale, e.Dragged.DY/fw.scale, 0)
+	fw.dragDelta.AddTo(&drag)
+	move := fw.dragDelta.Copy()
+
+	guides := []geometry.Guide{}
+	if s := fw.activeSnapper(); s != nil {
+		moved := fw.dragBounds.Copy()
+		moved.Location.AddTo(&fw.dragDelta)
+		offset, g := s.SnapMove(moved)
+		move.AddTo(&offset)
+		guides = g
+	}
+
+	// Nested features move in their parent's turned frame.
+	_, degrees := fw.Controller.Plan.Placement(fw.Controller.Plan.Features[fw.dragTarget].Parent)
+	move = move.Rotate(geometry.NewVector(0, 0, 0), -degrees)
+	box := fw.dragStart.Copy()
+	box.Location.AddTo(&move)
+
+	fw.Controller.SetFeatureBox(fw.dragTarget, box)
+	fw.OnGuides(guides)
+	fw.OnDragged(fw.dragTarget, e)
+}
+func (fw *FeatureWidget) DragEnd() {
+	if fw.dragTarget == models.NoFeature {
+		return
+	}
+	fw.endGesture()
+	fw.OnGuides([]geometry.Guide{})
+	fw.OnDragEnd(fw.dragTarget)
+	fw.dragTarget = models.NoFeature
+}
+
+// Remembers where a feature was when a drag started.
+func (fw *FeatureWidget) startDrag(id models.FeatureID) {
+	fw.dragStart = fw.Controller.Plan.Features[id].Box.Copy()
+	fw.dragBounds = fw.Controller.Plan.Shape(id).Bounds()
+	fw.dragDelta = geometry.NewVector(0, 0, 0)
+	fw.snapper = fw.NewSnapper(id)
+}
+
+// Snapper for the current drag, or nil while snapping is off or the
+// shortcut key (Control, or Command on a Mac) is held.
+func (fw *FeatureWidget) activeSnapper() *geometry.Snapper {
+	if currentModifiers()&fyne.KeyModifierShortcutDefault != 0 {
+		return nil
+	}
+	return fw.snapper
+}
+
+// Modifier keys held down. Only desktops have them.
+func currentModifiers() fyne.KeyModifier {
+	if app := fyne.CurrentApp(); app != nil {
+		if d, ok := app.Driver().(desktop.Driver); ok {
+			return d.CurrentKeyModifiers()
+		}
+	}
+	return 0
+}
+
+// Topmost feature under a point in widget coordinates.
+func (fw *FeatureWidget) featureAt(pos fyne.Position) models.FeatureID {
+	return fw.Controller.FeatureAt(fw.toPlan(pos))
+}
+
+// Converts widget coordinates to plan coordinates.
+func (fw *FeatureWidget) toPlan(pos fyne.Position) geometry.Vector {
+	offset := pos.Subtract(fw.Border.Position())
+	return geometry.NewVector(
+		fw.bounds.Location.X+offset.X/fw.scale,
+		fw.bounds.Location.Y+offset.Y/fw.scale,
 		0,
+	)
+}
+
+// Converts plan coordinates to widget coordinates.
+func (fw *FeatureWidget) toWidget(p geometry.Vector) fyne.Position {
+	return fw.Border.Position().AddXY(
+		(p.X-fw.bounds.Location.X)*fw.scale,
+		(p.Y-fw.bounds.Location.Y)*fw.scale,
+	)
+}
+
+// Reads the outline from the plan.
+func (fw *FeatureWidget) updateOutline() {
+	if outline := fw.Controller.Plan.Shape(fw.FeatureID); outline != nil {
+		fw.outline = outline
+		fw.bounds = outline.Bounds()
+	}
+}
+
+// Colors the pixels of the fill inside the outline.
+func (fw *FeatureWidget) fillPixel(x, y, w, h int) color.Color {
+	p := geometry.NewVector(
+		fw.bounds.Location.X+(float32(x)+0.5)/float32(w)*fw.bounds.Size.X,
+		fw.bounds.Location.Y+(float32(y)+0.5)/float32(h)*fw.bounds.Size.Y,
 		0,
 	)
-	fw.Controller.MoveResizeFeature(fw.FeatureID, &boxDelta)
-	fw.OnDragged(e)
+	if fw.outline != nil && fw.outline.Contains(p) {
+		return colornames.Lawngreen
+	}
+	return color.Transparent
 }
-func (fw *FeatureWidget) DragEnd() {
-	fw.OnDragEnd()
+
+// Creates or removes vertex handles so a selected polygon has one on each
+// corner and side.
+func (fw *FeatureWidget) syncVertexHandles() {
+	count := 0
+	shape := fw.Controller.Plan.Features[fw.FeatureID].Shape
+	if fw.selected && shape.GetKind() == models.SHAPE_POLYGON {
+		count = len(shape.Points)
+	}
+	if count == len(fw.VertexHandles) {
+		return
+	}
+
+	fw.VertexHandles = []*VertexHandle{}
+	fw.MidpointHandles = []*VertexHandle{}
+	for i := 0; i < count; i++ {
+		corner := NewVertexHandle()
+		corner.OnDragged = func(e *fyne.DragEvent) {
+			if fw.ToolDragged(e) {
+				return
+			}
+			fw.beginGesture()
+			fw.Controller.MoveVertex(fw.FeatureID, i, geometry.NewVector(e.Dragged.DX/fw.scale, e.Dragged.DY/fw.scale, 0))
+			fw.OnDragged(fw.FeatureID, e)
+		}
+		corner.OnDragEnd = func() {
+			fw.endGesture()
+			fw.OnDragEnd(fw.FeatureID)
+		}
+		corner.OnDoubleTapped = func() {
+			fw.Controller.RemoveVertex(fw.FeatureID, i)
+		}
+		fw.VertexHandles = append(fw.VertexHandles, corner)
+
+		middle := NewVertexHandle()
+		middle.Circle.FillColor = colornames.Lightgray
+		middle.OnTapped = func() {
+			fw.Controller.InsertVertex(fw.FeatureID, i)
+		}
+		fw.MidpointHandles = append(fw.MidpointHandles, middle)
+	}
+}
+
+// Groups the changes of one drag into a single undo step.
+func (fw *FeatureWidget) beginGesture() {
+	if !fw.dragging {
+		fw.dragging = true
+		fw.Controller.BeginGesture()
+	}
+}
+
+func (fw *FeatureWidget) endGesture() {
+	if fw.dragging {
+		fw.dragging = false
+		fw.Controller.EndGesture()
+	}
 }
 
 func (fw *FeatureWidget) HandleDragged(edge geometry.BoxEdge, e *fyne.DragEvent) {
-	dx := e.Dragged.DX / fw.scale
-	dy := e.Dragged.DY / fw.scale
-	dbox := geometry.NewBoxZero()
+	if fw.ToolDragged(e) {
+		return
+	}
+	fw.resizeDragged(geometry.NewEdgeResize(edge), e)
+	fw.OnHandleDragged(edge, e)
+}
+
+func (fw *FeatureWidget) CornerDragged(corner geometry.BoxCorner, e *fyne.DragEvent) {
+	if fw.ToolDragged(e) {
+		return
+	}
+	fw.resizeDragged(geometry.NewCornerResize(corner), e)
+	fw.OnCornerDragged(corner, e)
+}
+
+// Resizes the feature as a handle is dragged. Shift keeps the aspect and
+// Alt resizes from the center.
+func (fw *FeatureWidget) resizeDragged(resize geometry.Resize, e *fyne.DragEvent) {
+	if !fw.dragging {
+		fw.startDrag(fw.FeatureID)
+	}
+	fw.beginGesture()
+
+	// Edges of a turned feature move in its own frame.
+	f := fw.Controller.Plan.Features[fw.FeatureID]
+	placed, degrees := fw.Controller.Plan.Placement(fw.FeatureID)
+	drag := geometry.NewVector(e.Dragged.DX/fw.scale, e.Dragged.DY/fw.scale, 0)
+	drag = drag.Rotate(geometry.NewVector(0, 0, 0), -degrees)
+	fw.dragDelta.AddTo(&drag)
+
+	modifiers := currentModifiers()
+	resize.KeepAspect = modifiers&fyne.KeyModifierShift != 0
+	resize.FromCenter = modifiers&fyne.KeyModifierAlt != 0
+	resize.MinSize = MIN_FEATURE_SIZE
+	box := resize.Apply(fw.dragStart, fw.dragDelta)
+
+	// Only the dragged edges of a plain resize snap. Turned features, and
+	// those in turned parents, have no edges to line up.
+	guides := []geometry.Guide{}
+	if s := fw.activeSnapper(); s != nil && degrees == 0 && f.Rotation == 0 && !resize.KeepAspect && !resize.FromCenter {
+		// Snap on the plan, where the grid and other features are.
+		offset := placed.Location
+		offset.AddTo(f.Box.Location.Negate())
+
+		snapped := box.Copy()
+		guides = append(snapEdge(s, resize.X, &snapped, offset), snapEdge(s, resize.Y, &snapped, offset)...)
+		if snapped.Size.X >= resize.MinSize && snapped.Size.Y >= resize.MinSize {
+			box = snapped
+		} else {
+			guides = []geometry.Guide{}
+		}
+	}
 
-	// Handle edge cases (lol)
+	fw.Controller.SetFeatureBox(fw.FeatureID, geometry.KeepInPlace(fw.dragStart, box, f.Rotation))
+	fw.OnGuides(guides)
+}
+
+// Snaps one edge of a box, leaving the opposite edge in place. Offset is
+// the position of the box's parent on the plan.
+func snapEdge(s *geometry.Snapper, edge geometry.BoxEdge, box *geometry.Box, offset geometry.Vector) []geometry.Guide {
 	switch edge {
 	case geometry.TOP:
-		dbox.Location.Y = dy
-		dbox.Size.Y = -dy
+		d, guides := snapDelta(s.SnapY, box.Location.Y+offset.Y)
+		box.Location.Y += d
+		box.Size.Y -= d
+		return guides
 	case geometry.BOTTOM:
-		dbox.Size.Y = dy
+		d, guides := snapDelta(s.SnapY, box.Location.Y+box.Size.Y+offset.Y)
+		box.Size.Y += d
+		return guides
 	case geometry.LEFT:
-		dbox.Location.X = dx
-		dbox.Size.X = -dx
+		d, guides := snapDelta(s.SnapX, box.Location.X+offset.X)
+		box.Location.X += d
+		box.Size.X -= d
+		return guides
 	case geometry.RIGHT:
-		dbox.Size.X = dx
+		d, guides := snapDelta(s.SnapX, box.Location.X+box.Size.X+offset.X)
+		box.Size.X += d
+		return guides
 	}
+	return []geometry.Guide{}
+}
 
-	// Add box delta.
-	fw.Controller.MoveResizeFeature(fw.FeatureID, &dbox)
-	fw.OnHandleDragged(edge, e)
+// How far snapping moves a value.
+func snapDelta(snap func(v float32) (float32, []geometry.Guide), v float32) (float32, []geometry.Guide) {
+	snapped, guides := snap(v)
+	return snapped - v, guides
+}
+
+// Turns the feature to face the pointer while the rotation handle is
+// dragged, to the nearest degree.
+func (fw *FeatureWidget) RotationDragged(e *fyne.DragEvent) {
+	if fw.ToolDragged(e) {
+		return
+	}
+	fw.beginGesture()
+	p := fw.toPlan(fw.RotationHandle.Position().Add(e.Position))
+	box, degrees := fw.Controller.Plan.Placement(fw.FeatureID)
+	center := box.Center()
+
+	// The handle starts above the feature, a quarter turn before 0. Nested
+	// features are already turned with their parents.
+	f := fw.Controller.Plan.Features[fw.FeatureID]
+	angle := math.Atan2(float64(p.Y-center.Y), float64(p.X-center.X))*180/math.Pi + 90
+	angle -= float64(degrees - f.Rotation)
+	fw.Controller.SetFeatureRotation(fw.FeatureID, float32(math.Round(angle)))
+	fw.OnDragged(fw.FeatureID, e)
 }
 
 func (fw *FeatureWidget) HandleDragEnd(edge geometry.BoxEdge) {
+	fw.endGesture()
+	fw.OnGuides([]geometry.Guide{})
 	fw.OnHandleDragEnd(edge)
 }
 
+func (fw *FeatureWidget) CornerDragEnd(corner geometry.BoxCorner) {
+	fw.endGesture()
+	fw.OnGuides([]geometry.Guide{})
+	fw.OnCornerDragEnd(corner)
+}
+
+// Sets the zoom in pixels per base unit. The garden lays the widget out
+// again to match.
+func (fw *FeatureWidget) SetScale(s float32) {
+	fw.scale = s
+}
+
 func (featureWidget *FeatureWidget) CreateRenderer() fyne.WidgetRenderer {
 	return newFeatureRenderer(featureWidget)
 }
 
 func (fw *FeatureWidget) Select() {
 	fw.selected = true
-	fw.Border.StrokeColor = colornames.Black
-	fw.Border.StrokeWidth = 1
+	fw.updateStroke()
 }
 
 func (fw *FeatureWidget) Deselect() {
 	fw.selected = false
-	fw.Border.StrokeWidth = 0
+	fw.updateStroke()
 }
 
 func (fw *FeatureWidget) IsSelected() bool {
 	return fw.selected
 }
 
+// Outlines the feature in red while it overlaps another, sticks out of the
+// plan, or crowds a path.
+func (fw *FeatureWidget) SetColliding(colliding bool) {
+	if fw.colliding == colliding {
+		return
+	}
+	fw.colliding = colliding
+	fw.updateStroke()
+	fw.Border.Refresh()
+}
+
+func (fw *FeatureWidget) IsColliding() bool {
+	return fw.colliding
+}
+
+// Collisions show over the selection.
+func (fw *FeatureWidget) updateStroke() {
+	fw.Border.StrokeWidth = 0
+	if fw.selected {
+		fw.Border.StrokeColor = colornames.Black
+		fw.Border.StrokeWidth = 1
+	}
+	if fw.colliding {
+		fw.Border.StrokeColor = colornames.Red
+		fw.Border.StrokeWidth = 2
+	}
+}
+
+// Flags a problem with the feature, e.g. a reference to a removed plant.
+// An empty warning clears the flag.
+func (fw *FeatureWidget) SetWarning(warning string) {
+	fw.warning = warning
+	fw.Refresh()
+}
+
 type featureRenderer struct {
 	parent *FeatureWidget
 }
@@ -176,38 +602,63 @@ func (fr featureRenderer) Destroy() {
 func (fr featureRenderer) Layout(size fyne.Size) {
 	// TODO: Layout can have more objects depending on properties feature contains, e.g. row spacing.
 
-	// Define size of rectangle.
+	fr.parent.updateOutline()
+
+	// Define size of rectangle, leaving room for handles on the edges.
 	fr.parent.Border.Resize(size)
+	fr.parent.Border.Move(fyne.NewPos(HANDLE_SIZE/2, HANDLE_SIZE/2))
 
-	// Position handles.
-	handleSize := fyne.NewSquareSize(10)
-	fr.parent.TopHandle.Resize(handleSize)
-	fr.parent.BottomHandle.Resize(handleSize)
-	fr.parent.LeftHandle.Resize(handleSize)
-	fr.parent.RightHandle.Resize(handleSize)
+	// Outline fill over the rectangle.
+	fr.parent.Fill.Resize(size)
+	fr.parent.Fill.Move(fr.parent.Border.Position())
 
-	fr.parent.TopHandle.Move(fyne.NewPos(
-		size.Width/2,
-		0,
-	))
-	fr.parent.BottomHandle.Move(fyne.NewPos(
-		size.Width/2,
-		size.Height,
-	))
-	fr.parent.LeftHandle.Move(fyne.NewPos(
-		0,
-		size.Height/2,
-	))
-	fr.parent.RightHandle.Move(fyne.NewPos(
-		size.Width,
-		size.Height/2,
-	))
+	// Label in the top-left corner.
+	fr.parent.Label.Resize(fr.parent.Label.MinSize())
+	fr.parent.Label.Move(fr.parent.Border.Position())
 
-	// Center the rectangle.
-	fr.parent.Border.Move(fyne.NewPos(
-		fr.parent.LeftHandle.Size().Width/2,
-		fr.parent.TopHandle.Size().Height/2,
-	))
+	fr.layoutHandles()
+}
+
+// Places edge handles halfway along the sides of the turned box, the
+// rotation handle above it, and vertex handles on the corners of a polygon.
+func (fr featureRenderer) layoutHandles() {
+	f := fr.parent.Controller.Plan.Features[fr.parent.FeatureID]
+	if f == nil {
+		return
+	}
+
+	place := func(h fyne.CanvasObject, p geometry.Vector, diameter float32) {
+		h.Resize(fyne.NewSquareSize(diameter))
+		h.Move(fr.parent.toWidget(p).SubtractXY(diameter/2, diameter/2))
+	}
+
+	box, degrees := fr.parent.Controller.Plan.Placement(fr.parent.FeatureID)
+	center := box.Center()
+	turned := func(x, y float32) geometry.Vector {
+		p := geometry.NewVector(x, y, 0)
+		return p.Rotate(center, degrees)
+	}
+	left, top := box.Location.X, box.Location.Y
+	right, bottom := left+box.Size.X, top+box.Size.Y
+	place(fr.parent.TopHandle, turned(center.X, top), HANDLE_SIZE)
+	place(fr.parent.BottomHandle, turned(center.X, bottom), HANDLE_SIZE)
+	place(fr.parent.LeftHandle, turned(left, center.Y), HANDLE_SIZE)
+	place(fr.parent.RightHandle, turned(right, center.Y), HANDLE_SIZE)
+	place(fr.parent.TopLeftHandle, turned(left, top), HANDLE_SIZE)
+	place(fr.parent.TopRightHandle, turned(right, top), HANDLE_SIZE)
+	place(fr.parent.BottomRightHandle, turned(right, bottom), HANDLE_SIZE)
+	place(fr.parent.BottomLeftHandle, turned(left, bottom), HANDLE_SIZE)
+	place(fr.parent.RotationHandle, turned(center.X, top-ROTATION_HANDLE_DISTANCE/fr.parent.scale), HANDLE_SIZE)
+
+	corners := fr.parent.outline.Outline()
+	if f.Shape.GetKind() != models.SHAPE_POLYGON || len(fr.parent.VertexHandles) != len(corners) {
+		return
+	}
+	for i, p := range corners {
+		next := corners[(i+1)%len(corners)]
+		place(fr.parent.VertexHandles[i], p, HANDLE_SIZE)
+		place(fr.parent.MidpointHandles[i], geometry.NewVector((p.X+next.X)/2, (p.Y+next.Y)/2, 0), HANDLE_SIZE*0.8)
+	}
 }
 
 func (fr featureRenderer) MinSize() fyne.Size {
@@ -215,25 +666,70 @@ func (fr featureRenderer) MinSize() fyne.Size {
 }
 
 func (fr featureRenderer) Objects() []fyne.CanvasObject {
-	return []fyne.CanvasObject{
+	os := []fyne.CanvasObject{
 		fr.parent.Border,
+		fr.parent.Fill,
 		fr.parent.TopHandle,
 		fr.parent.BottomHandle,
 		fr.parent.LeftHandle,
 		fr.parent.RightHandle,
+		fr.parent.TopLeftHandle,
+		fr.parent.TopRightHandle,
+		fr.parent.BottomRightHandle,
+		fr.parent.BottomLeftHandle,
+		fr.parent.RotationHandle,
 		fr.parent.Label,
 	}
+	for _, h := range fr.parent.MidpointHandles {
+		os = append(os, h)
+	}
+	for _, h := range fr.parent.VertexHandles {
+		os = append(os, h)
+	}
+	return os
 }
 
 func (fr featureRenderer) Refresh() {
+	f := fr.parent.Controller.Plan.Features[fr.parent.FeatureID]
+	fr.parent.updateOutline()
+
+	// Plain rectangles fill their border. Other outlines, and turned
+	// rectangles, are drawn by the fill, leaving the border around them to
+	// show the selection.
+	fr.parent.Border.FillColor = colornames.Lawngreen
+	fr.parent.Fill.Hide()
+	if _, degrees := fr.parent.Controller.Plan.Placement(fr.parent.FeatureID); f.Shape.GetKind() != models.SHAPE_RECTANGLE || degrees != 0 {
+		fr.parent.Border.FillColor = color.Transparent
+		fr.parent.Fill.Show()
+		fr.parent.Fill.Refresh()
+	}
 	fr.parent.Border.Refresh()
 
-	fr.parent.Label.SetText(fr.parent.Controller.Plan.Features[fr.parent.FeatureID].Name)
+	if fr.parent.selected {
+		fr.parent.RotationHandle.Show()
+	} else {
+		fr.parent.RotationHandle.Hide()
+	}
+	fr.parent.syncVertexHandles()
+	fr.layoutHandles()
+
+	label := f.Name
+	fr.parent.Label.Importance = widget.MediumImportance
+	if fr.parent.warning != "" {
+		label += "\n⚠ " + fr.parent.warning
+		fr.parent.Label.Importance = widget.DangerImportance
+	}
+	fr.parent.Label.SetText(label)
 
 	fr.parent.TopHandle.Refresh()
 	fr.parent.BottomHandle.Refresh()
 	fr.parent.LeftHandle.Refresh()
 	fr.parent.RightHandle.Refresh()
+	fr.parent.TopLeftHandle.Refresh()
+	fr.parent.TopRightHandle.Refresh()
+	fr.parent.BottomRightHandle.Refresh()
+	fr.parent.BottomLeftHandle.Refresh()
+	fr.parent.RotationHandle.Refresh()
 
 	// fr.Layout(fr.MinSize())
 }
@@ -3,15 +3,20 @@ package ui
 import (
 	"fmt"
 	"strconv"
-	"strings"
 
 	"github.com/bcicen/go-units"
+	"github.com/cpgillem/garden-planner/models"
 )
 
 var AnyUnit units.Unit = units.NewUnit("Any", "")
 
 type DimensionFormatter struct {
 	fmtOptions units.FmtOptions
+
+	// Unit and precision of lengths read off the plan, such as rulers and
+	// measurements.
+	displayUnit   units.Unit
+	lengthOptions units.FmtOptions
 }
 
 func NewFormatter() *DimensionFormatter {
@@ -21,6 +26,12 @@ func NewFormatter() *DimensionFormatter {
 			Short:     true,
 			Precision: 6,
 		},
+		displayUnit: models.BaseLengthUnit,
+		lengthOptions: units.FmtOptions{
+			Label:     true,
+			Short:     true,
+			Precision: 2,
+		},
 	}
 }
 
@@ -37,33 +48,11 @@ func (formatter *DimensionFormatter) ToDecimal(s string) (float32, error) {
 // Normally, the input would be a float, followed by a space, followed by a symbol.
 // TODO: Accept units such as "
 func (formatter *DimensionFormatter) ToDimension(s string) (units.Value, error) {
-	zero := units.NewValue(0, AnyUnit)
-
-	// Check format.
-	firstSpace := strings.Index(strings.TrimSpace(s), " ")
-	if firstSpace < 0 {
-		return zero, NewDimensionError(s, "Dimension format: [quantity] [unit].")
-	}
-
-	// Parse out quantity and unit string.
-	qty := strings.TrimSpace(s[:firstSpace])
-	unitStr := strings.TrimSpace(s[firstSpace+1:])
-
-	// Parse quantity to float.
-	f, err := strconv.ParseFloat(qty, 32)
-	if err != nil {
-		return zero, NewDimensionError(s, "Quantity must be a number.")
-	}
-
-	// Parse unit string.
-	unit, err := units.Find(unitStr)
+	value, err := models.ParseDimension(s)
 	if err != nil {
-		return zero, NewDimensionError(s, "Unrecognizable unit.")
+		return units.NewValue(0, AnyUnit), NewDimensionError(s, err.Error())
 	}
 
-	// Create value.
-	value := units.NewValue(float64(f), unit)
-
 	return value, nil
 }
 
@@ -94,6 +83,26 @@ func (formatter *DimensionFormatter) FormatDimension(value units.Value) string {
 	return value.Fmt(formatter.fmtOptions)
 }
 
+// Sets the unit that lengths on the plan are shown in.
+func (formatter *DimensionFormatter) SetDisplayUnit(unit units.Unit) {
+	formatter.displayUnit = unit
+}
+
+func (formatter *DimensionFormatter) DisplayUnit() units.Unit {
+	return formatter.displayUnit
+}
+
+// How many base units make one display unit.
+func (formatter *DimensionFormatter) DisplayUnitLength() float32 {
+	return float32(units.NewValue(1, formatter.displayUnit).MustConvert(models.BaseLengthUnit).Float())
+}
+
+// Formats a length in base units in the display unit, to two places.
+func (formatter *DimensionFormatter) FormatLength(length float32) string {
+	value := units.NewValue(float64(length/formatter.DisplayUnitLength()), formatter.displayUnit)
+	return value.Fmt(formatter.lengthOptions)
+}
+
 type DimensionError struct {
 	input string
 	msg   string
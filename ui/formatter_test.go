@@ -49,3 +49,23 @@ func TestToDimension(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatLength(t *testing.T) {
+	cases := []struct {
+		length float32
+		unit   units.Unit
+		want   string
+	}{
+		{18, units.Inch, "18 in"},
+		{18, units.Foot, "1.5 ft"},
+		{100, units.Meter, "2.54 m"},
+	}
+
+	formatter := NewFormatter()
+	for _, c := range cases {
+		formatter.SetDisplayUnit(c.unit)
+		if got := formatter.FormatLength(c.length); got != c.want {
+			t.Errorf("FormatLength(%v) in %s == %q; want %q", c.length, c.unit.Name, got, c.want)
+		}
+	}
+}
@@ -2,16 +2,43 @@ package ui
 
 import (
 	"math"
+	"sort"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/widget"
 	"github.com/cpgillem/garden-planner/controllers"
 	"github.com/cpgillem/garden-planner/geometry"
+	"github.com/cpgillem/garden-planner/irrigation"
 	"github.com/cpgillem/garden-planner/models"
 	"golang.org/x/image/colornames"
 )
 
+// How close, in pixels, a dragged feature must come to another's edge or
+// centre to line up with it.
+const SNAP_DISTANCE = 8
+
+// How much the zoom buttons zoom by, how far in pixels the mouse wheel
+// scrolls to zoom by e (a notch zooms about as much as a button), and the
+// room in pixels left around plans zoomed to fit.
+const (
+	ZOOM_STEP   = 1.25
+	ZOOM_SCROLL = 45
+	FIT_MARGIN  = 20
+)
+
+// Smallest size of the garden widget, in pixels. The view zooms to fit
+// plans bigger than this.
+const MIN_GARDEN_SIZE = 400
+
+// A place picked with the measuring tool: the tapped point, and the feature
+// edge it was on. Away from edges, the edge is just the point.
+type measureTarget struct {
+	point geometry.Vector
+	edge  geometry.Segment
+}
+
 type GardenWidget struct {
 	widget.BaseWidget
 
@@ -25,43 +52,102 @@ type GardenWidget struct {
 	hGridlines []*canvas.Line
 	vGridlines []*canvas.Line
 
+	// Companion planting links between nearby features.
+	companions     []controllers.Companion
+	companionLinks []*canvas.Line
+
+	// Overlaps, features outside the plan, and paths narrower than the
+	// path width.
+	collisions []controllers.Collision
+
+	// Lines a dragged feature is lined up with.
+	guides     []geometry.Guide
+	guideLines []*canvas.Line
+
+	// Measuring tool. Each tap picks a target, the second shows the
+	// distance between them, and the next starts again.
+	measuring      bool
+	measureTargets []measureTarget
+	measureEdges   []*canvas.Line
+	measureDots    []*canvas.Circle
+	measureLine    *canvas.Line
+
+	// Drip irrigation network, drawn over the features.
+	irrigationReport irrigation.Report
+	tubeIDs          []models.IrrigationID
+	tubeLines        []*canvas.Line
+	nodeIDs          []models.IrrigationID
+	nodeDots         []*canvas.Circle
+	emitterDots      []*canvas.Circle
+
+	// Part of the plan in view. The view zooms to fit the plan at the
+	// first layout after a plan opens, once the widget has a size.
+	view       Viewport
+	fitPending bool
+
+	// Drags pan the view while the middle button or the pan key is held.
+	panning bool
+	panKey  bool
+
 	// Drawing Settings
-	scale       float32
-	gridSpacing float32
+	gridSpacing       float32
+	companionDistance float32
+	pathWidth         float32
+	snapToGrid        bool
+	snapToFeatures    bool
 
 	// Controller reference
 	Controller *controllers.PlanController
 
+	// Plant data for companion hints. Hints are off while nil.
+	Plants *controllers.PlantController
+
 	// Events
 	OnFeatureDragged       func(id models.FeatureID, e *fyne.DragEvent)
 	OnFeatureDragEnd       func(id models.FeatureID)
 	OnFeatureHandleDragged func(id models.FeatureID, edge geometry.BoxEdge, e *fyne.DragEvent)
 	OnFeatureHandleDragEnd func(id models.FeatureID, edge geometry.BoxEdge)
+	OnFeatureCornerDragEnd func(id models.FeatureID, corner geometry.BoxCorner)
 	OnFeatureTapped        func(id models.FeatureID)
+	OnPointerMoved         func(p geometry.Vector)
+	OnPointerLeft          func()
+	OnMeasured             func(from geometry.Vector, to geometry.Vector)
+	OnViewChanged          func(view Viewport)
 }
 
 // Create a new garden widget. Requires a plan. Agnostic to base units.
 //
 // controller allows the widget to modify features.
 //
-// scale is a multiplier on the base unit for display.
+// scale is a multiplier on the base unit for display, until the plan is
+// zoomed to fit.
 //
 // gridSpacing defines how many base units between each gridline.
 func NewGardenWidget(controller *controllers.PlanController, scale float32, gridSpacing float32) *GardenWidget {
 	gardenWidget := &GardenWidget{
 		Controller:             controller,
-		scale:                  scale,
+		view:                   NewViewport(scale),
 		gridSpacing:            gridSpacing,
+		snapToGrid:             true,
+		snapToFeatures:         true,
 		features:               map[models.FeatureID]*FeatureWidget{},
 		OnFeatureDragged:       func(id models.FeatureID, e *fyne.DragEvent) {},
 		OnFeatureDragEnd:       func(id models.FeatureID) {},
 		OnFeatureHandleDragged: func(id models.FeatureID, edge geometry.BoxEdge, e *fyne.DragEvent) {},
 		OnFeatureHandleDragEnd: func(id models.FeatureID, edge geometry.BoxEdge) {},
+		OnFeatureCornerDragEnd: func(id models.FeatureID, corner geometry.BoxCorner) {},
 		OnFeatureTapped:        func(id models.FeatureID) {},
+		OnPointerMoved:         func(p geometry.Vector) {},
+		OnPointerLeft:          func() {},
+		OnMeasured:             func(from geometry.Vector, to geometry.Vector) {},
+		OnViewChanged:          func(view Viewport) {},
 		background:             canvas.NewRectangle(colornames.White),
 		hGridlines:             []*canvas.Line{},
 		vGridlines:             []*canvas.Line{},
+		measureLine:            canvas.NewLine(colornames.Darkorange),
 	}
+	gardenWidget.measureLine.StrokeWidth = 2
+	gardenWidget.measureLine.Hidden = true
 
 	gardenWidget.OpenPlan(gardenWidget.Controller)
 	gardenWidget.Refresh()
@@ -72,26 +158,40 @@ func NewGardenWidget(controller *controllers.PlanController, scale float32, grid
 
 // Create a new feature widget.
 func (g *GardenWidget) AddFeature(id models.FeatureID) {
-	fw := NewFeatureWidget(id, g.Controller, g.scale)
-	fw.OnDragEnd = func() {
-		g.Refresh()
-		g.OnFeatureDragEnd(fw.FeatureID)
+	fw := NewFeatureWidget(id, g.Controller, g.view.Scale)
+	fw.OnDragEnd = func(id models.FeatureID) {
+		g.Recalculate()
+		g.OnFeatureDragEnd(id)
 	}
-	fw.OnDragged = func(e *fyne.DragEvent) {
+	fw.OnDragged = func(id models.FeatureID, e *fyne.DragEvent) {
 		g.Refresh()
-		g.OnFeatureDragged(fw.FeatureID, e)
+		g.OnFeatureDragged(id, e)
 	}
 	fw.OnHandleDragged = func(edge geometry.BoxEdge, e *fyne.DragEvent) {
 		g.Refresh()
 		g.OnFeatureHandleDragged(fw.FeatureID, edge, e)
 	}
 	fw.OnHandleDragEnd = func(edge geometry.BoxEdge) {
+		g.Recalculate()
 		g.OnFeatureHandleDragEnd(fw.FeatureID, edge)
 	}
-	fw.OnTapped = func() {
-		g.SelectFeature(fw.FeatureID)
-		g.OnFeatureTapped(fw.FeatureID)
+	fw.OnCornerDragged = func(corner geometry.BoxCorner, e *fyne.DragEvent) {
+		g.Refresh()
+	}
+	fw.OnCornerDragEnd = func(corner geometry.BoxCorner) {
+		g.Recalculate()
+		g.OnFeatureCornerDragEnd(fw.FeatureID, corner)
+	}
+	fw.OnTapped = func(id models.FeatureID) {
+		g.SelectFeature(id)
+		g.OnFeatureTapped(id)
 	}
+	fw.OnMouseDown = g.MouseDown
+	fw.OnMouseUp = g.MouseUp
+	fw.ToolDragged = g.toolDragged
+	fw.ToolTapped = g.toolTapped
+	fw.OnGuides = g.showGuides
+	fw.NewSnapper = g.newSnapper
 	g.features[id] = fw
 }
 
@@ -99,8 +199,16 @@ func (g *GardenWidget) RemoveFeature(id models.FeatureID) {
 	// Deselect all features.
 	g.SelectNone()
 
-	// Remove feature.
-	g.features[id].Hide()
+	// Remove feature. The renderer builds its object list from the map.
+	delete(g.features, id)
+	g.Recalculate()
+}
+
+// Flags a problem on a feature. An empty warning clears the flag.
+func (g *GardenWidget) SetFeatureWarning(id models.FeatureID, warning string) {
+	if fw, ok := g.features[id]; ok {
+		fw.SetWarning(warning)
+	}
 }
 
 func (g *GardenWidget) SelectFeature(id models.FeatureID) {
@@ -138,9 +246,143 @@ func (g *GardenWidget) CalculateGridlines() {
 	}
 }
 
+// Sets the zoom in pixels per base unit, keeping the middle of the view in
+// place.
 func (g *GardenWidget) SetScale(s float32) {
-	g.scale = s
+	g.ZoomAt(g.middle(), s/g.view.Scale)
+}
+
+func (g *GardenWidget) Scale() float32 {
+	return g.view.Scale
+}
+
+// Part of the plan in view.
+func (g *GardenWidget) Viewport() Viewport {
+	return g.view
+}
+
+// Zooms by a factor, keeping the point under a position in place.
+func (g *GardenWidget) ZoomAt(pos fyne.Position, factor float32) {
+	g.view.ZoomAt(pos, factor)
+	g.Refresh()
+}
+
+func (g *GardenWidget) ZoomIn() {
+	g.ZoomAt(g.middle(), ZOOM_STEP)
+}
+
+func (g *GardenWidget) ZoomOut() {
+	g.ZoomAt(g.middle(), 1/ZOOM_STEP)
+}
+
+// Zooms and pans to show the whole plan and every feature.
+func (g *GardenWidget) ZoomToFit() {
+	if g.Size().IsZero() {
+		g.fitPending = true
+		return
+	}
+	if area, ok := g.planExtent(); ok {
+		g.view.Fit(area, g.Size(), FIT_MARGIN)
+	}
+	g.Refresh()
+}
+
+// Zooms and pans to show a feature.
+func (g *GardenWidget) ZoomToFeature(id models.FeatureID) {
+	if g.Controller.Plan.Features[id] == nil {
+		return
+	}
+	g.view.Fit(g.Controller.Plan.Shape(id).Bounds(), g.Size(), FIT_MARGIN)
+	g.Refresh()
+}
+
+// Moves the view by a distance in pixels.
+func (g *GardenWidget) Pan(dx float32, dy float32) {
+	g.view.Pan(dx, dy)
+	g.Refresh()
+}
+
+// Sets whether the pan key, usually space, is held. Drags that start while
+// it is held pan the view.
+func (g *GardenWidget) SetPanKey(held bool) {
+	g.panKey = held
+}
+
+// Turns the measuring tool on or off. While it is on, taps pick the points
+// or feature edges to measure between instead of selecting features.
+func (g *GardenWidget) SetMeasuring(on bool) {
+	g.measuring = on
+	g.measureTargets = []measureTarget{}
+	g.CalculateMeasurement()
+	g.Refresh()
+}
+
+func (g *GardenWidget) IsMeasuring() bool {
+	return g.measuring
+}
+
+// Nearest points of the two measured targets, or false until two are
+// picked.
+func (g *GardenWidget) Measurement() (geometry.Vector, geometry.Vector, bool) {
+	if len(g.measureTargets) < 2 {
+		return geometry.Vector{}, geometry.Vector{}, false
+	}
+	from, to := geometry.ClosestPoints(g.measureTargets[0].edge, g.measureTargets[1].edge)
+	return from, to, true
+}
+
+// Picks a target for the measuring tool. Taps close to a feature's edge
+// pick the whole edge.
+func (g *GardenWidget) measureTapped(pos fyne.Position) {
+	p := g.view.ToPlan(pos)
+	target := measureTarget{point: p, edge: geometry.NewPointSegment(p)}
+	if edge, ok := g.Controller.EdgeNear(p, SNAP_DISTANCE/g.view.Scale); ok {
+		target.edge = edge
+	}
+
+	if len(g.measureTargets) == 2 {
+		g.measureTargets = []measureTarget{}
+	}
+	g.measureTargets = append(g.measureTargets, target)
+	g.CalculateMeasurement()
 	g.Refresh()
+
+	if from, to, ok := g.Measurement(); ok {
+		g.OnMeasured(from, to)
+	}
+}
+
+// Recreates the measurement cache: the picked edges, a dot on each tapped
+// point, and the line between the targets once there are two.
+func (g *GardenWidget) CalculateMeasurement() {
+	g.measureEdges = []*canvas.Line{}
+	g.measureDots = []*canvas.Circle{}
+	for range g.measureTargets {
+		edge := canvas.NewLine(colornames.Darkorange)
+		edge.StrokeWidth = 3
+		g.measureEdges = append(g.measureEdges, edge)
+		g.measureDots = append(g.measureDots, canvas.NewCircle(colornames.Darkorange))
+	}
+	g.measureLine.Hidden = len(g.measureTargets) < 2
+}
+
+// Middle of the widget.
+func (g *GardenWidget) middle() fyne.Position {
+	return fyne.NewPos(g.Size().Width/2, g.Size().Height/2)
+}
+
+// Box around the plan and all its features, or false for a blank plan.
+func (g *GardenWidget) planExtent() (geometry.Box, bool) {
+	area, ok := g.Controller.Plan.Box, !g.Controller.Plan.Box.IsEmpty()
+	for id := range g.Controller.Plan.Features {
+		bounds := g.Controller.Plan.Shape(id).Bounds()
+		if ok {
+			area = area.Union(&bounds)
+		} else {
+			area, ok = bounds, true
+		}
+	}
+	return area, ok
 }
 
 func (g *GardenWidget) SetGridSpacing(s float32) {
@@ -148,6 +390,162 @@ func (g *GardenWidget) SetGridSpacing(s float32) {
 	g.Refresh()
 }
 
+// Sets whether dragged features snap to the grid, and whether they line up
+// with other features.
+func (g *GardenWidget) SetSnapping(grid bool, features bool) {
+	g.snapToGrid = grid
+	g.snapToFeatures = features
+}
+
+// Snapper for dragging a feature, or nil while snapping is off.
+func (g *GardenWidget) newSnapper(id models.FeatureID) *geometry.Snapper {
+	if !g.snapToGrid && !g.snapToFeatures {
+		return nil
+	}
+
+	grid := float32(0)
+	if g.snapToGrid {
+		grid = g.gridSpacing
+	}
+	s := geometry.NewSnapper(grid, 0)
+	if g.snapToFeatures {
+		s = controllers.NewFeatureSnapper(g.Controller, id, grid, SNAP_DISTANCE/g.view.Scale)
+	}
+	return &s
+}
+
+// Recreates the guide cache. Guides are drawn at the next refresh.
+func (g *GardenWidget) showGuides(guides []geometry.Guide) {
+	g.guides = guides
+	g.guideLines = []*canvas.Line{}
+	for range guides {
+		line := canvas.NewLine(colornames.Magenta)
+		line.StrokeWidth = 1
+		g.guideLines = append(g.guideLines, line)
+	}
+}
+
+// Sets how close, in base units, features must be for companion hints.
+func (g *GardenWidget) SetCompanionDistance(d float32) {
+	g.companionDistance = d
+	g.CalculateCompanionLinks()
+	g.Refresh()
+}
+
+func (g *GardenWidget) CompanionDistance() float32 {
+	return g.companionDistance
+}
+
+// Nearby features whose plants interact, as of the last recalculation.
+func (g *GardenWidget) Companions() []controllers.Companion {
+	return g.companions
+}
+
+// Finds interacting neighbours and recreates the link cache.
+func (g *GardenWidget) CalculateCompanionLinks() {
+	g.companions = []controllers.Companion{}
+	g.companionLinks = []*canvas.Line{}
+	if g.Plants == nil {
+		return
+	}
+
+	g.companions = controllers.FindCompanions(g.Controller, g.Plants, g.companionDistance)
+	for _, c := range g.companions {
+		link := canvas.NewLine(colornames.Green)
+		if c.InteractionType == models.ANTAGONISTIC {
+			link.StrokeColor = colornames.Red
+		}
+		link.StrokeWidth = 3
+		g.companionLinks = append(g.companionLinks, link)
+	}
+}
+
+// Sets the narrowest gap, in base units, allowed between features side by
+// side. 0 allows features to touch.
+func (g *GardenWidget) SetPathWidth(w float32) {
+	g.pathWidth = w
+	g.CalculateCollisions()
+	g.Refresh()
+}
+
+func (g *GardenWidget) PathWidth() float32 {
+	return g.pathWidth
+}
+
+// Collisions between features, as of the last recalculation.
+func (g *GardenWidget) Collisions() []controllers.Collision {
+	return g.collisions
+}
+
+// Finds collisions and flags the features involved.
+func (g *GardenWidget) CalculateCollisions() {
+	g.collisions = controllers.FindCollisions(g.Controller, g.pathWidth)
+
+	colliding := map[models.FeatureID]bool{}
+	for _, c := range g.collisions {
+		colliding[c.A] = true
+		colliding[c.B] = true
+	}
+	for id, fw := range g.features {
+		fw.SetColliding(colliding[id])
+	}
+}
+
+// Nodes, tubes and emitters of the irrigation network, as of the last
+// recalculation.
+func (g *GardenWidget) IrrigationReport() irrigation.Report {
+	return g.irrigationReport
+}
+
+// Analyzes the irrigation network and recreates its drawing cache. Tubes
+// with problems are drawn in red.
+func (g *GardenWidget) CalculateIrrigation() {
+	network := &g.Controller.Plan.Irrigation
+	g.irrigationReport = irrigation.Analyze(g.Controller.Plan, irrigation.DefaultLimits())
+
+	troubled := map[models.IrrigationID]bool{}
+	for _, p := range g.irrigationReport.Problems {
+		troubled[p.Tube] = true
+	}
+
+	g.tubeIDs = []models.IrrigationID{}
+	g.tubeLines = []*canvas.Line{}
+	for id, t := range network.Tubes {
+		if network.Nodes[t.From] == nil || network.Nodes[t.To] == nil {
+			continue
+		}
+		line := canvas.NewLine(colornames.Navy)
+		line.StrokeWidth = 4
+		if t.Kind == models.LATERAL {
+			line.StrokeColor = colornames.Dodgerblue
+			line.StrokeWidth = 2
+		}
+		if troubled[id] {
+			line.StrokeColor = colornames.Red
+		}
+		g.tubeIDs = append(g.tubeIDs, id)
+		g.tubeLines = append(g.tubeLines, line)
+	}
+
+	g.nodeIDs = []models.IrrigationID{}
+	g.nodeDots = []*canvas.Circle{}
+	for id, n := range network.Nodes {
+		dot := canvas.NewCircle(colornames.Navy)
+		if n.Source {
+			dot.FillColor = colornames.Dodgerblue
+			dot.StrokeColor = colornames.Navy
+			dot.StrokeWidth = 2
+		}
+		g.nodeIDs = append(g.nodeIDs, id)
+		g.nodeDots = append(g.nodeDots, dot)
+	}
+
+	g.emitterDots = []*canvas.Circle{}
+	for range g.irrigationReport.Emitters {
+		g.emitterDots = append(g.emitterDots, canvas.NewCircle(colornames.Deepskyblue))
+	}
+}
+
 // Opens a plan for viewing.
 func (g *GardenWidget) OpenPlan(controller *controllers.PlanController) {
 	g.Controller = controller
@@ -155,24 +553,113 @@ func (g *GardenWidget) OpenPlan(controller *controllers.PlanController) {
 	// Calculate gridlines.
 	g.CalculateGridlines()
 
-	// Add features
+	// Add features, dropping any left over from a previous plan.
+	g.features = map[models.FeatureID]*FeatureWidget{}
 	for i := range controller.Plan.Features {
-		g.AddFeature(models.FeatureID(i))
+		g.AddFeature(i)
 	}
+	g.fitPending = true
+
+	// Measurements were of the previous plan.
+	g.measureTargets = []measureTarget{}
+	g.CalculateMeasurement()
 
+	g.Recalculate()
+}
+
+// Reanalyzes the plan after its features change. Companions, collisions
+// and the irrigation network are too slow to analyze on every refresh, so
+// they are analyzed here once a change or gesture is over.
+func (g *GardenWidget) Recalculate() {
+	g.CalculateCompanionLinks()
+	g.CalculateIrrigation()
+	g.CalculateCollisions()
 	g.Refresh()
 }
 
+// Feature IDs ordered by nesting depth, then by ID so the order is stable.
+func (g *GardenWidget) drawOrder() []models.FeatureID {
+	ids := []models.FeatureID{}
+	depths := map[models.FeatureID]int{}
+	for id := range g.features {
+		ids = append(ids, id)
+		depths[id] = g.Controller.Plan.Depth(id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if depths[ids[i]] == depths[ids[j]] {
+			return ids[i] < ids[j]
+		}
+		return depths[ids[i]] < depths[ids[j]]
+	})
+	return ids
+}
+
 func (w *GardenWidget) CreateRenderer() fyne.WidgetRenderer {
 	return newGardenRenderer(w)
 }
 
 // Events
 
-// On scroll, adjust the scale.
+// On scroll, zoom in or out around the pointer.
 func (w *GardenWidget) Scrolled(e *fyne.ScrollEvent) {
-	adjDY := e.Scrolled.DY / 250
-	w.SetScale(w.scale + adjDY)
+	w.ZoomAt(e.Position, float32(math.Exp(float64(e.Scrolled.DY/ZOOM_SCROLL))))
+}
+
+// Starts panning for presses of the middle button, or the primary button
+// while the pan key is held. Presses on features arrive here too.
+func (w *GardenWidget) MouseDown(e *desktop.MouseEvent) {
+	w.panning = e.Button == desktop.MouseButtonTertiary || (e.Button == desktop.MouseButtonPrimary && w.panKey)
+}
+
+func (w *GardenWidget) MouseUp(e *desktop.MouseEvent) {
+	w.panning = false
+}
+
+// Drags on the background pan the view while a pan is under way.
+func (w *GardenWidget) Dragged(e *fyne.DragEvent) {
+	w.toolDragged(e)
+}
+
+func (w *GardenWidget) DragEnd() {
+	w.panning = false
+}
+
+// Taps on the background are only used by the measuring tool.
+func (w *GardenWidget) Tapped(e *fyne.PointEvent) {
+	w.toolTapped(e.Position)
+}
+
+// Pans the view if a pan is under way. Drags do nothing else while
+// measuring. Returns whether the drag was used.
+func (w *GardenWidget) toolDragged(e *fyne.DragEvent) bool {
+	if w.panning {
+		w.Pan(e.Dragged.DX, e.Dragged.DY)
+		return true
+	}
+	return w.measuring
+}
+
+// Measures from a tap while measuring. Returns whether the tap was used.
+func (w *GardenWidget) toolTapped(pos fyne.Position) bool {
+	if !w.measuring {
+		return false
+	}
+	w.measureTapped(pos)
+	return true
+}
+
+// Report where the pointer is on the plan.
+func (w *GardenWidget) MouseIn(e *desktop.MouseEvent) {
+	w.OnPointerMoved(w.view.ToPlan(e.Position))
+}
+
+func (w *GardenWidget) MouseMoved(e *desktop.MouseEvent) {
+	w.OnPointerMoved(w.view.ToPlan(e.Position))
+}
+
+func (w *GardenWidget) MouseOut() {
+	w.OnPointerLeft()
 }
 
 type gardenRenderer struct {
@@ -197,69 +684,116 @@ func (g gardenRenderer) Destroy() {
 
 }
 
-// Layout implements fyne.WidgetRenderer.
+// Layout implements fyne.WidgetRenderer. Everything is placed through the
+// view.
 func (g gardenRenderer) Layout(s fyne.Size) {
+	view := &g.parent.view
+	if g.parent.fitPending && !s.IsZero() {
+		g.parent.fitPending = false
+		if area, ok := g.parent.planExtent(); ok {
+			view.Fit(area, s, FIT_MARGIN)
+		}
+	}
+	plan := g.parent.Controller.Plan.Box
+	origin := view.ToScreen(geometry.NewVector(0, 0, 0))
+
 	// Layout background.
 	g.parent.background.StrokeWidth = 1
 	g.parent.background.StrokeColor = colornames.Black
-	g.parent.background.Move(fyne.NewPos(0, 0))
+	g.parent.background.Move(origin)
 	g.parent.background.Resize(fyne.NewSize(
-		g.parent.Controller.Plan.Box.GetWidth()*g.parent.scale,
-		g.parent.Controller.Plan.Box.GetHeight()*g.parent.scale,
+		plan.GetWidth()*view.Scale,
+		plan.GetHeight()*view.Scale,
 	))
 
 	// Layout horizontal gridlines.
 	for i := range g.parent.hGridlines {
-		y := float32(float64(i) * float64(g.parent.gridSpacing) * float64(g.parent.scale))
-		var left float32 = 0
-		var right float32 = g.parent.Controller.Plan.Box.GetWidth() * g.parent.scale
-		g.parent.hGridlines[i].Position1 = fyne.NewPos(
-			left,
-			y,
-		)
-		g.parent.hGridlines[i].Position2 = fyne.NewPos(
-			right,
-			y,
-		)
+		y := float32(i) * g.parent.gridSpacing
+		g.parent.hGridlines[i].Position1 = view.ToScreen(geometry.NewVector(0, y, 0))
+		g.parent.hGridlines[i].Position2 = view.ToScreen(geometry.NewVector(plan.GetWidth(), y, 0))
 	}
 
 	// Layout vertical gridlines.
 	for i := range g.parent.vGridlines {
-		x := float32(float64(i) * float64(g.parent.gridSpacing) * float64(g.parent.scale))
-		var top float32 = 0
-		var bottom float32 = g.parent.Controller.Plan.Box.GetHeight() * g.parent.scale
-		g.parent.vGridlines[i].Position1 = fyne.NewPos(
-			x,
-			top,
-		)
-		g.parent.vGridlines[i].Position2 = fyne.NewPos(
-			x,
-			bottom,
-		)
-	}
-
-	// Layout features.
+		x := float32(i) * g.parent.gridSpacing
+		g.parent.vGridlines[i].Position1 = view.ToScreen(geometry.NewVector(x, 0, 0))
+		g.parent.vGridlines[i].Position2 = view.ToScreen(geometry.NewVector(x, plan.GetHeight(), 0))
+	}
+
+	// Layout features over the box around their turned outlines. Nested
+	// features are positioned relative to their parents.
 	for i := range g.parent.features {
-		box := g.parent.Controller.Plan.Features[i].Box
+		box := g.parent.Controller.Plan.Shape(i).Bounds()
+		g.parent.features[i].SetScale(view.Scale)
 		g.parent.features[i].Resize(fyne.NewSize(
-			box.Size.X*g.parent.scale,
-			box.Size.Y*g.parent.scale,
-		))
-		g.parent.features[i].Move(fyne.NewPos(
-			box.Location.X*g.parent.scale,
-			box.Location.Y*g.parent.scale,
+			box.Size.X*view.Scale,
+			box.Size.Y*view.Scale,
 		))
+		g.parent.features[i].Move(view.ToScreen(box.Location))
+	}
+
+	// Layout companion links between feature centers.
+	for i, c := range g.parent.companions {
+		a, _ := g.parent.Controller.Plan.Placement(c.A)
+		b, _ := g.parent.Controller.Plan.Placement(c.B)
+		g.parent.companionLinks[i].Position1 = view.ToScreen(a.Center())
+		g.parent.companionLinks[i].Position2 = view.ToScreen(b.Center())
+	}
+
+	// Layout guides across the whole widget.
+	for i, guide := range g.parent.guides {
+		at := view.ToScreen(geometry.NewVector(guide.Position, guide.Position, 0))
+		if guide.Vertical {
+			g.parent.guideLines[i].Position1 = fyne.NewPos(at.X, 0)
+			g.parent.guideLines[i].Position2 = fyne.NewPos(at.X, s.Height)
+		} else {
+			g.parent.guideLines[i].Position1 = fyne.NewPos(0, at.Y)
+			g.parent.guideLines[i].Position2 = fyne.NewPos(s.Width, at.Y)
+		}
 	}
 
+	// Layout the irrigation network.
+	network := &g.parent.Controller.Plan.Irrigation
+	for i, id := range g.parent.tubeIDs {
+		t := network.Tubes[id]
+		g.parent.tubeLines[i].Position1 = view.ToScreen(network.Nodes[t.From].Location)
+		g.parent.tubeLines[i].Position2 = view.ToScreen(network.Nodes[t.To].Location)
+	}
+	for i, id := range g.parent.nodeIDs {
+		radius := float32(3)
+		if network.Nodes[id].Source {
+			radius = 6
+		}
+		placeDot(g.parent.nodeDots[i], view.ToScreen(network.Nodes[id].Location), radius)
+	}
+	for i, e := range g.parent.irrigationReport.Emitters {
+		placeDot(g.parent.emitterDots[i], view.ToScreen(e.Location), 2)
+	}
+
+	// Layout the measurement.
+	for i, t := range g.parent.measureTargets {
+		g.parent.measureEdges[i].Position1 = view.ToScreen(t.edge.A)
+		g.parent.measureEdges[i].Position2 = view.ToScreen(t.edge.B)
+		placeDot(g.parent.measureDots[i], view.ToScreen(t.point), 4)
+	}
+	if from, to, ok := g.parent.Measurement(); ok {
+		g.parent.measureLine.Position1 = view.ToScreen(from)
+		g.parent.measureLine.Position2 = view.ToScreen(to)
+	}
+
+	g.parent.OnViewChanged(*view)
 }
 
-// MinSize implements fyne.WidgetRenderer.
+// Centers a circle on a point.
+func placeDot(dot *canvas.Circle, center fyne.Position, radius float32) {
+	dot.Move(center.SubtractXY(radius, radius))
+	dot.Resize(fyne.NewSquareSize(radius * 2))
+}
+
+// MinSize implements fyne.WidgetRenderer. Plans of any size fit by
+// zooming, so the widget doesn't grow with them.
 func (g gardenRenderer) MinSize() fyne.Size {
-	size := g.parent.Controller.Plan.Box.Size
-	return fyne.NewSize(
-		size.X*g.parent.scale,
-		size.Y*g.parent.scale,
-	)
+	return fyne.NewSquareSize(MIN_GARDEN_SIZE)
 }
 
 // Objects implements fyne.WidgetRenderer.
@@ -277,10 +811,40 @@ func (g gardenRenderer) Objects() []fyne.CanvasObject {
 		os = append(os, g)
 	}
 
-	// Add features.
-	for _, f := range g.parent.features {
-		os = append(os, f)
+	// Add features, parents first so children are drawn and tapped on top.
+	for _, id := range g.parent.drawOrder() {
+		os = append(os, g.parent.features[id])
+	}
+
+	// Add companion links over the features.
+	for _, l := range g.parent.companionLinks {
+		os = append(os, l)
+	}
+
+	// Add alignment guides.
+	for _, l := range g.parent.guideLines {
+		os = append(os, l)
+	}
+
+	// Add the irrigation network on top.
+	for _, l := range g.parent.tubeLines {
+		os = append(os, l)
+	}
+	for _, d := range g.parent.emitterDots {
+		os = append(os, d)
+	}
+	for _, d := range g.parent.nodeDots {
+		os = append(os, d)
+	}
+
+	// Add the measurement over everything.
+	for _, l := range g.parent.measureEdges {
+		os = append(os, l)
+	}
+	for _, d := range g.parent.measureDots {
+		os = append(os, d)
 	}
+	os = append(os, g.parent.measureLine)
 	return os
 }
 
@@ -292,5 +856,5 @@ func (g gardenRenderer) Refresh() {
 		g.parent.features[i].Refresh()
 	}
 
-	g.Layout(g.MinSize())
+	g.Layout(g.parent.Size())
 }
@@ -1,9 +1,145 @@
 package ui
 
-import "fyne.io/fyne/v2/widget"
+import (
+	"fmt"
+	"strings"
 
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// An option shown by name that stands for a hidden ID.
+type IdOption struct {
+	ID   int
+	Name string
+}
+
+// Extends the fyne select entry to select options by a hidden ID. Typing
+// filters the drop-down to the options whose names contain the text.
 type IdSelectEntry struct {
 	widget.SelectEntry
+
+	options    []IdOption
+	selectedID int
+	resetting  bool
+
+	// Events
+	OnSelected func(id int)
+}
+
+func NewIdSelectEntry(options []IdOption) *IdSelectEntry {
+	e := &IdSelectEntry{
+		options:    options,
+		OnSelected: func(id int) {},
+	}
+	e.ExtendBaseWidget(e)
+	e.Wrapping = fyne.TextTruncate
+	e.SelectEntry.SetOptions(e.names(options))
+
+	e.SelectEntry.OnChanged = e.textChanged
+	e.SelectEntry.OnSubmitted = e.submitted
+
+	return e
 }
 
-// TODO: This will extend the fyne select widget to have a hidden ID.
+func (e *IdSelectEntry) SetOptions(options []IdOption) {
+	e.options = options
+	e.SelectEntry.SetOptions(e.names(options))
+	e.SetSelectedID(e.selectedID)
+}
+
+// Selects an option without firing an event. IDs without an option are
+// shown as unknown rather than dropped, so missing references stay visible.
+func (e *IdSelectEntry) SetSelectedID(id int) {
+	e.selectedID = id
+	e.resetText()
+}
+
+func (e *IdSelectEntry) SelectedID() int {
+	return e.selectedID
+}
+
+// Whether the selected ID matches one of the options.
+func (e *IdSelectEntry) IsKnown() bool {
+	_, ok := e.find(e.selectedID)
+	return ok
+}
+
+func (e *IdSelectEntry) resetText() {
+	e.resetting = true
+	defer func() { e.resetting = false }()
+
+	// Restore the full list along with the text.
+	e.SelectEntry.SetOptions(e.names(e.options))
+	if option, ok := e.find(e.selectedID); ok {
+		e.SetText(option.Name)
+	} else {
+		e.SetText(fmt.Sprintf("Unknown (#%d)", e.selectedID))
+	}
+}
+
+func (e *IdSelectEntry) find(id int) (IdOption, bool) {
+	for _, option := range e.options {
+		if option.ID == id {
+			return option, true
+		}
+	}
+	return IdOption{}, false
+}
+
+func (e *IdSelectEntry) names(options []IdOption) []string {
+	names := []string{}
+	for _, option := range options {
+		names = append(names, option.Name)
+	}
+	return names
+}
+
+// Options whose names contain the text, ignoring case.
+func (e *IdSelectEntry) matches(text string) []IdOption {
+	text = strings.ToLower(strings.TrimSpace(text))
+	matches := []IdOption{}
+	for _, option := range e.options {
+		if strings.Contains(strings.ToLower(option.Name), text) {
+			matches = append(matches, option)
+		}
+	}
+	return matches
+}
+
+func (e *IdSelectEntry) textChanged(text string) {
+	if e.resetting {
+		return
+	}
+
+	// An exact name, e.g. picked from the drop-down, selects right away.
+	for _, option := range e.options {
+		if option.Name == text {
+			e.selectOption(option)
+			return
+		}
+	}
+
+	e.SelectEntry.SetOptions(e.names(e.matches(text)))
+}
+
+func (e *IdSelectEntry) submitted(text string) {
+	// Accept the only remaining match; otherwise go back to the selection.
+	matches := e.matches(text)
+	if len(matches) == 1 {
+		e.selectOption(matches[0])
+		e.resetText()
+		return
+	}
+
+	e.resetText()
+}
+
+func (e *IdSelectEntry) selectOption(option IdOption) {
+	if option.ID == e.selectedID {
+		return
+	}
+
+	e.selectedID = option.ID
+	e.OnSelected(option.ID)
+}
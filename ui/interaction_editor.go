@@ -1,9 +1,118 @@
 package ui
 
-import "fyne.io/fyne/v2/widget"
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"github.com/cpgillem/garden-planner/controllers"
+	"github.com/cpgillem/garden-planner/models"
+)
 
+// Table of every other plant and how the edited plant interacts with it.
+// Changes go through the plant controller, so they are saved and can be
+// undone.
 type InteractionEditor struct {
 	widget.BaseWidget
+
+	list      *widget.List
+	symmetric *widget.Check
+
+	// Internal data
+	plantID int
+	others  []models.Plant
+
+	// Controller reference
+	Controller *controllers.PlantController
+
+	// Events
+	OnError func(err error)
+}
+
+func NewInteractionEditor(controller *controllers.PlantController) *InteractionEditor {
+	e := &InteractionEditor{
+		Controller: controller,
+		plantID:    models.NO_PLANT,
+		others:     []models.Plant{},
+		symmetric:  widget.NewCheck("Make symmetric", nil),
+		OnError:    func(err error) {},
+	}
+
+	e.list = widget.NewList(
+		func() int {
+			return len(e.others)
+		},
+		func() fyne.CanvasObject {
+			return container.New(
+				layout.NewGridLayout(2),
+				widget.NewLabel(""),
+				widget.NewSelect(interactionTypeNames(), nil),
+			)
+		},
+		e.updateRow,
+	)
+
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+// Shows the interactions of a plant. NO_PLANT clears the table.
+func (e *InteractionEditor) SetPlant(id int) {
+	e.plantID = id
+	e.Refresh()
+}
+
+func (e *InteractionEditor) Refresh() {
+	e.others = []models.Plant{}
+	if e.Controller.HasPlant(e.plantID) {
+		for _, p := range e.Controller.Plants() {
+			if p.ID != e.plantID {
+				e.others = append(e.others, p)
+			}
+		}
+	}
+
+	e.list.Refresh()
+	e.BaseWidget.Refresh()
+}
+
+func (e *InteractionEditor) updateRow(i widget.ListItemID, row fyne.CanvasObject) {
+	plant, _ := e.Controller.GetPlant(e.plantID)
+	other := e.others[i]
+
+	cells := row.(*fyne.Container).Objects
+	cells[0].(*widget.Label).SetText(other.Name)
+
+	// Rows are reused, so rebind the selector without firing it.
+	selector := cells[1].(*widget.Select)
+	selector.OnChanged = nil
+	selector.SetSelected(models.InteractionTypeName(plant.InteractionWith(other.ID)))
+	selector.OnChanged = func(name string) {
+		err := e.Controller.SetInteraction(plant.ID, other.ID, interactionTypeFromName(name), e.symmetric.Checked)
+		if err != nil {
+			e.OnError(err)
+			e.Refresh()
+		}
+	}
 }
 
-// TODO: table of plant types mapped to a type of interaction.
+func (e *InteractionEditor) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewBorder(e.symmetric, nil, nil, nil, e.list))
+}
+
+func interactionTypeNames() []string {
+	names := []string{}
+	for _, t := range models.InteractionTypes {
+		names = append(names, models.InteractionTypeName(t))
+	}
+	return names
+}
+
+func interactionTypeFromName(name string) uint16 {
+	for _, t := range models.InteractionTypes {
+		if models.InteractionTypeName(t) == name {
+			return t
+		}
+	}
+	return models.NEUTRAL
+}
@@ -1,9 +1,345 @@
 package ui
 
-import "fyne.io/fyne/v2/widget"
+import (
+	"fmt"
+	"image/color"
+	"sort"
 
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+	"github.com/cpgillem/garden-planner/controllers"
+	"github.com/cpgillem/garden-planner/models"
+	"golang.org/x/image/colornames"
+)
+
+// Orders of the plants in the matrix.
+const (
+	SORT_BY_NAME   = "Name"
+	SORT_BY_FAMILY = "Family"
+	SORT_BY_ID     = "ID"
+)
+
+var MatrixSortOrders = []string{SORT_BY_NAME, SORT_BY_FAMILY, SORT_BY_ID}
+
+// Side length of a cell.
+const matrixCellSize = 24
+
+// Grid of every plant against every other, coloured by how the row plant is
+// affected by the column plant. Cells where the two plants disagree are
+// outlined. Tapping a cell cycles its interaction type.
 type InteractionMatrix struct {
 	widget.BaseWidget
+
+	// Filtering and sorting
+	family    string
+	sortOrder string
+
+	// Internal widgets, rebuilt on refresh.
+	plants       []models.Plant
+	rowLabels    []*canvas.Text
+	columnLabels []*canvas.Text
+	cells        [][]*interactionCell
+	tooltip      *widget.Label
+	tooltipBack  *canvas.Rectangle
+
+	// Controller reference
+	Controller *controllers.PlantController
+
+	// Events
+	OnError func(err error)
+}
+
+func NewInteractionMatrix(controller *controllers.PlantController) *InteractionMatrix {
+	m := &InteractionMatrix{
+		Controller:  controller,
+		sortOrder:   SORT_BY_NAME,
+		tooltip:     widget.NewLabel(""),
+		tooltipBack: canvas.NewRectangle(colornames.Lightyellow),
+		OnError:     func(err error) {},
+	}
+	m.tooltipBack.StrokeColor = colornames.Gray
+	m.tooltipBack.StrokeWidth = 1
+	m.hideTooltip()
+
+	m.ExtendBaseWidget(m)
+	m.rebuild()
+	return m
+}
+
+// Only shows plants of a family. An empty family shows all plants.
+func (m *InteractionMatrix) SetFamily(family string) {
+	m.family = family
+	m.Refresh()
+}
+
+// Orders plants by one of MatrixSortOrders.
+func (m *InteractionMatrix) SetSortOrder(order string) {
+	m.sortOrder = order
+	m.Refresh()
+}
+
+func (m *InteractionMatrix) Refresh() {
+	m.rebuild()
+	m.BaseWidget.Refresh()
+}
+
+func (m *InteractionMatrix) CreateRenderer() fyne.WidgetRenderer {
+	return &matrixRenderer{parent: m}
+}
+
+// Recreates labels and cells for the plants currently shown.
+func (m *InteractionMatrix) rebuild() {
+	// The hovered cell is about to be replaced.
+	m.hideTooltip()
+
+	m.plants = []models.Plant{}
+	for _, p := range m.Controller.Plants() {
+		if m.family == "" || p.Family == m.family {
+			m.plants = append(m.plants, p)
+		}
+	}
+
+	sort.SliceStable(m.plants, func(i, j int) bool {
+		a, b := m.plants[i], m.plants[j]
+		switch m.sortOrder {
+		case SORT_BY_FAMILY:
+			if a.Family != b.Family {
+				return a.Family < b.Family
+			}
+			return a.Name < b.Name
+		case SORT_BY_ID:
+			return a.ID < b.ID
+		default:
+			return a.Name < b.Name
+		}
+	})
+
+	m.rowLabels = []*canvas.Text{}
+	m.columnLabels = []*canvas.Text{}
+	m.cells = [][]*interactionCell{}
+	for i, row := range m.plants {
+		m.rowLabels = append(m.rowLabels, canvas.NewText(row.Name, colornames.Black))
+		m.columnLabels = append(m.columnLabels, canvas.NewText(abbreviate(row.Name), colornames.Black))
+
+		cells := []*interactionCell{}
+		for j, column := range m.plants {
+			cells = append(cells, newInteractionCell(m, i, j, row.ID == column.ID))
+		}
+		m.cells = append(m.cells, cells)
+	}
+
+	for _, cells := range m.cells {
+		for _, cell := range cells {
+			cell.update()
+		}
+	}
+}
+
+// Text describing a cell, e.g. "Potato with Bean: Beneficial".
+func (m *InteractionMatrix) describe(row int, column int) string {
+	a, b := m.plants[row], m.plants[column]
+	if a.ID == b.ID {
+		return a.Name
+	}
+
+	text := fmt.Sprintf("%s with %s: %s", a.Name, b.Name, models.InteractionTypeName(a.InteractionWith(b.ID)))
+	if m.Controller.IsAsymmetric(a.ID, b.ID) {
+		text += fmt.Sprintf("\n%s with %s: %s", b.Name, a.Name, models.InteractionTypeName(b.InteractionWith(a.ID)))
+	}
+	return text
+}
+
+// Cycles a cell through the interaction types.
+func (m *InteractionMatrix) cycle(row int, column int) {
+	a, b := m.plants[row], m.plants[column]
+	next := models.InteractionTypes[0]
+	current := a.InteractionWith(b.ID)
+	for i, t := range models.InteractionTypes {
+		if t == current {
+			next = models.InteractionTypes[(i+1)%len(models.InteractionTypes)]
+		}
+	}
+
+	if err := m.Controller.SetInteraction(a.ID, b.ID, next, false); err != nil {
+		m.OnError(err)
+	}
+}
+
+func (m *InteractionMatrix) showTooltip(text string, pos fyne.Position) {
+	m.tooltip.SetText(text)
+	size := m.tooltip.MinSize()
+	m.tooltip.Resize(size)
+	m.tooltipBack.Resize(size)
+
+	// Keep clear of the pointer.
+	pos = pos.Add(fyne.NewPos(12, 12))
+	m.tooltip.Move(pos)
+	m.tooltipBack.Move(pos)
+
+	m.tooltip.Show()
+	m.tooltipBack.Show()
+}
+
+func (m *InteractionMatrix) hideTooltip() {
+	m.tooltip.Hide()
+	m.tooltipBack.Hide()
+}
+
+// Short column header, since there is no room for full names.
+func abbreviate(name string) string {
+	runes := []rune(name)
+	if len(runes) > 3 {
+		return string(runes[:3])
+	}
+	return name
+}
+
+func interactionColor(t uint16) color.Color {
+	switch t {
+	case models.BENEFICIAL:
+		return colornames.Mediumseagreen
+	case models.ANTAGONISTIC:
+		return colornames.Indianred
+	default:
+		return colornames.Whitesmoke
+	}
+}
+
+type matrixRenderer struct {
+	parent *InteractionMatrix
+}
+
+func (r *matrixRenderer) Destroy() {
+
+}
+
+// Width of the row labels and height of the column labels.
+func (r *matrixRenderer) headerSize() fyne.Size {
+	size := fyne.NewSize(0, 0)
+	for _, l := range r.parent.rowLabels {
+		size.Width = fyne.Max(size.Width, l.MinSize().Width)
+	}
+	for _, l := range r.parent.columnLabels {
+		size.Height = fyne.Max(size.Height, l.MinSize().Height)
+	}
+	return size.Add(fyne.NewSize(4, 4))
+}
+
+func (r *matrixRenderer) Layout(size fyne.Size) {
+	header := r.headerSize()
+
+	for i, l := range r.parent.rowLabels {
+		l.Resize(l.MinSize())
+		l.Move(fyne.NewPos(0, header.Height+float32(i)*matrixCellSize+(matrixCellSize-l.MinSize().Height)/2))
+	}
+	for j, l := range r.parent.columnLabels {
+		l.Resize(l.MinSize())
+		l.Move(fyne.NewPos(header.Width+float32(j)*matrixCellSize+(matrixCellSize-l.MinSize().Width)/2, 0))
+	}
+
+	for i, cells := range r.parent.cells {
+		for j, cell := range cells {
+			cell.Resize(fyne.NewSquareSize(matrixCellSize))
+			cell.Move(fyne.NewPos(
+				header.Width+float32(j)*matrixCellSize,
+				header.Height+float32(i)*matrixCellSize,
+			))
+		}
+	}
+}
+
+func (r *matrixRenderer) MinSize() fyne.Size {
+	n := float32(len(r.parent.plants))
+	return r.headerSize().Add(fyne.NewSquareSize(n * matrixCellSize))
+}
+
+func (r *matrixRenderer) Objects() []fyne.CanvasObject {
+	os := []fyne.CanvasObject{}
+	for _, l := range r.parent.rowLabels {
+		os = append(os, l)
+	}
+	for _, l := range r.parent.columnLabels {
+		os = append(os, l)
+	}
+	for _, cells := range r.parent.cells {
+		for _, cell := range cells {
+			os = append(os, cell)
+		}
+	}
+
+	// Tooltip on top of everything.
+	os = append(os, r.parent.tooltipBack, r.parent.tooltip)
+	return os
+}
+
+func (r *matrixRenderer) Refresh() {
+	r.Layout(r.parent.Size())
+	canvas.Refresh(r.parent)
+}
+
+// One cell of the matrix.
+type interactionCell struct {
+	widget.BaseWidget
+
+	matrix   *InteractionMatrix
+	row      int
+	column   int
+	diagonal bool
+
+	rect *canvas.Rectangle
+}
+
+func newInteractionCell(matrix *InteractionMatrix, row int, column int, diagonal bool) *interactionCell {
+	c := &interactionCell{
+		matrix:   matrix,
+		row:      row,
+		column:   column,
+		diagonal: diagonal,
+		rect:     canvas.NewRectangle(colornames.Whitesmoke),
+	}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+// Colours the cell from the plant data.
+func (c *interactionCell) update() {
+	a, b := c.matrix.plants[c.row], c.matrix.plants[c.column]
+	if c.diagonal {
+		c.rect.FillColor = colornames.Darkgray
+		return
+	}
+
+	c.rect.FillColor = interactionColor(a.InteractionWith(b.ID))
+	c.rect.StrokeColor = colornames.Lightgray
+	c.rect.StrokeWidth = 1
+	if c.matrix.Controller.IsAsymmetric(a.ID, b.ID) {
+		c.rect.StrokeColor = colornames.Darkorange
+		c.rect.StrokeWidth = 3
+	}
 }
 
-// TODO: A widget that visualizes companion planting interactions in a grid.
+func (c *interactionCell) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(c.rect)
+}
+
+func (c *interactionCell) Tapped(e *fyne.PointEvent) {
+	if !c.diagonal {
+		c.matrix.cycle(c.row, c.column)
+	}
+}
+
+// Implement desktop.Hoverable to show a tooltip.
+
+func (c *interactionCell) MouseIn(e *desktop.MouseEvent) {
+	c.MouseMoved(e)
+}
+
+func (c *interactionCell) MouseMoved(e *desktop.MouseEvent) {
+	c.matrix.showTooltip(c.matrix.describe(c.row, c.column), c.Position().Add(e.Position))
+}
+
+func (c *interactionCell) MouseOut() {
+	c.matrix.hideTooltip()
+}
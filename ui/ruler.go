@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/widget"
+	"golang.org/x/image/colornames"
+)
+
+// Thickness of a ruler, the least room between labelled ticks, and the
+// size of their labels, in pixels.
+const (
+	RULER_SIZE      = 36
+	RULER_TICK_GAP  = 60
+	RULER_TEXT_SIZE = 10
+)
+
+// Scale along the top or left of the garden, labelled in the display unit.
+// Follows the garden's view.
+type Ruler struct {
+	widget.BaseWidget
+
+	// Runs down the left side rather than across the top.
+	Vertical bool
+
+	view      Viewport
+	formatter *DimensionFormatter
+
+	// Drawing
+	background *canvas.Rectangle
+	ticks      []*canvas.Line
+	labels     []*canvas.Text
+}
+
+// Creates a new ruler, labelled by the formatter.
+func NewRuler(vertical bool, formatter *DimensionFormatter) *Ruler {
+	r := &Ruler{
+		Vertical:   vertical,
+		view:       NewViewport(1),
+		formatter:  formatter,
+		background: canvas.NewRectangle(colornames.Whitesmoke),
+		ticks:      []*canvas.Line{},
+		labels:     []*canvas.Text{},
+	}
+	r.background.StrokeColor = colornames.Gray
+	r.background.StrokeWidth = 1
+
+	r.ExtendBaseWidget(r)
+	return r
+}
+
+// Sets the part of the plan in view, matching the garden.
+func (r *Ruler) SetView(view Viewport) {
+	r.view = view
+	r.Refresh()
+}
+
+func (r *Ruler) CreateRenderer() fyne.WidgetRenderer {
+	return rulerRenderer{parent: r}
+}
+
+// Distance between labelled ticks, in display units: the smallest 1, 2 or
+// 5 times a power of ten that leaves at least minGap pixels between them.
+func TickStep(pixelsPerUnit float32, minGap float32) float32 {
+	if pixelsPerUnit <= 0 || minGap <= 0 {
+		return 1
+	}
+	// Allow for rounding, so exact fits aren't pushed to the next step.
+	least := float64(minGap) / float64(pixelsPerUnit) * (1 - 1e-6)
+	power := math.Pow(10, math.Floor(math.Log10(least)))
+	for _, m := range []float64{1, 2, 5} {
+		if m*power >= least {
+			return float32(m * power)
+		}
+	}
+	return float32(10 * power)
+}
+
+type rulerRenderer struct {
+	parent *Ruler
+}
+
+// Destroy implements fyne.WidgetRenderer.
+func (r rulerRenderer) Destroy() {
+
+}
+
+// Layout implements fyne.WidgetRenderer. Recreates the ticks for the plan
+// in view: a labelled tick every step, and a short one halfway between.
+func (r rulerRenderer) Layout(size fyne.Size) {
+	ruler := r.parent
+	ruler.background.Resize(size)
+
+	length, depth := size.Width, size.Height
+	start := ruler.view.ToPlan(fyne.NewPos(0, 0)).X
+	offset := ruler.view.Offset.X
+	if ruler.Vertical {
+		length, depth = size.Height, size.Width
+		start = ruler.view.ToPlan(fyne.NewPos(0, 0)).Y
+		offset = ruler.view.Offset.Y
+	}
+
+	unit := ruler.formatter.DisplayUnitLength()
+	half := TickStep(ruler.view.Scale*unit, RULER_TICK_GAP) * unit / 2
+	end := start + length/ruler.view.Scale
+
+	ruler.ticks = []*canvas.Line{}
+	ruler.labels = []*canvas.Text{}
+	for k := math.Ceil(float64(start / half)); k <= math.Floor(float64(end/half)); k++ {
+		value := float32(k) * half
+		at := offset + value*ruler.view.Scale
+		tick := canvas.NewLine(colornames.Dimgray)
+		tick.StrokeWidth = 1
+		from := depth * 0.75
+		if int(k)%2 == 0 {
+			from = depth * 0.4
+			label := canvas.NewText(ruler.formatter.FormatLength(value), colornames.Black)
+			label.TextSize = RULER_TEXT_SIZE
+			if ruler.Vertical {
+				label.Move(fyne.NewPos(2, at))
+			} else {
+				label.Move(fyne.NewPos(at+2, 0))
+			}
+			ruler.labels = append(ruler.labels, label)
+		}
+
+		if ruler.Vertical {
+			tick.Position1 = fyne.NewPos(from, at)
+			tick.Position2 = fyne.NewPos(depth, at)
+		} else {
+			tick.Position1 = fyne.NewPos(at, from)
+			tick.Position2 = fyne.NewPos(at, depth)
+		}
+		ruler.ticks = append(ruler.ticks, tick)
+	}
+}
+
+// MinSize implements fyne.WidgetRenderer.
+func (r rulerRenderer) MinSize() fyne.Size {
+	if r.parent.Vertical {
+		return fyne.NewSize(RULER_SIZE, 0)
+	}
+	return fyne.NewSize(0, RULER_SIZE)
+}
+
+// Objects implements fyne.WidgetRenderer.
+func (r rulerRenderer) Objects() []fyne.CanvasObject {
+	os := []fyne.CanvasObject{r.parent.background}
+	for _, t := range r.parent.ticks {
+		os = append(os, t)
+	}
+	for _, l := range r.parent.labels {
+		os = append(os, l)
+	}
+	return os
+}
+
+// Refresh implements fyne.WidgetRenderer.
+func (r rulerRenderer) Refresh() {
+	r.Layout(r.parent.Size())
+}
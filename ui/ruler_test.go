@@ -0,0 +1,27 @@
+package ui
+
+import "testing"
+
+func TestTickStep(t *testing.T) {
+	cases := []struct {
+		pixelsPerUnit float32
+		want          float32
+	}{
+		{60, 1},
+		{40, 2},
+		{13, 5},
+		{5, 20},
+		{600, 0.1},
+		{250, 0.5},
+	}
+
+	for _, c := range cases {
+		if got := TickStep(c.pixelsPerUnit, 60); !nearly(got, c.want) {
+			t.Errorf("TickStep(%v, 60) == %v; want %v", c.pixelsPerUnit, got, c.want)
+		}
+	}
+}
+
+func nearly(a float32, b float32) bool {
+	return a-b < 1e-4 && b-a < 1e-4
+}
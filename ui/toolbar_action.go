@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Extends the fyne toolbar action so it can be disabled. The toolbar asks for
+// its button on every refresh, so the same button is handed out each time.
+type ToolbarAction struct {
+	widget.ToolbarAction
+
+	button *widget.Button
+}
+
+func NewToolbarAction(icon fyne.Resource, onActivated func()) *ToolbarAction {
+	a := &ToolbarAction{
+		ToolbarAction: widget.ToolbarAction{Icon: icon, OnActivated: onActivated},
+	}
+	a.button = widget.NewButtonWithIcon("", icon, onActivated)
+	a.button.Importance = widget.LowImportance
+	return a
+}
+
+func (a *ToolbarAction) ToolbarObject() fyne.CanvasObject {
+	return a.button
+}
+
+func (a *ToolbarAction) Enable() {
+	a.button.Enable()
+}
+
+func (a *ToolbarAction) Disable() {
+	a.button.Disable()
+}
+
+// Enables the action if on is true, or disables it otherwise.
+func (a *ToolbarAction) SetEnabled(on bool) {
+	if on {
+		a.Enable()
+	} else {
+		a.Disable()
+	}
+}
@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"golang.org/x/image/colornames"
+)
+
+// Handle on a polygon corner, or halfway along a side. Unlike edge handles
+// it can be tapped, e.g. to add or remove a corner.
+type VertexHandle struct {
+	Handle
+
+	// Events
+	OnTapped       func()
+	OnDoubleTapped func()
+}
+
+func NewVertexHandle() *VertexHandle {
+	h := VertexHandle{
+		OnTapped:       func() {},
+		OnDoubleTapped: func() {},
+	}
+	h.Circle = *canvas.NewCircle(colornames.White)
+	h.Circle.StrokeColor = colornames.Black
+	h.Circle.StrokeWidth = 1
+	h.OnDragged = func(e *fyne.DragEvent) {}
+	h.OnDragEnd = func() {}
+
+	h.ExtendBaseWidget(&h)
+	return &h
+}
+
+func (h *VertexHandle) Tapped(e *fyne.PointEvent) {
+	h.OnTapped()
+}
+
+func (h *VertexHandle) DoubleTapped(e *fyne.PointEvent) {
+	h.OnDoubleTapped()
+}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"github.com/cpgillem/garden-planner/geometry"
+)
+
+// Furthest out and furthest in the view zooms, in pixels per base unit.
+const (
+	MIN_SCALE = 0.05
+	MAX_SCALE = 50
+)
+
+// Part of the plan shown on the garden widget. Plan points, in base units,
+// appear at Offset + point × Scale.
+type Viewport struct {
+	// Pixels per base unit.
+	Scale float32
+
+	// Where the plan's origin appears on the widget.
+	Offset fyne.Position
+}
+
+// Creates a new viewport with the plan's origin in the top-left corner.
+func NewViewport(scale float32) Viewport {
+	return Viewport{
+		Scale:  clampScale(scale),
+		Offset: fyne.NewPos(0, 0),
+	}
+}
+
+// Where a plan point appears on the widget.
+func (v *Viewport) ToScreen(p geometry.Vector) fyne.Position {
+	return fyne.NewPos(v.Offset.X+p.X*v.Scale, v.Offset.Y+p.Y*v.Scale)
+}
+
+// Plan point under a position on the widget.
+func (v *Viewport) ToPlan(pos fyne.Position) geometry.Vector {
+	return geometry.NewVector((pos.X-v.Offset.X)/v.Scale, (pos.Y-v.Offset.Y)/v.Scale, 0)
+}
+
+// Zooms by a factor, keeping the plan point under a widget position in
+// place.
+func (v *Viewport) ZoomAt(pos fyne.Position, factor float32) {
+	p := v.ToPlan(pos)
+	v.Scale = clampScale(v.Scale * factor)
+	v.Offset = fyne.NewPos(pos.X-p.X*v.Scale, pos.Y-p.Y*v.Scale)
+}
+
+// Moves the view by a distance in pixels.
+func (v *Viewport) Pan(dx float32, dy float32) {
+	v.Offset = v.Offset.AddXY(dx, dy)
+}
+
+// Zooms and pans so the whole of an area fills a widget of a given size,
+// centred, with a margin in pixels around it. Areas with no width and
+// height are only centred.
+func (v *Viewport) Fit(area geometry.Box, size fyne.Size, margin float32) {
+	width, height := size.Width-2*margin, size.Height-2*margin
+	if width > 0 && height > 0 && (area.Size.X > 0 || area.Size.Y > 0) {
+		scale := float32(MAX_SCALE)
+		if area.Size.X > 0 {
+			scale = min(scale, width/area.Size.X)
+		}
+		if area.Size.Y > 0 {
+			scale = min(scale, height/area.Size.Y)
+		}
+		v.Scale = clampScale(scale)
+	}
+
+	center := area.Center()
+	v.Offset = fyne.NewPos(size.Width/2-center.X*v.Scale, size.Height/2-center.Y*v.Scale)
+}
+
+func clampScale(scale float32) float32 {
+	return min(max(scale, MIN_SCALE), MAX_SCALE)
+}
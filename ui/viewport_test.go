@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"github.com/cpgillem/garden-planner/geometry"
+)
+
+func TestViewportZoomAt(t *testing.T) {
+	v := NewViewport(2)
+	v.Pan(10, 20)
+	cursor := fyne.NewPos(100, 50)
+	under := v.ToPlan(cursor)
+
+	v.ZoomAt(cursor, 1.5)
+	if v.Scale != 3 {
+		t.Errorf("Scale == %v; want 3", v.Scale)
+	}
+	if got := v.ToScreen(under); got != cursor {
+		t.Errorf("ToScreen(%v) == %v; want the cursor at %v", under, got, cursor)
+	}
+
+	// Zooming never flips or blows up the plan.
+	v.ZoomAt(cursor, -1)
+	if v.Scale != MIN_SCALE {
+		t.Errorf("Scale == %v; want %v", v.Scale, MIN_SCALE)
+	}
+	v.ZoomAt(cursor, 1e6)
+	if v.Scale != MAX_SCALE {
+		t.Errorf("Scale == %v; want %v", v.Scale, MAX_SCALE)
+	}
+}
+
+func TestViewportFit(t *testing.T) {
+	v := NewViewport(1)
+	v.Fit(geometry.NewBox(100, 100, 200, 100), fyne.NewSize(420, 420), 10)
+	if v.Scale != 2 {
+		t.Errorf("Scale == %v; want 2", v.Scale)
+	}
+	if got, want := v.ToScreen(geometry.NewVector(200, 150, 0)), fyne.NewPos(210, 210); got != want {
+		t.Errorf("Center at %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,136 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Severity int
+
+const (
+	INFO    = Severity(0)
+	WARNING = Severity(1)
+	ERROR   = Severity(2)
+)
+
+func (s Severity) String() string {
+	switch s {
+	case INFO:
+		return "info"
+	case WARNING:
+		return "warning"
+	case ERROR:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// A single problem found while validating a file.
+type Problem struct {
+	File     string
+	Path     string
+	Severity Severity
+	Message  string
+}
+
+func (p Problem) String() string {
+	location := p.Path
+	if p.File != "" {
+		location = p.File + ": " + p.Path
+	}
+	return fmt.Sprintf("%s: %s: %s", p.Severity, location, p.Message)
+}
+
+// Collects problems found while loading data. Reports for different files
+// share one list of problems; see In.
+type Report struct {
+	file     string
+	problems *[]Problem
+}
+
+func NewReport() *Report {
+	return &Report{
+		problems: &[]Problem{},
+	}
+}
+
+// Returns a report that adds problems for the given file to the same list.
+func (r *Report) In(file string) *Report {
+	return &Report{
+		file:     file,
+		problems: r.problems,
+	}
+}
+
+func (r *Report) Add(severity Severity, path string, format string, args ...any) {
+	*r.problems = append(*r.problems, Problem{
+		File:     r.file,
+		Path:     path,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (r *Report) Errorf(path string, format string, args ...any) {
+	r.Add(ERROR, path, format, args...)
+}
+
+func (r *Report) Warnf(path string, format string, args ...any) {
+	r.Add(WARNING, path, format, args...)
+}
+
+func (r *Report) Infof(path string, format string, args ...any) {
+	r.Add(INFO, path, format, args...)
+}
+
+func (r *Report) Problems() []Problem {
+	return *r.problems
+}
+
+func (r *Report) IsEmpty() bool {
+	return len(*r.problems) == 0
+}
+
+// Whether any problem is at least as severe as the given severity.
+func (r *Report) Has(severity Severity) bool {
+	for _, p := range *r.problems {
+		if p.Severity >= severity {
+			return true
+		}
+	}
+	return false
+}
+
+// Number of problems at least as severe as the given severity.
+func (r *Report) Count(severity Severity) int {
+	count := 0
+	for _, p := range *r.problems {
+		if p.Severity >= severity {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *Report) String() string {
+	lines := []string{}
+	for _, p := range *r.problems {
+		lines = append(lines, p.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// JSON path helpers, e.g. $.features.abc[0].
+
+func Root() string {
+	return "$"
+}
+
+func Key(path string, key string) string {
+	return path + "." + key
+}
+
+func Index(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
@@ -0,0 +1,129 @@
+package validation
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Checks that JSON content matches the shape of the Go type it will be
+// decoded into: unknown fields, wrong JSON types and non-integral numbers
+// for integer fields are all reported. Fields typed any are not checked.
+func CheckSchema(content []byte, t reflect.Type, r *Report) {
+	var raw any
+	if err := json.Unmarshal(content, &raw); err != nil {
+		r.Errorf(Root(), "invalid JSON: %s", err.Error())
+		return
+	}
+
+	CheckValue(raw, t, Root(), r)
+}
+
+// Implemented by types with their own JSON encoding to check raw JSON
+// values against it.
+type Checker interface {
+	CheckJSON(raw any, path string, r *Report)
+}
+
+var checkerType = reflect.TypeOf((*Checker)(nil)).Elem()
+
+// Checks a decoded JSON value against a Go type.
+func CheckValue(raw any, t reflect.Type, path string, r *Report) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Implements(checkerType) {
+		reflect.Zero(t).Interface().(Checker).CheckJSON(raw, path, r)
+		return
+	}
+
+	// JSON null decodes to the zero value of anything.
+	if raw == nil {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return
+	case reflect.Struct:
+		obj, ok := raw.(map[string]any)
+		if !ok {
+			r.Errorf(path, "expected an object")
+			return
+		}
+		checkFields(obj, t, path, r)
+	case reflect.Slice, reflect.Array:
+		arr, ok := raw.([]any)
+		if !ok {
+			r.Errorf(path, "expected an array")
+			return
+		}
+		for i, v := range arr {
+			CheckValue(v, t.Elem(), Index(path, i), r)
+		}
+	case reflect.Map:
+		obj, ok := raw.(map[string]any)
+		if !ok {
+			r.Errorf(path, "expected an object")
+			return
+		}
+		for k, v := range obj {
+			CheckValue(v, t.Elem(), Key(path, k), r)
+		}
+	case reflect.String:
+		if _, ok := raw.(string); !ok {
+			r.Errorf(path, "expected a string")
+		}
+	case reflect.Bool:
+		if _, ok := raw.(bool); !ok {
+			r.Errorf(path, "expected true or false")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f, ok := raw.(float64)
+		if !ok {
+			r.Errorf(path, "expected an integer")
+		} else if f != math.Trunc(f) {
+			r.Errorf(path, "expected an integer, got %v", f)
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := raw.(float64); !ok {
+			r.Errorf(path, "expected a number")
+		}
+	}
+}
+
+// Checks the fields of a JSON object against the exported fields of a struct.
+func checkFields(obj map[string]any, t reflect.Type, path string, r *Report) {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		fields[strings.ToLower(name)] = field
+	}
+
+	for key, value := range obj {
+		// encoding/json matches keys case-insensitively.
+		field, ok := fields[strings.ToLower(key)]
+		if !ok {
+			r.Warnf(Key(path, key), "unknown field %q is ignored", key)
+			continue
+		}
+		CheckValue(value, field.Type, Key(path, key), r)
+	}
+}
@@ -0,0 +1,48 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testItem struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	Tags  []string `json:"tags"`
+	Extra any      `json:"extra"`
+}
+
+func TestCheckSchema(t *testing.T) {
+	cases := []struct {
+		in       string
+		wantPath string
+		wantSev  Severity
+	}{
+		{`[{"id": 1, "nmae": "x"}]`, "$[0].nmae", WARNING},
+		{`[{"id": 1.5}]`, "$[0].id", ERROR},
+		{`[{"id": 1, "name": 3}]`, "$[0].name", ERROR},
+		{`[{"id": 1}, {"tags": [1]}]`, "$[1].tags[0]", ERROR},
+		{`{"id": 1}`, "$", ERROR},
+	}
+
+	for _, c := range cases {
+		r := NewReport()
+		CheckSchema([]byte(c.in), reflect.TypeOf([]testItem{}), r)
+
+		problems := r.Problems()
+		if len(problems) != 1 {
+			t.Errorf("CheckSchema(%s) found %d problems; want 1:\n%s", c.in, len(problems), r.String())
+			continue
+		}
+		if problems[0].Path != c.wantPath || problems[0].Severity != c.wantSev {
+			t.Errorf("CheckSchema(%s) == %s; want %s at %s", c.in, problems[0].String(), c.wantSev, c.wantPath)
+		}
+	}
+
+	// Valid content, including anything in an untyped field.
+	r := NewReport()
+	CheckSchema([]byte(`[{"ID": 2, "name": "a", "tags": ["b"], "extra": {"c": 1}}]`), reflect.TypeOf([]testItem{}), r)
+	if !r.IsEmpty() {
+		t.Errorf("CheckSchema(valid) found problems:\n%s", r.String())
+	}
+}
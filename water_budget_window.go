@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/bcicen/go-units"
+	"github.com/cpgillem/garden-planner/irrigation"
+)
+
+// Report of the water the plan needs each week, by feature, plant and zone,
+// with a schedule of watering days. Volumes follow the measurement system.
+type WaterBudgetWindow struct {
+	instance *GardenPlanner
+	window   fyne.Window
+
+	// Table contents, header row first.
+	featureRows  [][]string
+	plantRows    [][]string
+	zoneRows     [][]string
+	scheduleRows [][]string
+
+	// Widgets
+	summaryLabel  *widget.Label
+	featureTable  *widget.Table
+	plantTable    *widget.Table
+	zoneTable     *widget.Table
+	scheduleTable *widget.Table
+}
+
+func NewWaterBudgetWindow(instance *GardenPlanner) WaterBudgetWindow {
+	w := WaterBudgetWindow{
+		instance:     instance,
+		window:       instance.App.NewWindow("Water Budget"),
+		featureRows:  [][]string{},
+		plantRows:    [][]string{},
+		zoneRows:     [][]string{},
+		scheduleRows: [][]string{},
+		summaryLabel: widget.NewLabel(""),
+	}
+
+	w.window.SetCloseIntercept(w.window.Hide)
+	w.window.Resize(fyne.NewSize(600, 400))
+
+	return w
+}
+
+// Builds the window contents. Separate from NewWaterBudgetWindow so tables
+// read the rows stored in the app rather than a copy.
+func (w *WaterBudgetWindow) Setup() {
+	w.featureTable = newReportTable(&w.featureRows, 160, 100, 100, 80, 110)
+	w.plantTable = newReportTable(&w.plantRows, 160, 110)
+	w.zoneTable = newReportTable(&w.zoneRows, 160, 110)
+	w.scheduleTable = newReportTable(&w.scheduleRows, 110, 110, 300)
+
+	w.summaryLabel.Wrapping = fyne.TextWrapWord
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem("By Feature", w.featureTable),
+		container.NewTabItem("By Plant", w.plantTable),
+		container.NewTabItem("By Zone", w.zoneTable),
+		container.NewTabItem("Schedule", w.scheduleTable),
+	)
+	w.window.SetContent(container.NewBorder(w.summaryLabel, nil, nil, nil, tabs))
+}
+
+func (w *WaterBudgetWindow) Show() {
+	w.Refresh()
+	w.window.Show()
+}
+
+// Recalculates the budget from the plan.
+func (w *WaterBudgetWindow) Refresh() {
+	plan := w.instance.PlanController.Plan
+	budget := irrigation.WaterBudget(plan, w.instance.PlantController.Plants())
+
+	plantNames := map[int]string{}
+	for _, p := range w.instance.PlantController.Plants() {
+		plantNames[p.ID] = p.Name
+	}
+	zoneName := func(fw irrigation.FeatureWater) string {
+		if z := plan.Irrigation.Zones[fw.Zone]; z != nil {
+			return z.Name
+		}
+		return irrigation.UNZONED
+	}
+
+	w.featureRows = [][]string{{"Feature", "Plant", "Zone", "Sessions", "Per Session"}}
+	for _, fw := range budget.Features {
+		w.featureRows = append(w.featureRows, []string{
+			fw.Name,
+			plantNames[fw.PlantID],
+			zoneName(fw),
+			fmt.Sprintf("%d / wk", fw.Sessions),
+			w.volume(fw.PerSession),
+		})
+	}
+
+	w.plantRows = [][]string{{"Plant", "Per Week"}}
+	for _, t := range budget.Plants {
+		w.plantRows = append(w.plantRows, []string{t.Name, w.volume(t.Weekly)})
+	}
+
+	w.zoneRows = [][]string{{"Zone", "Per Week"}}
+	for _, t := range budget.Zones {
+		w.zoneRows = append(w.zoneRows, []string{t.Name, w.volume(t.Weekly)})
+	}
+
+	w.scheduleRows = [][]string{{"Day", "Volume", "Features"}}
+	for _, day := range budget.Schedule {
+		names := ""
+		for i, id := range day.Features {
+			if i > 0 {
+				names += ", "
+			}
+			names += plan.Features[id].Name
+		}
+		w.scheduleRows = append(w.scheduleRows, []string{day.Weekday.String(), w.volume(day.Gallons), names})
+	}
+
+	busiest := budget.BusiestDay()
+	w.summaryLabel.SetText(fmt.Sprintf(
+		"The plan needs %s a week, at most %s on %s. Storing a week of water takes %d rain barrels of %s.",
+		w.volume(budget.Weekly),
+		w.volume(busiest.Gallons),
+		busiest.Weekday,
+		budget.RainBarrels(),
+		w.volume(irrigation.RAIN_BARREL_GALLONS),
+	))
+
+	for _, table := range []*widget.Table{w.featureTable, w.plantTable, w.zoneTable, w.scheduleTable} {
+		table.Refresh()
+	}
+}
+
+// Formats a volume in gallons in the unit of the measurement system.
+func (w *WaterBudgetWindow) volume(gallons float64) string {
+	if w.instance.App.Preferences().StringWithFallback("measurement_system", IMPERIAL) == METRIC {
+		return fmt.Sprintf("%.1f L", irrigation.ConvertGallons(gallons, units.Liter))
+	}
+	return fmt.Sprintf("%.1f gal", gallons)
+}
+
+// Read-only table of text rows. The first row is a bold header.
+func newReportTable(rows *[][]string, widths ...float32) *widget.Table {
+	table := widget.NewTable(
+		func() (int, int) {
+			return len(*rows), len(widths)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			label := o.(*widget.Label)
+			label.TextStyle.Bold = id.Row == 0
+			label.SetText((*rows)[id.Row][id.Col])
+		},
+	)
+	for i, width := range widths {
+		table.SetColumnWidth(i, width)
+	}
+	return table
+}